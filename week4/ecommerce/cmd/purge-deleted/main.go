@@ -0,0 +1,67 @@
+// main.go
+package main
+
+import (
+	"context"
+	productInfra "ecommerce/internal/product/infra"
+	productUsecase "ecommerce/internal/product/usecase"
+	userInfra "ecommerce/internal/user/infra"
+	userUsecase "ecommerce/internal/user/usecase"
+	"ecommerce/pkg/cache"
+	"ecommerce/pkg/db"
+	"ecommerce/pkg/storage"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// purge-deleted hard deletes products and users that were soft deleted more
+// than -days ago. Records still referenced by an order are kept forever so
+// invoices keep resolving.
+func main() {
+	days := flag.Int("days", 90, "retention period in days for soft deleted records")
+	flag.Parse()
+
+	if *days < 0 {
+		log.Fatal("-days must not be negative")
+	}
+
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	dbInstance := db.GetDBInstance()
+	defer dbInstance.Close()
+
+	store, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	retention := time.Duration(*days) * 24 * time.Hour
+
+	pu := productUsecase.NewProductUsecase(
+		productInfra.NewProductPGRepository(dbInstance),
+		productInfra.NewProductImagePGRepository(dbInstance),
+		productInfra.NewProductImportPGRepository(dbInstance),
+		store,
+		cache.NewFromEnv(),
+	)
+	products, err := pu.PurgeDeletedProducts(ctx, retention)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	uu := userUsecase.NewUserUsecase(userInfra.NewUserPGRepository(dbInstance))
+	users, err := uu.PurgeDeletedUsers(ctx, retention)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("purged %d products and %d users deleted more than %d days ago\n", products, users, *days)
+}
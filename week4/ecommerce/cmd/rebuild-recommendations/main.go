@@ -0,0 +1,33 @@
+// main.go
+package main
+
+import (
+	"context"
+	"ecommerce/internal/recommendation/infra"
+	"ecommerce/internal/recommendation/usecase"
+	"ecommerce/pkg/db"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// rebuild-recommendations recomputes the "frequently bought together" scores
+// right away instead of waiting for the web server's periodic job.
+func main() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	dbInstance := db.GetDBInstance()
+	defer dbInstance.Close()
+
+	ru := usecase.NewRecommendationUsecase(infra.NewRecommendationPGRepository(dbInstance))
+	stats, err := ru.Rebuild(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("stored %d related product pairs\n", stats.Pairs)
+}
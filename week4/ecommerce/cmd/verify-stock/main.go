@@ -0,0 +1,49 @@
+// main.go
+package main
+
+import (
+	"context"
+	inventoryInfra "ecommerce/internal/inventory/infra"
+	inventoryUsecase "ecommerce/internal/inventory/usecase"
+	"ecommerce/pkg/db"
+	"ecommerce/pkg/notify"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// verify-stock checks that every product's stock, in total and per warehouse,
+// equals the sum of its inventory movements and exits with status 1 when it
+// does not.
+func main() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	dbInstance := db.GetDBInstance()
+	defer dbInstance.Close()
+
+	uc := inventoryUsecase.NewInventoryUsecase(inventoryInfra.NewInventoryPGRepository(dbInstance), notify.LogNotifier{})
+
+	mismatches, err := uc.Verify(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(mismatches) == 0 {
+		fmt.Println("OK: stock matches the inventory ledger for every product")
+		return
+	}
+
+	for _, m := range mismatches {
+		if m.WarehouseID != nil {
+			fmt.Printf("product %d in warehouse %d: stock %d, ledger %d (diff %d)\n", m.ProductID, *m.WarehouseID, m.Stock, m.LedgerTotal, m.Stock-m.LedgerTotal)
+			continue
+		}
+		fmt.Printf("product %d: stock %d, ledger %d (diff %d)\n", m.ProductID, m.Stock, m.LedgerTotal, m.Stock-m.LedgerTotal)
+	}
+	os.Exit(1)
+}
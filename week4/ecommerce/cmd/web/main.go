@@ -2,15 +2,34 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	accountHandler "ecommerce/internal/auth/handler"
+	accountUsecase "ecommerce/internal/auth/usecase"
+	digitalHandler "ecommerce/internal/digital/handler"
+	inventoryHandler "ecommerce/internal/inventory/handler"
+	inventoryUsecase "ecommerce/internal/inventory/usecase"
 	orderHandler "ecommerce/internal/order/handler"
 	productHandler "ecommerce/internal/product/handler"
+	productUsecase "ecommerce/internal/product/usecase"
+	recommendationHandler "ecommerce/internal/recommendation/handler"
+	recommendationUsecase "ecommerce/internal/recommendation/usecase"
+	reviewHandler "ecommerce/internal/review/handler"
 	"ecommerce/internal/user/userHandler"
+	warehouseHandler "ecommerce/internal/warehouse/handler"
+	wishlistHandler "ecommerce/internal/wishlist/handler"
+	wishlistUsecase "ecommerce/internal/wishlist/usecase"
+	"ecommerce/pkg/imaging"
 	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/scheduler"
+	"ecommerce/pkg/storage"
 
 	"ecommerce/pkg/db"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
@@ -19,10 +38,29 @@ import (
 const port = `:8080`
 
 type application struct {
-	accountHandler *accountHandler.AccountHandler
-	userHandler    *userHandler.UserHandler
-	productHandler *productHandler.ProductHandler
-	orderHandler   *orderHandler.OrderHandler
+	accountHandler        *accountHandler.AccountHandler
+	roleHandler           *accountHandler.RoleHandler
+	apiKeyHandler         *accountHandler.APIKeyHandler
+	oidcHandler           *accountHandler.OIDCHandler
+	userHandler           *userHandler.UserHandler
+	productHandler        *productHandler.ProductHandler
+	priceHandler          *productHandler.PriceHandler
+	orderHandler          *orderHandler.OrderHandler
+	digitalHandler        *digitalHandler.DigitalHandler
+	reviewHandler         *reviewHandler.ReviewHandler
+	recommendationHandler *recommendationHandler.RecommendationHandler
+	inventoryHandler      *inventoryHandler.InventoryHandler
+	warehouseHandler      *warehouseHandler.WarehouseHandler
+	wishlistHandler       *wishlistHandler.WishlistHandler
+	accountUsecase        accountUsecase.IAccountUsecase
+	signingKeyUsecase     *accountUsecase.SigningKeyUsecase
+	apiKeyUsecase         *accountUsecase.APIKeyUsecase
+	oidcUsecase           *accountUsecase.OIDCUsecase
+	requireVerifiedEmail  bool
+	inventoryUsecase      *inventoryUsecase.InventoryUsecase
+	priceUsecase          *productUsecase.PriceUsecase
+	recommendationUsecase *recommendationUsecase.RecommendationUsecase
+	wishlistUsecase       *wishlistUsecase.WishlistUsecase
 }
 
 func main() {
@@ -43,7 +81,49 @@ func main() {
 	// Initialize application
 	app := setupApplication(dbInstance)
 
-	fiberApp := fiber.New()
+	// Background jobs
+	go scheduler.Every(context.Background(), "low stock check",
+		scheduler.IntervalFromEnv("LOW_STOCK_CHECK_INTERVAL", 15*time.Minute),
+		app.inventoryUsecase.CheckLowStock)
+	go scheduler.Every(context.Background(), "price schedules",
+		scheduler.IntervalFromEnv("PRICE_SCHEDULE_INTERVAL", time.Minute),
+		app.priceUsecase.ApplyPriceSchedules)
+	go scheduler.Every(context.Background(), "recommendations",
+		scheduler.IntervalFromEnv("RECOMMENDATION_INTERVAL", time.Hour),
+		app.recommendationUsecase.RebuildJob)
+	go scheduler.Every(context.Background(), "back in stock",
+		scheduler.IntervalFromEnv("BACK_IN_STOCK_INTERVAL", time.Minute),
+		app.wishlistUsecase.NotifyBackInStock)
+	go scheduler.Every(context.Background(), "token cleanup",
+		scheduler.IntervalFromEnv("TOKEN_CLEANUP_INTERVAL", time.Hour),
+		app.accountUsecase.DeleteExpiredTokens)
+	go scheduler.Every(context.Background(), "login attempt cleanup",
+		scheduler.IntervalFromEnv("LOGIN_ATTEMPT_CLEANUP_INTERVAL", time.Hour),
+		app.accountUsecase.DeleteExpiredLoginAttempts)
+	go scheduler.Every(context.Background(), "oidc login cleanup",
+		scheduler.IntervalFromEnv("OIDC_LOGIN_CLEANUP_INTERVAL", time.Hour),
+		app.oidcUsecase.DeleteExpiredLogins)
+	go scheduler.Every(context.Background(), "signing keys",
+		scheduler.IntervalFromEnv("SIGNING_KEY_INTERVAL", time.Minute),
+		app.signingKeyUsecase.RotateKeys)
+
+	// Behind a load balancer, the client IP failed logins are counted by is
+	// read from PROXY_HEADER, but only on requests from TRUSTED_PROXIES
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+
+	fiberApp := fiber.New(fiber.Config{
+		// Leave room for several product images in one multipart request
+		BodyLimit:               4 * imaging.MaxUploadSize,
+		ProxyHeader:             os.Getenv("PROXY_HEADER"),
+		EnableTrustedProxyCheck: len(trustedProxies) > 0,
+		TrustedProxies:          trustedProxies,
+	})
+
+	// Files kept by the local storage backend
+	fiberApp.Static(storage.LocalURLPrefix, storage.LocalDir)
 
 	// Setup routes
 	app.setupRoutes(fiberApp)
@@ -59,34 +139,147 @@ func (app *application) setupRoutes(fiberApp *fiber.App) {
 
 	// Public routes (no auth required)
 	fiberApp.Post("/login", app.accountHandler.Login)
+	// Second step of logging in with two-factor authentication
+	fiberApp.Post("/auth/login/2fa", app.accountHandler.CompleteLogin)
+	// Sign-in with the identity provider, see OIDC_ISSUER
+	fiberApp.Get("/auth/oidc/login", app.oidcHandler.Login)
+	fiberApp.Get("/auth/oidc/callback", app.oidcHandler.Callback)
 	fiberApp.Post("/register", app.accountHandler.Register)
+	// Refresh tokens stand in for the expired access token
+	fiberApp.Post("/auth/refresh", app.accountHandler.Refresh)
+	fiberApp.Post("/auth/logout", app.accountHandler.Logout)
+	fiberApp.Post("/auth/password-reset", app.accountHandler.RequestPasswordReset)
+	fiberApp.Post("/auth/password-reset/confirm", app.accountHandler.ResetPassword)
+	fiberApp.Post("/auth/verify-email", app.accountHandler.VerifyEmail)
+	// Public keys for services verifying our access tokens
+	fiberApp.Get("/.well-known/jwks.json", app.accountHandler.JWKS)
+	// Signed download links carry their own authorization
+	fiberApp.Get("/downloads/:id", app.digitalHandler.Download)
+	// Shared wishlists are public to anyone with the link
+	fiberApp.Get("/wishlists/:token", app.wishlistHandler.GetSharedWishlist)
 
-	// Apply auth middleware to all other routes
-	api := fiberApp.Group("/api", middleware.AuthMiddleware())
+	// Apply auth middleware to all other routes; other services send an
+	// API key instead of a token
+	api := fiberApp.Group("/api", middleware.AuthMiddleware(app.accountUsecase, app.apiKeyUsecase))
 
 	// Auth routes
 	api.Post("/login", app.accountHandler.Login)
 	api.Post("/register", app.accountHandler.Register)
+	api.Get("/me", app.accountHandler.GetMe)
+	api.Put("/me", app.accountHandler.UpdateMe)
+	api.Delete("/me", app.accountHandler.DeleteMe)
+	api.Post("/me/password", app.accountHandler.ChangePassword)
+	api.Post("/me/verify-email", app.accountHandler.ResendVerification)
+	api.Post("/me/2fa", app.accountHandler.EnrolTwoFactor)
+	api.Post("/me/2fa/confirm", app.accountHandler.ConfirmTwoFactor)
+	api.Delete("/me/2fa", app.accountHandler.DisableTwoFactor)
+	api.Post("/me/2fa/recovery-codes", app.accountHandler.RegenerateRecoveryCodes)
+
+	// Account administration
+	api.Get("/accounts", middleware.RequirePermission(rbac.AccountManage), app.accountHandler.GetAllAccounts)
+	api.Post("/accounts/:id/lock", middleware.RequirePermission(rbac.AccountManage), app.accountHandler.LockAccount)
+	api.Delete("/accounts/:id/lock", middleware.RequirePermission(rbac.AccountManage), app.accountHandler.UnlockAccount)
+	api.Put("/accounts/:id/roles", middleware.RequirePermission(rbac.AccountManage), app.accountHandler.SetRoles)
+	api.Delete("/accounts/:id/2fa", middleware.RequirePermission(rbac.AccountManage), app.accountHandler.ResetTwoFactor)
+
+	// Role administration
+	api.Get("/permissions", middleware.RequirePermission(rbac.RoleManage), app.roleHandler.GetPermissions)
+	api.Get("/roles", middleware.RequirePermission(rbac.RoleManage), app.roleHandler.GetAllRoles)
+	api.Post("/roles", middleware.RequirePermission(rbac.RoleManage), app.roleHandler.AddRole)
+	api.Put("/roles/:id", middleware.RequirePermission(rbac.RoleManage), app.roleHandler.UpdateRole)
+	api.Delete("/roles/:id", middleware.RequirePermission(rbac.RoleManage), app.roleHandler.DeleteRole)
+
+	// API keys for other services
+	api.Get("/api-keys", middleware.RequirePermission(rbac.APIKeyManage), app.apiKeyHandler.GetAllAPIKeys)
+	api.Post("/api-keys", middleware.RequirePermission(rbac.APIKeyManage), app.apiKeyHandler.CreateAPIKey)
+	api.Delete("/api-keys/:id", middleware.RequirePermission(rbac.APIKeyManage), app.apiKeyHandler.RevokeAPIKey)
 
 	// User routes
-	api.Get("/users", app.userHandler.GetAllUsers)
-	api.Post("/users", app.userHandler.AddUser)
+	api.Get("/users", middleware.RequirePermission(rbac.UserReadAny), app.userHandler.GetAllUsers)
+	api.Post("/users", middleware.RequirePermission(rbac.UserWriteAny), app.userHandler.AddUser)
 	api.Put("/users/:id", app.userHandler.UpdateUser)
-	api.Delete("/users/:id", app.userHandler.DeleteUser)
+	api.Delete("/users/:id", middleware.RequirePermission(rbac.UserWriteAny), app.userHandler.DeleteUser)
+	api.Post("/users/:id/restore", middleware.RequirePermission(rbac.UserWriteAny), app.userHandler.RestoreUser)
 
 	// Product routes
 	api.Get("/products", app.productHandler.GetAllProducts)
+	api.Get("/products/export", middleware.RequirePermission(rbac.ReportRead), app.productHandler.ExportProducts)
+	api.Post("/products/import", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.ImportProducts)
+	api.Get("/products/import/:id", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.GetImportJob)
 	api.Get("/products/:id", app.productHandler.GetProductByID)
-	api.Post("/products", middleware.IsAdminMiddleware(), app.productHandler.AddProduct)
-	api.Put("/products/:id", middleware.IsAdminMiddleware(), app.productHandler.UpdateProduct)
-	api.Delete("/products/:id", middleware.IsAdminMiddleware(), app.productHandler.DeleteProduct)
+	api.Post("/products", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.AddProduct)
+	api.Put("/products/:id", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.UpdateProduct)
+	api.Delete("/products/:id", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.DeleteProduct)
+	api.Post("/products/:id/restore", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.RestoreProduct)
+	api.Get("/products/:id/components", app.productHandler.GetBundleComponents)
+	api.Put("/products/:id/components", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.SetBundleComponents)
+	api.Get("/products/:id/images", app.productHandler.GetProductImages)
+	api.Post("/products/:id/images", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.AddProductImages)
+	api.Put("/products/:id/images/order", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.ReorderProductImages)
+	api.Delete("/products/:id/images/:imageID", middleware.RequirePermission(rbac.ProductWrite), app.productHandler.DeleteProductImage)
+
+	// Price routes
+	api.Get("/products/:id/price-history", middleware.RequirePermission(rbac.ReportRead), app.priceHandler.GetPriceHistory)
+	api.Get("/products/:id/price-schedules", middleware.RequirePermission(rbac.PriceWrite), app.priceHandler.GetPriceSchedules)
+	api.Post("/products/:id/price-schedules", middleware.RequirePermission(rbac.PriceWrite), app.priceHandler.SchedulePrice)
+	api.Delete("/products/:id/price-schedules/:scheduleID", middleware.RequirePermission(rbac.PriceWrite), app.priceHandler.CancelPriceSchedule)
+
+	// Digital product routes
+	api.Put("/products/:id/file", middleware.RequirePermission(rbac.ProductWrite), app.digitalHandler.SetDownloadFile)
+	api.Get("/products/:id/licence-keys", middleware.RequirePermission(rbac.ProductWrite), app.digitalHandler.GetKeyPool)
+	api.Post("/products/:id/licence-keys", middleware.RequirePermission(rbac.ProductWrite), app.digitalHandler.AddLicenceKeys)
+	api.Get("/orders/:id/downloads", app.digitalHandler.GetOrderDelivery)
+
+	// Review routes
+	api.Get("/products/:id/reviews", app.reviewHandler.GetProductReviews)
+	api.Post("/products/:id/reviews", middleware.RequirePermission(rbac.ReviewWrite), app.reviewHandler.CreateReview)
+	api.Get("/products/:id/reviews/mine", middleware.RequirePermission(rbac.ReviewWrite), app.reviewHandler.GetMyReview)
+	api.Put("/products/:id/reviews/mine", middleware.RequirePermission(rbac.ReviewWrite), app.reviewHandler.UpdateMyReview)
+	api.Get("/reviews", middleware.RequirePermission(rbac.ReviewModerate), app.reviewHandler.GetReviews)
+	api.Put("/reviews/:id/moderation", middleware.RequirePermission(rbac.ReviewModerate), app.reviewHandler.ModerateReview)
+
+	// Recommendation routes
+	api.Get("/products/:id/recommendations", app.recommendationHandler.GetProductRecommendations)
+	api.Get("/me/recommendations", app.recommendationHandler.GetMyRecommendations)
+
+	// Wishlist routes
+	api.Get("/me/wishlist", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.GetWishlist)
+	api.Post("/me/wishlist/share", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.Share)
+	api.Delete("/me/wishlist/share", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.Unshare)
+	api.Post("/me/wishlist/:productID", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.AddItem)
+	api.Delete("/me/wishlist/:productID", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.RemoveItem)
+	api.Get("/me/stock-subscriptions", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.GetSubscriptions)
+	api.Post("/products/:id/stock-subscription", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.Subscribe)
+	api.Delete("/products/:id/stock-subscription", middleware.RequirePermission(rbac.WishlistWrite), app.wishlistHandler.Unsubscribe)
+
+	// Inventory routes
+	api.Get("/products/:id/stock-history", middleware.RequirePermission(rbac.ReportRead), app.inventoryHandler.GetStockHistory)
+	api.Post("/products/:id/stock/receive", middleware.RequirePermission(rbac.InventoryWrite), app.inventoryHandler.ReceiveStock)
+	api.Post("/products/:id/stock/adjust", middleware.RequirePermission(rbac.InventoryWrite), app.inventoryHandler.AdjustStock)
+	api.Post("/products/:id/stock/stock-take", middleware.RequirePermission(rbac.InventoryWrite), app.inventoryHandler.StockTake)
+	api.Put("/products/:id/reorder-policy", middleware.RequirePermission(rbac.InventoryWrite), app.inventoryHandler.SetReorderPolicy)
+	api.Get("/inventory/low-stock", middleware.RequirePermission(rbac.ReportRead), app.inventoryHandler.GetLowStockAlerts)
+	api.Get("/inventory/reorder-suggestions", middleware.RequirePermission(rbac.ReportRead), app.inventoryHandler.GetReorderSuggestions)
+
+	// Warehouse routes
+	api.Get("/products/:id/stock", app.warehouseHandler.GetProductStock)
+	api.Get("/products/:id/transfers", middleware.RequirePermission(rbac.ReportRead), app.warehouseHandler.GetTransfers)
+	api.Get("/warehouses", middleware.RequirePermission(rbac.ReportRead), app.warehouseHandler.GetAllWarehouses)
+	api.Post("/warehouses", middleware.RequirePermission(rbac.InventoryWrite), app.warehouseHandler.AddWarehouse)
+	api.Put("/warehouses/:id", middleware.RequirePermission(rbac.InventoryWrite), app.warehouseHandler.UpdateWarehouse)
+	api.Post("/warehouses/transfers", middleware.RequirePermission(rbac.InventoryWrite), app.warehouseHandler.TransferStock)
 
 	// Order routes
-	api.Get("/orders", middleware.IsAdminMiddleware(), app.orderHandler.GetAllOrders)
+	api.Get("/orders", middleware.RequirePermission(rbac.OrderReadAny), app.orderHandler.GetAllOrders)
 	api.Get("/orders/:username", app.orderHandler.GetUserOrders)
-	api.Post("/orders", middleware.IsUserMiddleware(), app.orderHandler.CreateOrder)
+	createOrder := []fiber.Handler{middleware.RequirePermission(rbac.OrderCreate)}
+	if app.requireVerifiedEmail {
+		createOrder = append(createOrder, middleware.VerifiedEmailMiddleware(app.accountUsecase))
+	}
+	api.Post("/orders", append(createOrder, app.orderHandler.CreateOrder)...)
 	api.Put("/orders/:id", app.orderHandler.UpdateOrder)
-	api.Delete("/orders/:id", middleware.IsAdminMiddleware(), app.orderHandler.DeleteOrder)
+	api.Put("/orders/:id/status", middleware.RequirePermission(rbac.OrderWriteAny), app.orderHandler.UpdateOrderStatus)
+	api.Delete("/orders/:id", middleware.RequirePermission(rbac.OrderWriteAny), app.orderHandler.DeleteOrder)
 	api.Get("/orders/:id/invoice", app.orderHandler.GetInvoice)
 	api.Get("/orders/:id/print-invoice", app.orderHandler.PrintInvoice)
 
@@ -1,43 +1,199 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	accountHandler "ecommerce/internal/auth/handler"
 	"ecommerce/internal/auth/infra"
+	"ecommerce/internal/auth/repository"
 	"ecommerce/internal/auth/usecase"
+	digitalHandler "ecommerce/internal/digital/handler"
+	digitalInfra "ecommerce/internal/digital/infra"
+	digitalUsecase "ecommerce/internal/digital/usecase"
+	inventoryHandler "ecommerce/internal/inventory/handler"
+	inventoryInfra "ecommerce/internal/inventory/infra"
+	inventoryUsecase "ecommerce/internal/inventory/usecase"
 	orderHandler "ecommerce/internal/order/handler"
 	orderRepo "ecommerce/internal/order/infra"
 	orderUsecase "ecommerce/internal/order/usecase"
 	productHandler "ecommerce/internal/product/handler"
 	productPGRepo "ecommerce/internal/product/infra"
 	productUsecase "ecommerce/internal/product/usecase"
+	recommendationHandler "ecommerce/internal/recommendation/handler"
+	recommendationInfra "ecommerce/internal/recommendation/infra"
+	recommendationUsecase "ecommerce/internal/recommendation/usecase"
+	reviewHandler "ecommerce/internal/review/handler"
+	reviewInfra "ecommerce/internal/review/infra"
+	reviewUsecase "ecommerce/internal/review/usecase"
 	userInfra "ecommerce/internal/user/infra"
 	userUC "ecommerce/internal/user/usecase"
 	"ecommerce/internal/user/userHandler"
+	warehouseHandler "ecommerce/internal/warehouse/handler"
+	warehouseInfra "ecommerce/internal/warehouse/infra"
+	warehouseUsecase "ecommerce/internal/warehouse/usecase"
+	wishlistHandler "ecommerce/internal/wishlist/handler"
+	wishlistInfra "ecommerce/internal/wishlist/infra"
+	wishlistUsecase "ecommerce/internal/wishlist/usecase"
+	"ecommerce/pkg/cache"
+	"ecommerce/pkg/config"
+	"ecommerce/pkg/notify"
+	"ecommerce/pkg/oidc"
+	"ecommerce/pkg/scheduler"
+	"ecommerce/pkg/storage"
+	"ecommerce/pkg/utils"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func setupApplication(database *sql.DB) *application {
 	// Initialize repository
+	notifier := notify.NewFromEnv()
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost" + port
+	}
+
+	// Failed logins are counted in the database so every instance sees them;
+	// a single instance can keep them in memory instead
+	var loginAttempts repository.ILoginAttemptRepository = infra.NewLoginAttemptPGRepository(database)
+	if os.Getenv("LOGIN_ATTEMPT_STORE") == "memory" {
+		loginAttempts = infra.NewLoginAttemptMemoryRepository()
+	}
+
+	// Without two-factor authentication, accounts only get the permissions
+	// of customers in their tokens when this is set
+	requireAdminTwoFactor, _ := strconv.ParseBool(os.Getenv("REQUIRE_ADMIN_2FA"))
+
 	accountRepo := infra.NewAccountPGRepository(database)
-	accountUsecase := usecase.NewAccountUsecase(accountRepo)
+	accountUsecase := usecase.NewAccountUsecase(accountRepo, loginAttempts, notifier, appURL, requireAdminTwoFactor)
 	ah := accountHandler.NewAccountHandler(accountUsecase)
 
+	rlr := infra.NewRolePGRepository(database)
+	rlu := usecase.NewRoleUsecase(rlr)
+	rlh := accountHandler.NewRoleHandler(rlu)
+
+	akr := infra.NewAPIKeyPGRepository(database)
+	aku := usecase.NewAPIKeyUsecase(akr)
+	akh := accountHandler.NewAPIKeyHandler(aku)
+
+	// Sign-in with an identity provider is on once OIDC_ISSUER is set
+	var oidcProvider *oidc.Provider
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		oidcConfig := config.NewOIDCConfig(issuer, os.Getenv("OIDC_CLIENT_ID"), os.Getenv("OIDC_CLIENT_SECRET"),
+			os.Getenv("OIDC_REDIRECT_URL"), strings.Fields(os.Getenv("OIDC_SCOPES")))
+		oidcProvider = oidc.NewProvider(oidcConfig, &http.Client{Timeout: 10 * time.Second})
+	}
+	oidcu := usecase.NewOIDCUsecase(oidcProvider, infra.NewOIDCPGRepository(database), accountRepo, notifier)
+	oidch := accountHandler.NewOIDCHandler(oidcu, accountUsecase)
+
+	// Unverified accounts can still order unless this is set
+	requireVerifiedEmail, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+
+	keyRotation := scheduler.IntervalFromEnv("JWT_KEY_ROTATION", 30*24*time.Hour)
+	if keyRotation < 2*usecase.KeyPublishLead {
+		log.Fatalf("JWT_KEY_ROTATION must be at least %s", 2*usecase.KeyPublishLead)
+	}
+	keyAlgorithm := os.Getenv("JWT_ALGORITHM")
+	if keyAlgorithm == "" {
+		keyAlgorithm = utils.AlgRS256
+	}
+	if keyAlgorithm != utils.AlgRS256 && keyAlgorithm != utils.AlgEdDSA {
+		log.Fatalf("JWT_ALGORITHM must be %s or %s", utils.AlgRS256, utils.AlgEdDSA)
+	}
+
+	skr := infra.NewSigningKeyPGRepository(database)
+	sku := usecase.NewSigningKeyUsecase(skr, keyAlgorithm, keyRotation)
+	// Nothing can log in until there is a key to sign with
+	if err := sku.RotateKeys(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
 	ur := userInfra.NewUserPGRepository(database)
 	uu := userUC.NewUserUsecase(ur)
 	uh := userHandler.NewUserHandler(*uu)
 
+	store, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	pr := productPGRepo.NewProductPGRepository(database)
-	pu := productUsecase.NewProductUsecase(pr)
+	pir := productPGRepo.NewProductImagePGRepository(database)
+	pimr := productPGRepo.NewProductImportPGRepository(database)
+	pu := productUsecase.NewProductUsecase(pr, pir, pimr, store, cache.NewFromEnv())
 	ph := productHandler.NewProductHandler(*pu)
 
+	ppr := productPGRepo.NewPricePGRepository(database)
+	ppu := productUsecase.NewPriceUsecase(ppr)
+	pph := productHandler.NewPriceHandler(ppu)
+
 	or := orderRepo.NewOrderPGRepository(database)
 	ou := orderUsecase.NewOrderUsecase(or)
 	oh := orderHandler.NewOrderHandler(ou)
 
+	privateStore, err := storage.NewPrivateFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Without a key anyone could sign download links
+	signingKey := os.Getenv("DOWNLOAD_SIGNING_KEY")
+	if signingKey == "" {
+		log.Fatal("DOWNLOAD_SIGNING_KEY must be set")
+	}
+
+	dr := digitalInfra.NewDigitalPGRepository(database)
+	du := digitalUsecase.NewDigitalUsecase(dr, privateStore, []byte(signingKey))
+	dh := digitalHandler.NewDigitalHandler(du)
+
+	rr := reviewInfra.NewReviewPGRepository(database)
+	ru := reviewUsecase.NewReviewUsecase(rr)
+	rh := reviewHandler.NewReviewHandler(ru)
+
+	rcr := recommendationInfra.NewRecommendationPGRepository(database)
+	rcu := recommendationUsecase.NewRecommendationUsecase(rcr)
+	rch := recommendationHandler.NewRecommendationHandler(rcu)
+
+	wlr := wishlistInfra.NewWishlistPGRepository(database)
+	wlu := wishlistUsecase.NewWishlistUsecase(wlr, notifier)
+	wlh := wishlistHandler.NewWishlistHandler(wlu)
+
+	ir := inventoryInfra.NewInventoryPGRepository(database)
+	iu := inventoryUsecase.NewInventoryUsecase(ir, notifier)
+	ih := inventoryHandler.NewInventoryHandler(iu)
+
+	wr := warehouseInfra.NewWarehousePGRepository(database)
+	wu := warehouseUsecase.NewWarehouseUsecase(wr)
+	wh := warehouseHandler.NewWarehouseHandler(wu)
+
 	return &application{
-		accountHandler: ah,
-		userHandler:    uh,
-		productHandler: ph,
-		orderHandler:   oh,
+		accountHandler:        ah,
+		roleHandler:           rlh,
+		apiKeyHandler:         akh,
+		oidcHandler:           oidch,
+		userHandler:           uh,
+		productHandler:        ph,
+		priceHandler:          pph,
+		orderHandler:          oh,
+		digitalHandler:        dh,
+		reviewHandler:         rh,
+		recommendationHandler: rch,
+		inventoryHandler:      ih,
+		warehouseHandler:      wh,
+		wishlistHandler:       wlh,
+		accountUsecase:        accountUsecase,
+		signingKeyUsecase:     sku,
+		apiKeyUsecase:         aku,
+		oidcUsecase:           oidcu,
+		requireVerifiedEmail:  requireVerifiedEmail,
+		inventoryUsecase:      iu,
+		priceUsecase:          ppu,
+		recommendationUsecase: rcu,
+		wishlistUsecase:       wlu,
 	}
 }
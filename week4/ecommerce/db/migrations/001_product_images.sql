@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_images
+(
+    id            SERIAL PRIMARY KEY,
+    product_id    INT          NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    position      INT          NOT NULL DEFAULT 0,
+    original_key  VARCHAR(255) NOT NULL,
+    original_url  VARCHAR(512) NOT NULL,
+    medium_key    VARCHAR(255) NOT NULL,
+    medium_url    VARCHAR(512) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    thumbnail_url VARCHAR(512) NOT NULL,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images (product_id, position);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS inventory_movements
+(
+    id          SERIAL PRIMARY KEY,
+    product_id  INT          NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity    INT          NOT NULL,
+    reason      VARCHAR(20)  NOT NULL CHECK (reason IN ('sale', 'cancellation', 'return', 'receipt', 'adjustment', 'stock_take')),
+    note        TEXT,
+    actor       VARCHAR(100),
+    order_id    INT,
+    stock_after INT          NOT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product ON inventory_movements (product_id, id);
+
+-- Opening balance so that existing stock equals the sum of movements
+INSERT INTO inventory_movements (product_id, quantity, reason, note, actor, stock_after)
+SELECT p.id, p.stock, 'stock_take', 'opening balance', 'migration', p.stock
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM inventory_movements m WHERE m.product_id = p.id);
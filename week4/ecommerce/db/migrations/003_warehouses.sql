@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS warehouses
+(
+    id         SERIAL PRIMARY KEY,
+    code       VARCHAR(20)  NOT NULL UNIQUE,
+    name       VARCHAR(100) NOT NULL,
+    latitude   DOUBLE PRECISION,
+    longitude  DOUBLE PRECISION,
+    is_default BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Movements without an explicit warehouse are booked against the default one
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default ON warehouses (is_default) WHERE is_default;
+
+INSERT INTO warehouses (code, name, is_default)
+VALUES ('MAIN', 'Main warehouse', TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS warehouse_stock
+(
+    warehouse_id INT NOT NULL REFERENCES warehouses (id),
+    product_id   INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity     INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+-- Existing stock is moved into the default warehouse
+INSERT INTO warehouse_stock (warehouse_id, product_id, quantity)
+SELECT w.id, p.id, p.stock
+FROM products p
+         CROSS JOIN warehouses w
+WHERE w.is_default
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE inventory_movements
+    ADD COLUMN IF NOT EXISTS warehouse_id INT REFERENCES warehouses (id);
+
+UPDATE inventory_movements
+SET warehouse_id = (SELECT id FROM warehouses WHERE is_default)
+WHERE warehouse_id IS NULL;
+
+ALTER TABLE inventory_movements
+    DROP CONSTRAINT IF EXISTS inventory_movements_reason_check;
+
+ALTER TABLE inventory_movements
+    ADD CONSTRAINT inventory_movements_reason_check
+        CHECK (reason IN ('sale', 'cancellation', 'return', 'receipt', 'adjustment', 'stock_take', 'transfer'));
+
+CREATE TABLE IF NOT EXISTS stock_transfers
+(
+    id                SERIAL PRIMARY KEY,
+    product_id        INT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    from_warehouse_id INT       NOT NULL REFERENCES warehouses (id),
+    to_warehouse_id   INT       NOT NULL REFERENCES warehouses (id),
+    quantity          INT       NOT NULL CHECK (quantity > 0),
+    note              TEXT,
+    actor             VARCHAR(100),
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reorder_threshold INT NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0),
+    ADD COLUMN IF NOT EXISTS lead_time_days    INT NOT NULL DEFAULT 7 CHECK (lead_time_days >= 0);
+
+-- One open alert per product; resolved_at is set once stock is back above the threshold
+CREATE TABLE IF NOT EXISTS low_stock_alerts
+(
+    id          SERIAL PRIMARY KEY,
+    product_id  INT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    stock       INT       NOT NULL,
+    threshold   INT       NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_low_stock_alerts_open ON low_stock_alerts (product_id) WHERE resolved_at IS NULL;
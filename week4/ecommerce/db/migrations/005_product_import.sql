@@ -0,0 +1,20 @@
+-- Stock keeping unit used to match spreadsheet rows to existing products
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku) WHERE sku IS NOT NULL;
+
+-- Background imports; result holds the counts and per-row errors as JSON
+CREATE TABLE IF NOT EXISTS product_imports
+(
+    id          SERIAL PRIMARY KEY,
+    filename    VARCHAR(255) NOT NULL,
+    format      VARCHAR(8)   NOT NULL,
+    dry_run     BOOLEAN      NOT NULL DEFAULT FALSE,
+    status      VARCHAR(16)  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    result      JSONB,
+    error       TEXT         NOT NULL DEFAULT '',
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
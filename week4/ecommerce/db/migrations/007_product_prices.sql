@@ -0,0 +1,37 @@
+-- Every price a product has had, newest last
+CREATE TABLE IF NOT EXISTS product_prices
+(
+    id          SERIAL PRIMARY KEY,
+    product_id  INT              NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    old_price   DOUBLE PRECISION,
+    new_price   DOUBLE PRECISION NOT NULL CHECK (new_price >= 0),
+    source      VARCHAR(20)      NOT NULL CHECK (source IN ('initial', 'manual', 'import', 'schedule_start', 'schedule_end')),
+    schedule_id INT,
+    actor       VARCHAR(100),
+    created_at  TIMESTAMP        NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices (product_id, id);
+
+-- Future prices; ends_at NULL makes the change permanent. previous_price is
+-- captured when the schedule starts and restored when it ends.
+CREATE TABLE IF NOT EXISTS price_schedules
+(
+    id             SERIAL PRIMARY KEY,
+    product_id     INT              NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price          DOUBLE PRECISION NOT NULL CHECK (price >= 0),
+    starts_at      TIMESTAMP        NOT NULL,
+    ends_at        TIMESTAMP CHECK (ends_at > starts_at),
+    status         VARCHAR(16)      NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'done', 'cancelled')),
+    previous_price DOUBLE PRECISION,
+    created_by     VARCHAR(100),
+    created_at     TIMESTAMP        NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_schedules_due ON price_schedules (status, starts_at);
+
+-- Opening entry so the history starts from today's price
+INSERT INTO product_prices (product_id, new_price, source, actor)
+SELECT p.id, COALESCE(p.price, 0), 'initial', 'migration'
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = p.id);
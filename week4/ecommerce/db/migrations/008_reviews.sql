@@ -0,0 +1,28 @@
+-- Orders move placed -> shipped -> delivered; only delivered orders make a
+-- customer a verified buyer
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status       VARCHAR(16) NOT NULL DEFAULT 'placed' CHECK (status IN ('placed', 'shipped', 'delivered')),
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+
+-- Running totals of approved reviews, updated with every moderation decision
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS rating_sum   INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0 CHECK (rating_count >= 0);
+
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id              SERIAL PRIMARY KEY,
+    product_id      INT         NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    user_id         INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    rating          SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body            TEXT        NOT NULL DEFAULT '',
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    moderation_note TEXT        NOT NULL DEFAULT '',
+    moderated_by    VARCHAR(100),
+    moderated_at    TIMESTAMP,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews (status, created_at);
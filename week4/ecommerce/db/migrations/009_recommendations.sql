@@ -0,0 +1,11 @@
+-- "Frequently bought together" scores, rebuilt from order_lines by the
+-- recommendation job. Only the best related products per product are kept.
+CREATE TABLE IF NOT EXISTS product_affinities
+(
+    product_id  INT              NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    related_id  INT              NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    co_orders   INT              NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, related_id)
+);
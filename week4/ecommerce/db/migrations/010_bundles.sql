@@ -0,0 +1,38 @@
+-- Bundles are products sold at their own price but made of other products.
+-- They hold no stock themselves; selling one takes its components out of stock.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS bundle_components
+(
+    bundle_id    INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    component_id INT NOT NULL REFERENCES products (id),
+    quantity     INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bundle_components_component ON bundle_components (component_id);
+
+-- Sellable quantity of every product: its own stock, or for a bundle the
+-- number of complete kits its components make. A deleted component makes the
+-- bundle unavailable.
+CREATE OR REPLACE VIEW product_availability AS
+SELECT p.id AS product_id,
+       CASE
+           WHEN p.is_bundle THEN COALESCE((SELECT MIN(CASE WHEN c.deleted_at IS NULL THEN c.stock / bc.quantity ELSE 0 END)
+                                           FROM bundle_components bc
+                                                    JOIN products c ON c.id = bc.component_id
+                                           WHERE bc.bundle_id = p.id), 0)
+           ELSE COALESCE(p.stock, 0)
+           END AS available
+FROM products p;
+
+-- What each bundle line was made of when it was sold, for the invoice
+CREATE TABLE IF NOT EXISTS order_line_components
+(
+    order_line_id INT NOT NULL REFERENCES order_lines (id) ON DELETE CASCADE,
+    product_id    INT NOT NULL REFERENCES products (id),
+    qty           INT NOT NULL CHECK (qty > 0),
+    PRIMARY KEY (order_line_id, product_id)
+);
@@ -0,0 +1,51 @@
+-- Digital products are delivered as a download or a licence key instead of
+-- from warehouse stock
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS digital   VARCHAR(16) CHECK (digital IN ('download', 'licence_key')),
+    ADD COLUMN IF NOT EXISTS file_key  TEXT,
+    ADD COLUMN IF NOT EXISTS file_name TEXT;
+
+-- Pool of licence keys; a key is handed out once and never returns to the
+-- pool, even when its order is cancelled
+CREATE TABLE IF NOT EXISTS licence_keys
+(
+    id            SERIAL PRIMARY KEY,
+    product_id    INT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    licence_key   TEXT      NOT NULL,
+    order_line_id INT REFERENCES order_lines (id) ON DELETE SET NULL,
+    assigned_at   TIMESTAMP,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, licence_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_licence_keys_free ON licence_keys (product_id, id) WHERE assigned_at IS NULL;
+
+-- One grant per downloadable order line, limited in time and count
+CREATE TABLE IF NOT EXISTS download_grants
+(
+    id             SERIAL PRIMARY KEY,
+    order_line_id  INT       NOT NULL REFERENCES order_lines (id) ON DELETE CASCADE,
+    product_id     INT       NOT NULL REFERENCES products (id),
+    expires_at     TIMESTAMP NOT NULL,
+    max_downloads  INT       NOT NULL CHECK (max_downloads > 0),
+    download_count INT       NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Same as in 010, plus digital products: licence key products have as many
+-- as there are free keys and downloads are unlimited (NULL)
+CREATE OR REPLACE VIEW product_availability AS
+SELECT p.id AS product_id,
+       CASE
+           WHEN p.digital = 'download' THEN NULL
+           WHEN p.digital = 'licence_key' THEN (SELECT COUNT(*)::int
+                                                FROM licence_keys k
+                                                WHERE k.product_id = p.id
+                                                  AND k.assigned_at IS NULL)
+           WHEN p.is_bundle THEN COALESCE((SELECT MIN(CASE WHEN c.deleted_at IS NULL THEN c.stock / bc.quantity ELSE 0 END)
+                                           FROM bundle_components bc
+                                                    JOIN products c ON c.id = bc.component_id
+                                           WHERE bc.bundle_id = p.id), 0)
+           ELSE COALESCE(p.stock, 0)
+           END AS available
+FROM products p;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS wishlist_items
+(
+    user_id    INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    product_id INT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    added_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, product_id)
+);
+
+-- A shared wishlist is readable by anyone holding its token
+CREATE TABLE IF NOT EXISTS wishlist_shares
+(
+    user_id    INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token      VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Back-in-stock subscriptions are closed by setting notified_at when the
+-- notification goes out; the user can subscribe again afterwards
+CREATE TABLE IF NOT EXISTS stock_subscriptions
+(
+    id          SERIAL PRIMARY KEY,
+    user_id     INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    product_id  INT       NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_subscriptions_open ON stock_subscriptions (user_id, product_id) WHERE notified_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_subscriptions_product ON stock_subscriptions (product_id) WHERE notified_at IS NULL;
@@ -0,0 +1,51 @@
+-- Last change to a product, used for ETag and Last-Modified on catalog reads.
+-- Triggers keep it current for every write to the row (price, stock, rating,
+-- deletion) and to its gallery and bundle components.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE OR REPLACE FUNCTION touch_product() RETURNS trigger AS
+$$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_touch ON products;
+CREATE TRIGGER products_touch
+    BEFORE UPDATE ON products
+    FOR EACH ROW
+EXECUTE FUNCTION touch_product();
+
+-- Changes to images and components are changes to the product they belong
+-- to. OLD and NEW are NULL on insert and delete respectively.
+CREATE OR REPLACE FUNCTION touch_image_product() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE products SET updated_at = CURRENT_TIMESTAMP
+    WHERE id IN (OLD.product_id, NEW.product_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION touch_bundle() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE products SET updated_at = CURRENT_TIMESTAMP
+    WHERE id IN (OLD.bundle_id, NEW.bundle_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_images_touch ON product_images;
+CREATE TRIGGER product_images_touch
+    AFTER INSERT OR UPDATE OR DELETE ON product_images
+    FOR EACH ROW
+EXECUTE FUNCTION touch_image_product();
+
+DROP TRIGGER IF EXISTS bundle_components_touch ON bundle_components;
+CREATE TRIGGER bundle_components_touch
+    AFTER INSERT OR UPDATE OR DELETE ON bundle_components
+    FOR EACH ROW
+EXECUTE FUNCTION touch_bundle();
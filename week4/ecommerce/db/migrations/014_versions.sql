@@ -0,0 +1,11 @@
+-- Row versions for optimistic concurrency. Every write to an editable
+-- column bumps the version; updates sent with If-Match only apply when the
+-- version is unchanged. Versions only grow, they do not count edits.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
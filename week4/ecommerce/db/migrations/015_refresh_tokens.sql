@@ -0,0 +1,28 @@
+-- Refresh tokens are stored as SHA-256 hashes. Each login starts a family;
+-- a refresh marks the token used and issues the next one in the family, so
+-- a used token coming back means it was copied and the family is revoked.
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id                SERIAL PRIMARY KEY,
+    account_id        INT       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    family_id         TEXT      NOT NULL,
+    token_hash        TEXT      NOT NULL UNIQUE,
+    -- The access token issued together with this refresh token, revoked
+    -- along with the family
+    access_jti        TEXT      NOT NULL,
+    access_expires_at TIMESTAMP NOT NULL,
+    expires_at        TIMESTAMP NOT NULL,
+    used_at           TIMESTAMP,
+    revoked_at        TIMESTAMP,
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+
+-- Access tokens revoked before they expire, by JWT ID. Rows can go once the
+-- token has expired anyway.
+CREATE TABLE IF NOT EXISTS revoked_tokens
+(
+    jti        TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Keys access tokens are signed with, shared by every instance. A new key
+-- is inserted ahead of its active_at so it is published in the JWKS before
+-- anything is signed with it; a key is deleted once tokens signed with it
+-- have expired.
+CREATE TABLE IF NOT EXISTS signing_keys
+(
+    id          TEXT PRIMARY KEY,
+    algorithm   TEXT      NOT NULL,
+    -- PKCS#8 PEM
+    private_key TEXT      NOT NULL,
+    active_at   TIMESTAMP NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_active_at ON signing_keys (active_at);
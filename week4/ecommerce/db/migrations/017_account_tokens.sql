@@ -0,0 +1,25 @@
+-- Accounts created before email verification existed are treated as
+-- verified, so turning on REQUIRE_VERIFIED_EMAIL does not lock them out.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+UPDATE accounts
+SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP)
+WHERE email_verified_at IS NULL;
+
+-- Single-use tokens mailed for password resets and email verification,
+-- stored as SHA-256 hashes. email is the address a verification token was
+-- sent to, so it stops working if the address changes.
+CREATE TABLE IF NOT EXISTS account_tokens
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INT       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    purpose    TEXT      NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+    token_hash TEXT      NOT NULL UNIQUE,
+    email      TEXT      NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_account ON account_tokens (account_id, purpose);
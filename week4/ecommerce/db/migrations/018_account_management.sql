@@ -0,0 +1,14 @@
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS locked_at     TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS locked_reason TEXT;
+
+-- Set when a user deleted their account. The row stays, stripped of
+-- personal data, so their orders and invoices still resolve.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+
+-- Roles assigned through the admin API
+INSERT INTO roles (role_name)
+SELECT role_name
+FROM (VALUES ('user'), ('admin')) AS r (role_name)
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE roles.role_name = r.role_name);
@@ -0,0 +1,42 @@
+-- Permissions granted to roles. The permission names are defined by the
+-- application (pkg/rbac); an account can hold several roles and gets the
+-- union of their permissions.
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS description TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_role_name ON roles (role_name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_auth_role ON user_roles (auth_id, role_id);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id    INT  NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+UPDATE roles SET description = 'Customers' WHERE role_name = 'user' AND description IS NULL;
+UPDATE roles SET description = 'Staff with full access' WHERE role_name = 'admin' AND description IS NULL;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+         JOIN (VALUES ('user', 'order:create'),
+                      ('user', 'review:write'),
+                      ('user', 'wishlist:write'),
+                      ('admin', 'product:write'),
+                      ('admin', 'product:read:deleted'),
+                      ('admin', 'price:write'),
+                      ('admin', 'inventory:write'),
+                      ('admin', 'report:read'),
+                      ('admin', 'order:create'),
+                      ('admin', 'order:read:any'),
+                      ('admin', 'order:write:any'),
+                      ('admin', 'review:write'),
+                      ('admin', 'review:moderate'),
+                      ('admin', 'wishlist:write'),
+                      ('admin', 'user:read:any'),
+                      ('admin', 'user:write:any'),
+                      ('admin', 'account:manage'),
+                      ('admin', 'role:manage')) AS p (role_name, permission)
+              ON p.role_name = r.role_name
+ON CONFLICT DO NOTHING;
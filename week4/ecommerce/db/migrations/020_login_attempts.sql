@@ -0,0 +1,11 @@
+-- Failed logins per account ("account:<username>") and per client IP
+-- ("ip:<address>"), shared by every instance of the application.
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key             TEXT PRIMARY KEY,
+    failures        INT       NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure ON login_attempts (last_failure_at);
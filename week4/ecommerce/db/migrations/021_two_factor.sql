@@ -0,0 +1,29 @@
+-- TOTP two-factor authentication. totp_secret is stored on enrolment but
+-- only asked for once the first code confirmed it (totp_enabled_at).
+-- totp_last_step is the time step of the last code accepted, so a code
+-- cannot be used twice.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS totp_secret     TEXT,
+    ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS totp_last_step  BIGINT;
+
+-- Single-use codes for logging in without the authenticator, stored as
+-- SHA-256 hashes
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INT       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    code_hash  TEXT      NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_account ON recovery_codes (account_id);
+
+-- A login with the right password returns a challenge, exchanged together
+-- with a code for the tokens
+ALTER TABLE account_tokens
+    DROP CONSTRAINT IF EXISTS account_tokens_purpose_check;
+ALTER TABLE account_tokens
+    ADD CONSTRAINT account_tokens_purpose_check
+        CHECK (purpose IN ('password_reset', 'email_verification', 'login_challenge'));
@@ -0,0 +1,24 @@
+-- API keys for other services, sent in the X-API-Key header. Only the
+-- SHA-256 hash of a key is stored; the name is unique among keys that are
+-- not revoked.
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           SERIAL PRIMARY KEY,
+    name         TEXT      NOT NULL,
+    prefix       TEXT      NOT NULL,
+    key_hash     TEXT      NOT NULL UNIQUE,
+    permissions  TEXT[]    NOT NULL,
+    created_by   TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at   TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_active_name ON api_keys (name) WHERE revoked_at IS NULL;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'apikey:manage'
+FROM roles
+WHERE role_name = 'admin'
+ON CONFLICT DO NOTHING;
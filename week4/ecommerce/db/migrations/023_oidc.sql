@@ -0,0 +1,25 @@
+-- Sign-in with an OpenID Connect identity provider. oidc_logins holds the
+-- nonce and PKCE verifier of a sign-in in progress, found by the hash of
+-- its state; account_identities links a subject of a provider (issuer) to
+-- an account.
+CREATE TABLE IF NOT EXISTS oidc_logins
+(
+    state_hash    TEXT PRIMARY KEY,
+    nonce         TEXT      NOT NULL,
+    code_verifier TEXT      NOT NULL,
+    expires_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_logins_expires ON oidc_logins (expires_at);
+
+CREATE TABLE IF NOT EXISTS account_identities
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INT       NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    issuer     TEXT      NOT NULL,
+    subject    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_identities_account ON account_identities (account_id);
@@ -1,9 +1,27 @@
 package entity
 
 import (
+	"errors"
 	"time"
 )
 
+// Built-in roles. Customers get the user role on registration.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var (
+	ErrAccountLocked = errors.New("account is locked")
+	ErrOwnAccount    = errors.New("admins cannot lock or change the roles of their own account")
+)
+
+// AccountUpdate is what account holders can change about themselves.
+type AccountUpdate struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 type Account struct {
 	ID        int        `json:"id,omitempty"`
 	UserID    int        `json:"user_id,omitempty"`
@@ -14,6 +32,25 @@ type Account struct {
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 	Role      string     `json:"role,omitempty"`
+	// Roles are all roles of the account and Permissions what they grant
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// EmailVerifiedAt is nil until the address is confirmed
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// A locked account cannot log in or refresh its tokens
+	LockedAt     *time.Time `json:"locked_at,omitempty"`
+	LockedReason string     `json:"locked_reason,omitempty"`
+	// TOTPSecret is set on enrolment, but only asked for at login once
+	// TwoFactorEnabledAt is set. TOTPLastStep is the step of the last code
+	// accepted, -1 before the first.
+	TOTPSecret         string     `json:"-"`
+	TOTPLastStep       int64      `json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at,omitempty"`
+}
+
+// TwoFactorEnabled reports whether logins need a second factor.
+func (a *Account) TwoFactorEnabled() bool {
+	return a.TwoFactorEnabledAt != nil
 }
 
 type AccountBuilder struct {
@@ -57,3 +94,27 @@ func (a *Account) SetRole(role string) *Account {
 	a.Role = role
 	return a
 }
+
+// SetRoles sets the roles of the account. Its primary Role is admin or
+// user when it has one of them, otherwise the first role.
+func (a *Account) SetRoles(roles []string) *Account {
+	a.Roles = roles
+	a.Role = primaryRole(roles)
+	return a
+}
+
+func primaryRole(roles []string) string {
+	for _, preferred := range []string{RoleAdmin, RoleUser} {
+		for _, role := range roles {
+			if role == preferred {
+				return role
+			}
+		}
+	}
+
+	if len(roles) > 0 {
+		return roles[0]
+	}
+
+	return ""
+}
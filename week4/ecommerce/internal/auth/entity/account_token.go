@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Purposes of an AccountToken.
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+	PurposeLoginChallenge    = "login_challenge"
+)
+
+const (
+	PasswordResetTTL     = time.Hour
+	EmailVerificationTTL = 48 * time.Hour
+	MinPasswordLength    = 8
+)
+
+var (
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrWrongPassword            = errors.New("current password is incorrect")
+	ErrWeakPassword             = errors.New("password must be at least 8 characters")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrAccountNotFound          = errors.New("account not found")
+)
+
+// AccountToken is a single-use token mailed to the account holder. Only
+// its hash is stored.
+type AccountToken struct {
+	ID        int
+	AccountID int
+	Purpose   string
+	TokenHash string
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so a leaked one is easy to spot
+	APIKeyPrefix = "ek_"
+	// APIKeyLastUsedInterval is how often the last use of a key is saved,
+	// rather than writing on every request
+	APIKeyLastUsedInterval = time.Minute
+	// APIKeyUsernamePrefix and the key's name make the username requests
+	// made with an API key act as
+	APIKeyUsernamePrefix = "api-key:"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrAPIKeyExists        = errors.New("an active API key with this name already exists")
+	ErrInvalidAPIKeyName   = errors.New("API key name must be 1-50 lowercase letters, digits, '-' or '_'")
+	ErrAPIKeyNoPermissions = errors.New("an API key needs at least one permission")
+	ErrAPIKeyPermission    = errors.New("an API key cannot have permissions its creator does not have")
+	ErrAPIKeyExpiry        = errors.New("expires_at must be in the future")
+)
+
+// APIKey lets another service call the API without a login. Only the hash
+// of the key is kept; Prefix tells keys apart in listings.
+type APIKey struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Permissions []string   `json:"permissions"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// Active reports whether the key is accepted at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreatedAPIKey is returned once, when the key is created; Key cannot be
+// shown again.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Failed logins are counted per account and per client IP. For accounts,
+// every failure makes the next attempt wait twice as long, up to
+// LoginBackoffMax.
+const (
+	LoginBackoffBase = time.Second
+	LoginBackoffMax  = 30 * time.Second
+)
+
+// LoginLimit is how failed logins are throttled for one kind of key.
+type LoginLimit struct {
+	// MaxFailures in a row lock the key out
+	MaxFailures int
+	// Window is how long a failure is remembered
+	Window time.Duration
+	// Lockout is how long the key is locked out for
+	Lockout time.Duration
+	// Backoff makes every failure slow down the next attempt
+	Backoff bool
+}
+
+// An IP is allowed more failures than an account and does not back off, as
+// many users can share one address and log in at the same time.
+var (
+	AccountLoginLimit = LoginLimit{MaxFailures: 5, Window: time.Hour, Lockout: 15 * time.Minute, Backoff: true}
+	IPLoginLimit      = LoginLimit{MaxFailures: 50, Window: time.Hour, Lockout: 15 * time.Minute}
+)
+
+// LoginAttempts are the logins counted against a key, such as
+// "account:alice" or "ip:203.0.113.7": the failed ones and those whose
+// password is still being checked.
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// RetryAfter returns how long to wait before the next login under limit, or
+// 0 when one may be made now. Attempts still being checked count as
+// failures, so logins made at the same time can use up MaxFailures before
+// any of them locked the key out; they wait for the lockout as well.
+func (a *LoginAttempts) RetryAfter(now time.Time, limit LoginLimit) time.Duration {
+	if a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+		return a.LockedUntil.Sub(now)
+	}
+	if a.Failures == 0 || now.Sub(a.LastFailureAt) > limit.Window {
+		return 0
+	}
+	if a.Failures >= limit.MaxFailures {
+		return limit.Lockout
+	}
+	if !limit.Backoff {
+		return 0
+	}
+
+	next := a.LastFailureAt.Add(LoginBackoff(a.Failures))
+	if now.Before(next) {
+		return next.Sub(now)
+	}
+
+	return 0
+}
+
+// LoginBackoff is the wait after failures failed logins in a row.
+func LoginBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := LoginBackoffBase
+	for i := 1; i < failures && delay < LoginBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > LoginBackoffMax {
+		delay = LoginBackoffMax
+	}
+
+	return delay
+}
+
+// LoginThrottledError is returned for a login made before RetryAfter has
+// passed. The password is not checked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
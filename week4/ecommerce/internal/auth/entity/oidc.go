@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// OIDCLoginTTL is how long a user has to sign in at the identity provider
+const OIDCLoginTTL = 10 * time.Minute
+
+var (
+	ErrOIDCDisabled         = errors.New("sign-in with the identity provider is not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired sign-in, start again")
+	ErrOIDCSignInFailed     = errors.New("sign-in with the identity provider failed")
+	ErrOIDCEmailNotVerified = errors.New("the identity provider has not verified your email address")
+	ErrOIDCAccountConflict  = errors.New("an account with this email exists but has not verified it; log in with your password and verify it first")
+)
+
+// OIDCLogin is a sign-in started at the identity provider. It is found by
+// the hash of its state when the provider sends the user back.
+type OIDCLogin struct {
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "errors"
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrInvalidRoleName   = errors.New("role name must be 1-50 lowercase letters, digits, '-' or '_'")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrBuiltInRole       = errors.New("the user and admin roles cannot be deleted")
+	ErrNoRoles           = errors.New("an account needs at least one role")
+	ErrAdminLockout      = errors.New("the admin role must keep the role:manage permission")
+)
+
+// Role groups permissions. Accounts hold any number of roles.
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	// Accounts is the number of accounts holding the role
+	Accounts int `json:"accounts"`
+}
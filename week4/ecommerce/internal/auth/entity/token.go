@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// RefreshTokenTTL is how long a refresh token can be used. Every refresh
+// issues a new token, so an active session does not expire.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, every session of this login has been signed out")
+)
+
+// TokenPair is returned on login and refresh. Token repeats AccessToken for
+// clients written before refresh tokens.
+type TokenPair struct {
+	Token        string `json:"token"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	// TwoFactorSetupRequired is set when the account's elevated permissions
+	// were left out of the tokens until it enables two-factor authentication
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
+}
+
+// RefreshToken is a stored refresh token. Only the hash of the token is
+// kept.
+type RefreshToken struct {
+	ID              int
+	AccountID       int
+	Username        string
+	FamilyID        string
+	TokenHash       string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+}
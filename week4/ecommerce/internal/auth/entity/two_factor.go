@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// LoginChallengeTTL is how long the second step of a login can be made
+	LoginChallengeTTL = 5 * time.Minute
+	RecoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled  = errors.New("start two-factor enrolment first")
+	ErrTwoFactorNotEnabled   = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrInvalidLoginChallenge = errors.New("invalid or expired login challenge")
+)
+
+// TwoFactorEnrolment is shown once, for the user to add the secret to an
+// authenticator app, usually by scanning the URI as a QR code.
+type TwoFactorEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// LoginChallenge answers a login with the right password to an account with
+// two-factor authentication. The challenge token and a code are then
+// exchanged for a TokenPair.
+type LoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
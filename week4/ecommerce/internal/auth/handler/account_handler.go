@@ -3,7 +3,12 @@ package handler
 import (
 	"ecommerce/internal/auth/entity"
 	"ecommerce/internal/auth/usecase"
+	"ecommerce/pkg/middleware"
 	globalUtils "ecommerce/pkg/utils"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,6 +30,9 @@ func (h *AccountHandler) Register(c *fiber.Ctx) error {
 	}
 
 	err := h.au.Register(c.Context(), &account)
+	if errors.Is(err, entity.ErrWeakPassword) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -41,16 +49,413 @@ func (h *AccountHandler) Login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	account, err := h.au.Login(c.Context(), loginRequest.Username, loginRequest.Password)
+	account, err := h.au.Login(c.Context(), loginRequest.Username, loginRequest.Password, c.IP())
+	if err != nil {
+		return loginError(c, err)
+	}
+
+	return startSession(c, h.au, account)
+}
+
+// startSession answers a login that proved who the user is with tokens, or
+// with a challenge for the second step, CompleteLogin, when the account
+// has two-factor authentication.
+func startSession(c *fiber.Ctx, au usecase.IAccountUsecase, account *entity.Account) error {
+	if account.TwoFactorEnabled() {
+		challenge, err := au.CreateLoginChallenge(c.Context(), account)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(challenge)
+	}
+
+	tokens, err := au.IssueTokens(c.Context(), account)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (h *AccountHandler) CompleteLogin(c *fiber.Ctx) error {
+	var req struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.ChallengeToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "challenge_token and code are required"})
+	}
+
+	tokens, err := h.au.CompleteLogin(c.Context(), req.ChallengeToken, req.Code, c.IP())
 	if err != nil {
+		return loginError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func loginError(c *fiber.Ctx, err error) error {
+	var throttled *entity.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAccountLocked):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidCredentials), errors.Is(err, entity.ErrInvalidTwoFactorCode),
+		errors.Is(err, entity.ErrInvalidLoginChallenge):
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AccountHandler) Refresh(c *fiber.Ctx) error {
+	var req refreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token is required"})
 	}
 
-	// Generate JWT token
-	token, err := globalUtils.GenerateJWT(account.Username, account.Role)
+	tokens, err := h.au.Refresh(c.Context(), req.RefreshToken)
 	if err != nil {
+		return tokenError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// Logout revokes the refresh token in the body and the access token in the
+// Authorization header. Both are optional, but at least one is needed.
+func (h *AccountHandler) Logout(c *fiber.Ctx) error {
+	var req refreshRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	var claims *globalUtils.Claims
+	if authHeader := c.Get("Authorization"); authHeader != "" {
+		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+		var err error
+		claims, err = globalUtils.ValidateJWT(tokenStr)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	if req.RefreshToken == "" && claims == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token or an Authorization header is required"})
+	}
+
+	if err := h.au.Logout(c.Context(), req.RefreshToken, claims); err != nil {
+		return tokenError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// JWKS publishes the public keys access tokens are signed with, for other
+// services to verify them.
+func (h *AccountHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(globalUtils.SigningJWKS())
+}
+
+func tokenError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidRefreshToken), errors.Is(err, entity.ErrRefreshTokenReused):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	default:
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+}
+
+func (h *AccountHandler) ChangePassword(c *fiber.Ctx) error {
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err := h.au.ChangePassword(c.Context(), middleware.Username(c), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RequestPasswordReset always answers 202, whether or not the email belongs
+// to an account.
+func (h *AccountHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+
+	if err := h.au.RequestPasswordReset(c.Context(), req.Email); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "If the email belongs to an account, a reset link has been sent"})
+}
+
+func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.au.ResetPassword(c.Context(), req.Token, req.NewPassword); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.au.VerifyEmail(c.Context(), req.Token); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) ResendVerification(c *fiber.Ctx) error {
+	if err := h.au.ResendVerification(c.Context(), middleware.Username(c)); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Verification email sent"})
+}
+
+func (h *AccountHandler) GetMe(c *fiber.Ctx) error {
+	account, err := h.au.GetAccount(c.Context(), middleware.Username(c))
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(account)
+}
+
+func (h *AccountHandler) UpdateMe(c *fiber.Ctx) error {
+	var update entity.AccountUpdate
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	account, err := h.au.UpdateAccount(c.Context(), middleware.Username(c), &update)
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(account)
+}
+
+// DeleteMe deletes the caller's account. The password, and a code when
+// two-factor authentication is enabled, are asked for again so a stolen token
+// alone cannot delete it.
+func (h *AccountHandler) DeleteMe(c *fiber.Ctx) error {
+	var req struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.au.DeleteAccount(c.Context(), middleware.Username(c), req.Password, req.Code); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// EnrolTwoFactor starts two-factor enrolment, ConfirmTwoFactor finishes it.
+func (h *AccountHandler) EnrolTwoFactor(c *fiber.Ctx) error {
+	enrolment, err := h.au.EnrolTwoFactor(c.Context(), middleware.Username(c))
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(enrolment)
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *AccountHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	var req twoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	codes, err := h.au.ConfirmTwoFactor(c.Context(), middleware.Username(c), req.Code)
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (h *AccountHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	var req struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.au.DisableTwoFactor(c.Context(), middleware.Username(c), req.Password, req.Code); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req twoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	codes, err := h.au.RegenerateRecoveryCodes(c.Context(), middleware.Username(c), req.Code)
+	if err != nil {
+		return accountError(c, err)
+	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"token": token})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (h *AccountHandler) GetAllAccounts(c *fiber.Ctx) error {
+	accounts, err := h.au.GetAllAccounts(c.Context())
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(accounts)
+}
+
+func (h *AccountHandler) LockAccount(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	if err := h.au.LockAccount(c.Context(), middleware.Username(c), id, req.Reason); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) UnlockAccount(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.au.UnlockAccount(c.Context(), id); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) ResetTwoFactor(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.au.ResetTwoFactor(c.Context(), middleware.Username(c), id); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AccountHandler) SetRoles(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req struct {
+		Roles []string `json:"roles"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.au.SetRoles(c.Context(), middleware.Username(c), id, req.Roles); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func accountError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrWeakPassword), errors.Is(err, entity.ErrNoRoles), errors.Is(err, entity.ErrRoleNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrOwnAccount):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrEmailExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrWrongPassword):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidResetToken), errors.Is(err, entity.ErrInvalidVerificationToken):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrEmailAlreadyVerified):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidTwoFactorCode), errors.Is(err, entity.ErrTwoFactorNotEnrolled):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrTwoFactorEnabled), errors.Is(err, entity.ErrTwoFactorNotEnabled):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAccountNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 }
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	ku *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(ku *usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		ku: ku,
+	}
+}
+
+func (h *APIKeyHandler) GetAllAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.ku.GetAllAPIKeys(c.Context())
+	if err != nil {
+		return apiKeyError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
+
+// CreateAPIKey answers with the key itself, which is not shown again.
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var key entity.APIKey
+	if err := c.BodyParser(&key); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	created, err := h.ku.CreateAPIKey(c.Context(), middleware.Claims(c), &key)
+	if err != nil {
+		return apiKeyError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.ku.RevokeAPIKey(c.Context(), id); err != nil {
+		return apiKeyError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func apiKeyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAPIKeyExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidAPIKeyName), errors.Is(err, entity.ErrUnknownPermission),
+		errors.Is(err, entity.ErrAPIKeyNoPermissions), errors.Is(err, entity.ErrAPIKeyExpiry):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAPIKeyPermission):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
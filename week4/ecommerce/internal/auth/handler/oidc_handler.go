@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/usecase"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// oidcStateCookie keeps the state of a sign-in in the browser that started
+// it, so a callback link cannot sign someone else in.
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/auth/oidc"
+)
+
+// OIDCHandler signs users in with the identity provider, next to the
+// password login of AccountHandler.
+type OIDCHandler struct {
+	ou *usecase.OIDCUsecase
+	au usecase.IAccountUsecase
+}
+
+func NewOIDCHandler(ou *usecase.OIDCUsecase, au usecase.IAccountUsecase) *OIDCHandler {
+	return &OIDCHandler{
+		ou: ou,
+		au: au,
+	}
+}
+
+// Login sends the user to the identity provider.
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	authURL, state, err := h.ou.StartLogin(c.Context())
+	if err != nil {
+		return oidcError(c, err)
+	}
+
+	h.setStateCookie(c, state, time.Now().Add(entity.OIDCLoginTTL))
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Callback is where the identity provider sends the user back. It answers
+// like Login on AccountHandler.
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	state := c.Query("state")
+	cookie := c.Cookies(oidcStateCookie)
+	h.setStateCookie(c, "", time.Unix(0, 0))
+
+	if providerError := c.Query("error"); providerError != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": c.Query("error_description", providerError)})
+	}
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookie)) != 1 {
+		return oidcError(c, entity.ErrInvalidOIDCState)
+	}
+
+	account, err := h.ou.CompleteLogin(c.Context(), state, c.Query("code"))
+	if err != nil {
+		return oidcError(c, err)
+	}
+
+	return startSession(c, h.au, account)
+}
+
+func (h *OIDCHandler) setStateCookie(c *fiber.Ctx, state string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		Expires:  expires,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		// Lax, as the provider sends the user back with a top-level GET
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func oidcError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrOIDCDisabled):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidOIDCState), errors.Is(err, entity.ErrOIDCSignInFailed),
+		errors.Is(err, entity.ErrOIDCEmailNotVerified):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrOIDCAccountConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAccountLocked):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
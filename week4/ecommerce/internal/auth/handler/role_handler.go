@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/usecase"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	ru *usecase.RoleUsecase
+}
+
+func NewRoleHandler(ru *usecase.RoleUsecase) *RoleHandler {
+	return &RoleHandler{
+		ru: ru,
+	}
+}
+
+func (h *RoleHandler) GetPermissions(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.ru.GetPermissions())
+}
+
+func (h *RoleHandler) GetAllRoles(c *fiber.Ctx) error {
+	roles, err := h.ru.GetAllRoles(c.Context())
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(roles)
+}
+
+func (h *RoleHandler) AddRole(c *fiber.Ctx) error {
+	var role entity.Role
+	if err := c.BodyParser(&role); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.ru.CreateRole(c.Context(), &role); err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(role)
+}
+
+func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var role entity.Role
+	if err := c.BodyParser(&role); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	role.ID = id
+
+	updated, err := h.ru.UpdateRole(c.Context(), &role)
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
+
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.ru.DeleteRole(c.Context(), id); err != nil {
+		return roleError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func roleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrRoleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrRoleExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidRoleName), errors.Is(err, entity.ErrUnknownPermission):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrBuiltInRole), errors.Is(err, entity.ErrAdminLockout):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
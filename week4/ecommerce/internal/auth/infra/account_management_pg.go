@@ -0,0 +1,125 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (r *AccountPGRepository) UpdateProfile(ctx context.Context, account *entity.Account) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE accounts SET email = $1, updated_at = CURRENT_TIMESTAMP,
+			email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
+		WHERE id = $2
+		RETURNING user_id, email_verified_at`
+	err = tx.QueryRowContext(ctx, query, account.Email, account.ID).Scan(&account.UserID, &account.EmailVerifiedAt)
+	if err != nil {
+		return err
+	}
+
+	query = `UPDATE users SET name = $1, email = $2, version = version + 1 WHERE id = $3`
+	if err = expectOneRow(tx.ExecContext(ctx, query, account.Name, account.Email, account.UserID)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) SetLocked(ctx context.Context, id int, lockedAt *time.Time, reason string) error {
+	query := `UPDATE accounts SET locked_at = $1, locked_reason = NULLIF($2, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $3`
+
+	return expectOneRow(r.DB.ExecContext(ctx, query, lockedAt, reason, id))
+}
+
+func (r *AccountPGRepository) SetRoles(ctx context.Context, id int, roles []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_roles WHERE auth_id = $1`, id); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_roles (auth_id, role_id)
+		SELECT $1, id FROM roles WHERE role_name = ANY($2)`
+	result, err := tx.ExecContext(ctx, query, id, pq.Array(roles))
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if int(inserted) != len(roles) {
+		return entity.ErrRoleNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) Anonymize(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRowContext(ctx, `SELECT user_id FROM accounts WHERE id = $1 FOR UPDATE`, id).Scan(&userID)
+	if err != nil {
+		return err
+	}
+
+	// Sessions end with the account; the access tokens still in use are
+	// revoked before their refresh tokens go with the account row
+	queries := []string{
+		`INSERT INTO revoked_tokens (jti, expires_at)
+			SELECT access_jti, access_expires_at FROM refresh_tokens
+			WHERE account_id = $1 AND access_expires_at > CURRENT_TIMESTAMP
+			ON CONFLICT (jti) DO NOTHING`,
+		`DELETE FROM user_roles WHERE auth_id = $1`,
+		`DELETE FROM accounts WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	// Personal data that is not part of the order history
+	queries = []string{
+		`DELETE FROM wishlist_items WHERE user_id = $1`,
+		`DELETE FROM wishlist_shares WHERE user_id = $1`,
+		`DELETE FROM stock_subscriptions WHERE user_id = $1`,
+		`UPDATE users SET name = 'Deleted user', username = 'deleted-' || id, email = 'deleted-' || id || '@deleted.invalid',
+			anonymized_at = CURRENT_TIMESTAMP, deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP), version = version + 1
+		WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -62,6 +62,9 @@ func (r *AccountPGRepository) Register(ctx context.Context, account *entity.Acco
 		account.Username,
 		account.Email,
 	).Scan(&account.UserID)
+	if err != nil {
+		return err
+	}
 
 	query := `
             INSERT INTO accounts (user_id, username, email, password)
@@ -98,50 +101,108 @@ func (r *AccountPGRepository) Register(ctx context.Context, account *entity.Acco
 
 	// Add user role
 	roleQuery := `
-			INSERT INTO user_roles (user_id, SELECT id FROM roles WHERE role_name = ?)
-			VALUES (?, ?)
+			INSERT INTO user_roles (auth_id, role_id)
+			SELECT ?, id FROM roles WHERE role_name = ?
 		`
 	roleQuery = sqlx.Rebind(sqlx.DOLLAR, roleQuery)
 
 	_, err = tx.ExecContext(
 		ctx,
 		roleQuery,
-		"user",
-		account.UserID,
+		account.ID,
+		entity.RoleUser,
 	)
 
 	if err != nil {
 		return err
 	}
 
-	err = tx.Commit()
-
-	return nil
+	return tx.Commit()
 }
 
 func (r *AccountPGRepository) GetByUsername(ctx context.Context, username string) (*entity.Account, error) {
-	query := "SELECT a.id, a.user_id, a.username, a.email, a.password, a.created_at, a.updated_at, COALESCE(r.role_name, '') FROM accounts a LEFT JOIN user_roles ur ON ur.auth_id = a.id LEFT JOIN roles r ON ur.role_id = r.id WHERE a.username = ?"
+	return r.getAccount(ctx, "a.username = ?", username)
+}
+
+func (r *AccountPGRepository) GetByEmail(ctx context.Context, email string) (*entity.Account, error) {
+	return r.getAccount(ctx, "LOWER(a.email) = LOWER(?)", email)
+}
+
+func (r *AccountPGRepository) GetByID(ctx context.Context, id int) (*entity.Account, error) {
+	return r.getAccount(ctx, "a.id = ?", id)
+}
+
+const accountColumns = `a.id, a.user_id, COALESCE(u.name, ''), a.username, a.email, a.password, a.created_at, a.updated_at,
+	a.email_verified_at, a.locked_at, COALESCE(a.locked_reason, ''),
+	COALESCE(a.totp_secret, ''), COALESCE(a.totp_last_step, -1), a.totp_enabled_at,
+	ARRAY(SELECT r.role_name FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+		WHERE ur.auth_id = a.id ORDER BY r.role_name),
+	ARRAY(SELECT DISTINCT rp.permission FROM user_roles ur JOIN role_permissions rp ON rp.role_id = ur.role_id
+		WHERE ur.auth_id = a.id ORDER BY rp.permission)
+	FROM accounts a
+	JOIN users u ON u.id = a.user_id AND u.deleted_at IS NULL`
+
+func (r *AccountPGRepository) getAccount(ctx context.Context, where string, arg interface{}) (*entity.Account, error) {
+	query := "SELECT " + accountColumns + " WHERE " + where
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
-	row := r.DB.QueryRowContext(ctx, query, username)
 
+	account, err := scanAccount(r.DB.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (r *AccountPGRepository) GetAll(ctx context.Context) ([]*entity.Account, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+accountColumns+" ORDER BY a.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*entity.Account
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		account.Password = ""
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func scanAccount(row interface{ Scan(...interface{}) error }) (*entity.Account, error) {
 	account := &entity.Account{}
+	var roles, permissions pq.StringArray
 	err := row.Scan(
 		&account.ID,
 		&account.UserID,
+		&account.Name,
 		&account.Username,
 		&account.Email,
 		&account.Password,
 		&account.CreatedAt,
 		&account.UpdatedAt,
-		&account.Role,
+		&account.EmailVerifiedAt,
+		&account.LockedAt,
+		&account.LockedReason,
+		&account.TOTPSecret,
+		&account.TOTPLastStep,
+		&account.TwoFactorEnabledAt,
+		&roles,
+		&permissions,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil // User not found
-		}
 		return nil, err
 	}
 
+	account.SetRoles(roles)
+	account.Permissions = permissions
 	return account, nil
 }
@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+func (r *AccountPGRepository) UpdatePassword(ctx context.Context, accountID int, passwordHash string) error {
+	query := `UPDATE accounts SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	return expectOneRow(r.DB.ExecContext(ctx, query, passwordHash, accountID))
+}
+
+// MarkEmailVerified verifies the account's email if it is still email. It
+// reports false when the address was changed after the token was sent.
+func (r *AccountPGRepository) MarkEmailVerified(ctx context.Context, accountID int, email string) (bool, error) {
+	query := `UPDATE accounts SET email_verified_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND email = $2 AND email_verified_at IS NULL`
+
+	result, err := r.DB.ExecContext(ctx, query, accountID, email)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *AccountPGRepository) CreateAccountToken(ctx context.Context, token *entity.AccountToken) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only the most recently mailed link works
+	_, err = tx.ExecContext(ctx, `UPDATE account_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE account_id = $1 AND purpose = $2 AND used_at IS NULL`, token.AccountID, token.Purpose)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO account_tokens (account_id, purpose, token_hash, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		token.AccountID,
+		token.Purpose,
+		token.TokenHash,
+		token.Email,
+		token.ExpiresAt,
+	).Scan(&token.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) UseAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error) {
+	query := `UPDATE account_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING id, account_id, purpose, token_hash, email, expires_at, used_at`
+
+	token := &entity.AccountToken{}
+	err := r.DB.QueryRowContext(ctx, query, purpose, tokenHash, now).Scan(
+		&token.ID,
+		&token.AccountID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// RevokeAccountTokens revokes every refresh token of the account and the
+// access tokens issued with them.
+func (r *AccountPGRepository) RevokeAccountTokens(ctx context.Context, accountID int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO revoked_tokens (jti, expires_at)
+		SELECT access_jti, access_expires_at FROM refresh_tokens
+		WHERE account_id = $1 AND access_expires_at > CURRENT_TIMESTAMP
+		ON CONFLICT (jti) DO NOTHING`
+	if _, err = tx.ExecContext(ctx, query, accountID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE account_id = $1 AND revoked_at IS NULL`, accountID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// expectOneRow turns an update that matched nothing into sql.ErrNoRows.
+func expectOneRow(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
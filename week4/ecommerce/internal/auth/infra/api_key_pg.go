@@ -0,0 +1,97 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyPGRepository struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyPGRepository(db *sql.DB) *APIKeyPGRepository {
+	return &APIKeyPGRepository{DB: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, permissions, created_by, created_at,
+	expires_at, last_used_at, revoked_at FROM api_keys`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	var permissions pq.StringArray
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&permissions,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Permissions = permissions
+	return key, nil
+}
+
+func (r *APIKeyPGRepository) GetAll(ctx context.Context) ([]*entity.APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+apiKeyColumns+" ORDER BY revoked_at IS NOT NULL, name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyPGRepository) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	return scanAPIKey(r.DB.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" WHERE key_hash = $1", keyHash))
+}
+
+func (r *APIKeyPGRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	query := `INSERT INTO api_keys (name, prefix, key_hash, permissions, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	err := r.DB.QueryRowContext(ctx, query, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Permissions),
+		key.CreatedBy, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return entity.ErrAPIKeyExists
+	}
+
+	return err
+}
+
+func (r *APIKeyPGRepository) Revoke(ctx context.Context, id int, now time.Time) error {
+	err := expectOneRow(r.DB.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`, now, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrAPIKeyNotFound
+	}
+
+	return err
+}
+
+func (r *APIKeyPGRepository) SetLastUsed(ctx context.Context, id int, now time.Time) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, now, id)
+	return err
+}
@@ -0,0 +1,95 @@
+package infra
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"sync"
+	"time"
+)
+
+// LoginAttemptMemoryRepository counts logins in the process. It is
+// for a single instance; with several, each one counts on its own and an
+// attacker gets the limits once per instance.
+type LoginAttemptMemoryRepository struct {
+	mu       sync.Mutex
+	attempts map[string]entity.LoginAttempts
+}
+
+func NewLoginAttemptMemoryRepository() *LoginAttemptMemoryRepository {
+	return &LoginAttemptMemoryRepository{
+		attempts: make(map[string]entity.LoginAttempts),
+	}
+}
+
+func (r *LoginAttemptMemoryRepository) RecordAttempt(ctx context.Context, key string, now, since time.Time) (*entity.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		attempts = entity.LoginAttempts{Key: key, LastFailureAt: now}
+	}
+	before := attempts
+
+	if attempts.LastFailureAt.Before(since) {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	r.attempts[key] = attempts
+
+	return &before, nil
+}
+
+func (r *LoginAttemptMemoryRepository) Forgive(ctx context.Context, key string, at, previous time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempts, ok := r.attempts[key]; ok {
+		if attempts.Failures > 0 {
+			attempts.Failures--
+		}
+		if attempts.LastFailureAt.Equal(at) {
+			attempts.LastFailureAt = previous
+		}
+		r.attempts[key] = attempts
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptMemoryRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempts, ok := r.attempts[key]; ok {
+		attempts.LockedUntil = &until
+		attempts.Failures = 0
+		r.attempts[key] = attempts
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptMemoryRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *LoginAttemptMemoryRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, attempts := range r.attempts {
+		if attempts.LastFailureAt.Before(before) && (attempts.LockedUntil == nil || attempts.LockedUntil.Before(before)) {
+			delete(r.attempts, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,81 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+type LoginAttemptPGRepository struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptPGRepository(db *sql.DB) *LoginAttemptPGRepository {
+	return &LoginAttemptPGRepository{DB: db}
+}
+
+// RecordAttempt locks the row of key for the count, so concurrent logins on
+// different instances are counted one after the other.
+func (r *LoginAttemptPGRepository) RecordAttempt(ctx context.Context, key string, now, since time.Time) (*entity.LoginAttempts, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 0, $2)
+		ON CONFLICT (key) DO NOTHING`, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT key, failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1 FOR UPDATE`
+	attempts := &entity.LoginAttempts{}
+	err = tx.QueryRowContext(ctx, query, key).
+		Scan(&attempts.Key, &attempts.Failures, &attempts.LastFailureAt, &attempts.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE login_attempts SET
+			failures = CASE WHEN last_failure_at < $3 THEN 1 ELSE failures + 1 END,
+			last_failure_at = $2
+		WHERE key = $1`
+	if _, err = tx.ExecContext(ctx, query, key, now, since); err != nil {
+		return nil, err
+	}
+
+	return attempts, tx.Commit()
+}
+
+func (r *LoginAttemptPGRepository) Forgive(ctx context.Context, key string, at, previous time.Time) error {
+	query := `UPDATE login_attempts SET
+			failures = GREATEST(failures - 1, 0),
+			last_failure_at = CASE WHEN last_failure_at = $2 THEN $3 ELSE last_failure_at END
+		WHERE key = $1`
+
+	_, err := r.DB.ExecContext(ctx, query, key, at, previous)
+	return err
+}
+
+func (r *LoginAttemptPGRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE login_attempts SET locked_until = $2, failures = 0 WHERE key = $1`, key, until)
+	return err
+}
+
+func (r *LoginAttemptPGRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
+
+func (r *LoginAttemptPGRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`
+
+	result, err := r.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
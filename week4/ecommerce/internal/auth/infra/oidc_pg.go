@@ -0,0 +1,66 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+type OIDCPGRepository struct {
+	DB *sql.DB
+}
+
+func NewOIDCPGRepository(db *sql.DB) *OIDCPGRepository {
+	return &OIDCPGRepository{DB: db}
+}
+
+func (r *OIDCPGRepository) CreateLogin(ctx context.Context, login *entity.OIDCLogin) error {
+	query := `INSERT INTO oidc_logins (state_hash, nonce, code_verifier, expires_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := r.DB.ExecContext(ctx, query, login.StateHash, login.Nonce, login.CodeVerifier, login.ExpiresAt)
+	return err
+}
+
+func (r *OIDCPGRepository) UseLogin(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCLogin, error) {
+	query := `DELETE FROM oidc_logins WHERE state_hash = $1 AND expires_at > $2
+		RETURNING state_hash, nonce, code_verifier, expires_at`
+
+	login := &entity.OIDCLogin{}
+	err := r.DB.QueryRowContext(ctx, query, stateHash, now).Scan(
+		&login.StateHash,
+		&login.Nonce,
+		&login.CodeVerifier,
+		&login.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return login, nil
+}
+
+func (r *OIDCPGRepository) DeleteExpiredLogins(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM oidc_logins WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *OIDCPGRepository) GetLinkedAccountID(ctx context.Context, issuer, subject string) (int, error) {
+	var accountID int
+	err := r.DB.QueryRowContext(ctx, `SELECT account_id FROM account_identities WHERE issuer = $1 AND subject = $2`,
+		issuer, subject).Scan(&accountID)
+
+	return accountID, err
+}
+
+func (r *OIDCPGRepository) LinkIdentity(ctx context.Context, accountID int, issuer, subject string) error {
+	query := `INSERT INTO account_identities (account_id, issuer, subject) VALUES ($1, $2, $3)
+		ON CONFLICT (issuer, subject) DO NOTHING`
+
+	_, err := r.DB.ExecContext(ctx, query, accountID, issuer, subject)
+	return err
+}
@@ -0,0 +1,143 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type RolePGRepository struct {
+	DB *sql.DB
+}
+
+func NewRolePGRepository(db *sql.DB) *RolePGRepository {
+	return &RolePGRepository{DB: db}
+}
+
+const roleColumns = `r.id, r.role_name, COALESCE(r.description, ''),
+	ARRAY(SELECT permission FROM role_permissions WHERE role_id = r.id ORDER BY permission),
+	(SELECT COUNT(*) FROM user_roles WHERE role_id = r.id)
+	FROM roles r`
+
+func scanRole(row interface{ Scan(...interface{}) error }) (*entity.Role, error) {
+	role := &entity.Role{}
+	var permissions pq.StringArray
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &permissions, &role.Accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = permissions
+	return role, nil
+}
+
+func (r *RolePGRepository) GetAll(ctx context.Context) ([]*entity.Role, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+roleColumns+" ORDER BY r.role_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*entity.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *RolePGRepository) GetByID(ctx context.Context, id int) (*entity.Role, error) {
+	role, err := scanRole(r.DB.QueryRowContext(ctx, "SELECT "+roleColumns+" WHERE r.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrRoleNotFound
+	}
+
+	return role, err
+}
+
+func (r *RolePGRepository) Create(ctx context.Context, role *entity.Role) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO roles (role_name, description) VALUES ($1, NULLIF($2, '')) RETURNING id`,
+		role.Name, role.Description).Scan(&role.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entity.ErrRoleExists
+		}
+		return err
+	}
+
+	if err = setPermissions(ctx, tx, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RolePGRepository) Update(ctx context.Context, role *entity.Role) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = expectOneRow(tx.ExecContext(ctx, `UPDATE roles SET description = NULLIF($1, '') WHERE id = $2`, role.Description, role.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = setPermissions(ctx, tx, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func setPermissions(ctx context.Context, tx *sql.Tx, role *entity.Role) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, UNNEST($2::TEXT[])
+		ON CONFLICT DO NOTHING`
+	_, err := tx.ExecContext(ctx, query, role.ID, pq.Array(role.Permissions))
+	return err
+}
+
+func (r *RolePGRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_roles WHERE role_id = $1`, id); err != nil {
+		return err
+	}
+
+	err = expectOneRow(tx.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/pkg/utils"
+	"time"
+)
+
+type SigningKeyPGRepository struct {
+	DB *sql.DB
+}
+
+func NewSigningKeyPGRepository(db *sql.DB) *SigningKeyPGRepository {
+	return &SigningKeyPGRepository{DB: db}
+}
+
+func (r *SigningKeyPGRepository) GetAll(ctx context.Context) ([]*utils.SigningKey, error) {
+	query := `SELECT id, algorithm, private_key, active_at FROM signing_keys ORDER BY active_at`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*utils.SigningKey
+	for rows.Next() {
+		key := &utils.SigningKey{}
+		var private string
+		if err := rows.Scan(&key.ID, &key.Algorithm, &private, &key.ActiveAt); err != nil {
+			return nil, err
+		}
+
+		key.Private, err = utils.ParsePrivateKey(private)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *SigningKeyPGRepository) Create(ctx context.Context, key *utils.SigningKey, newest time.Time) (bool, error) {
+	private, err := key.MarshalPrivateKey()
+	if err != nil {
+		return false, err
+	}
+
+	query := `INSERT INTO signing_keys (id, algorithm, private_key, active_at)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (SELECT 1 FROM signing_keys WHERE active_at > $5)`
+
+	result, err := r.DB.ExecContext(ctx, query, key.ID, key.Algorithm, private, key.ActiveAt, newest)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *SigningKeyPGRepository) DeleteRetired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM signing_keys k
+		WHERE EXISTS (
+			SELECT 1 FROM signing_keys n
+			WHERE n.active_at > k.active_at AND n.active_at < $1
+		)`
+
+	result, err := r.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+func (r *AccountPGRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (account_id, family_id, token_hash, access_jti, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	return r.DB.QueryRowContext(
+		ctx,
+		query,
+		token.AccountID,
+		token.FamilyID,
+		token.TokenHash,
+		token.AccessJTI,
+		token.AccessExpiresAt,
+		token.ExpiresAt,
+	).Scan(&token.ID)
+}
+
+func (r *AccountPGRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `SELECT t.id, t.account_id, a.username, t.family_id, t.token_hash, t.access_jti, t.access_expires_at,
+			t.expires_at, t.used_at, t.revoked_at
+		FROM refresh_tokens t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.token_hash = $1`
+
+	token := &entity.RefreshToken{}
+	err := r.DB.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.AccountID,
+		&token.Username,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.AccessJTI,
+		&token.AccessExpiresAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// UseRefreshToken marks the token used. It reports false when the token was
+// already used or revoked, which for two concurrent refreshes means only
+// one of them wins.
+func (r *AccountPGRepository) UseRefreshToken(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// RevokeTokenFamily revokes every refresh token of a login together with
+// the access tokens issued with them that have not expired yet.
+func (r *AccountPGRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO revoked_tokens (jti, expires_at)
+		SELECT access_jti, access_expires_at FROM refresh_tokens
+		WHERE family_id = $1 AND access_expires_at > CURRENT_TIMESTAMP
+		ON CONFLICT (jti) DO NOTHING`
+	if _, err = tx.ExecContext(ctx, query, familyID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+
+	_, err := r.DB.ExecContext(ctx, query, jti, expiresAt)
+	return err
+}
+
+func (r *AccountPGRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+// DeleteExpiredTokens removes revocations, refresh tokens and mailed account
+// tokens that expired before the cutoff, as they can no longer be presented.
+func (r *AccountPGRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	for _, query := range []string{
+		`DELETE FROM revoked_tokens WHERE expires_at < $1`,
+		`DELETE FROM refresh_tokens WHERE expires_at < $1`,
+		`DELETE FROM account_tokens WHERE expires_at < $1`,
+	} {
+		result, err := tx.ExecContext(ctx, query, before)
+		if err != nil {
+			return 0, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += affected
+	}
+
+	return deleted, tx.Commit()
+}
@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"errors"
+	"time"
+)
+
+func (r *AccountPGRepository) GetAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error) {
+	query := `SELECT id, account_id, purpose, token_hash, email, expires_at, used_at FROM account_tokens
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > $3`
+
+	token := &entity.AccountToken{}
+	err := r.DB.QueryRowContext(ctx, query, purpose, tokenHash, now).Scan(
+		&token.ID,
+		&token.AccountID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// SetTOTPSecret replaces the secret of an enrolment that is not confirmed
+// yet. An account with two-factor authentication enabled gives sql.ErrNoRows.
+func (r *AccountPGRepository) SetTOTPSecret(ctx context.Context, accountID int, secret string) error {
+	query := `UPDATE accounts SET totp_secret = $1, totp_last_step = NULL
+		WHERE id = $2 AND totp_enabled_at IS NULL`
+
+	return expectOneRow(r.DB.ExecContext(ctx, query, secret, accountID))
+}
+
+func (r *AccountPGRepository) EnableTOTP(ctx context.Context, accountID int, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE accounts SET totp_enabled_at = CURRENT_TIMESTAMP, totp_last_step = $1
+		WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`
+	if err = expectOneRow(tx.ExecContext(ctx, query, step, accountID)); err != nil {
+		return err
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, accountID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) DisableTOTP(ctx context.Context, accountID int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE accounts SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = $1`
+	if err = expectOneRow(tx.ExecContext(ctx, query, accountID)); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep only moves forward, so of two logins with the same code one
+// fails even when they run at the same time.
+func (r *AccountPGRepository) UseTOTPStep(ctx context.Context, accountID int, step int64) (bool, error) {
+	query := `UPDATE accounts SET totp_last_step = $1
+		WHERE id = $2 AND totp_enabled_at IS NOT NULL AND COALESCE(totp_last_step, -1) < $1`
+
+	err := expectOneRow(r.DB.ExecContext(ctx, query, step, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (r *AccountPGRepository) ReplaceRecoveryCodes(ctx context.Context, accountID int, codeHashes []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = replaceRecoveryCodes(ctx, tx, accountID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AccountPGRepository) UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error) {
+	query := `UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT id FROM recovery_codes WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)
+		AND used_at IS NULL`
+
+	err := expectOneRow(r.DB.ExecContext(ctx, query, accountID, codeHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, accountID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (account_id, code_hash) VALUES ($1, $2)`, accountID, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	entity "ecommerce/internal/auth/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,151 @@ func (m *MockIAccountRepository) EXPECT() *MockIAccountRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Anonymize mocks base method.
+func (m *MockIAccountRepository) Anonymize(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockIAccountRepositoryMockRecorder) Anonymize(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockIAccountRepository)(nil).Anonymize), ctx, id)
+}
+
+// CreateAccountToken mocks base method.
+func (m *MockIAccountRepository) CreateAccountToken(ctx context.Context, token *entity.AccountToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccountToken indicates an expected call of CreateAccountToken.
+func (mr *MockIAccountRepositoryMockRecorder) CreateAccountToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountToken", reflect.TypeOf((*MockIAccountRepository)(nil).CreateAccountToken), ctx, token)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockIAccountRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockIAccountRepositoryMockRecorder) CreateRefreshToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockIAccountRepository)(nil).CreateRefreshToken), ctx, token)
+}
+
+// DeleteExpiredTokens mocks base method.
+func (m *MockIAccountRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokens", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTokens indicates an expected call of DeleteExpiredTokens.
+func (mr *MockIAccountRepositoryMockRecorder) DeleteExpiredTokens(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockIAccountRepository)(nil).DeleteExpiredTokens), ctx, before)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockIAccountRepository) DisableTOTP(ctx context.Context, accountID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockIAccountRepositoryMockRecorder) DisableTOTP(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockIAccountRepository)(nil).DisableTOTP), ctx, accountID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockIAccountRepository) EnableTOTP(ctx context.Context, accountID int, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, accountID, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockIAccountRepositoryMockRecorder) EnableTOTP(ctx, accountID, step, recoveryCodeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockIAccountRepository)(nil).EnableTOTP), ctx, accountID, step, recoveryCodeHashes)
+}
+
+// GetAccountToken mocks base method.
+func (m *MockIAccountRepository) GetAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountToken", ctx, purpose, tokenHash, now)
+	ret0, _ := ret[0].(*entity.AccountToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountToken indicates an expected call of GetAccountToken.
+func (mr *MockIAccountRepositoryMockRecorder) GetAccountToken(ctx, purpose, tokenHash, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountToken", reflect.TypeOf((*MockIAccountRepository)(nil).GetAccountToken), ctx, purpose, tokenHash, now)
+}
+
+// GetAll mocks base method.
+func (m *MockIAccountRepository) GetAll(ctx context.Context) ([]*entity.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entity.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockIAccountRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIAccountRepository)(nil).GetAll), ctx)
+}
+
+// GetByEmail mocks base method.
+func (m *MockIAccountRepository) GetByEmail(ctx context.Context, email string) (*entity.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*entity.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockIAccountRepositoryMockRecorder) GetByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockIAccountRepository)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockIAccountRepository) GetByID(ctx context.Context, id int) (*entity.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIAccountRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIAccountRepository)(nil).GetByID), ctx, id)
+}
+
 // GetByUsername mocks base method.
 func (m *MockIAccountRepository) GetByUsername(ctx context.Context, username string) (*entity.Account, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +201,36 @@ func (mr *MockIAccountRepositoryMockRecorder) GetByUsername(ctx, username any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockIAccountRepository)(nil).GetByUsername), ctx, username)
 }
 
+// GetRefreshToken mocks base method.
+func (m *MockIAccountRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockIAccountRepositoryMockRecorder) GetRefreshToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockIAccountRepository)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// IsAccessTokenRevoked mocks base method.
+func (m *MockIAccountRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockIAccountRepositoryMockRecorder) IsAccessTokenRevoked(ctx, jti any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockIAccountRepository)(nil).IsAccessTokenRevoked), ctx, jti)
+}
+
 // Login mocks base method.
 func (m *MockIAccountRepository) Login(ctx context.Context, account *entity.Account) (*entity.Account, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +246,21 @@ func (mr *MockIAccountRepositoryMockRecorder) Login(ctx, account any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockIAccountRepository)(nil).Login), ctx, account)
 }
 
+// MarkEmailVerified mocks base method.
+func (m *MockIAccountRepository) MarkEmailVerified(ctx context.Context, accountID int, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, accountID, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockIAccountRepositoryMockRecorder) MarkEmailVerified(ctx, accountID, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockIAccountRepository)(nil).MarkEmailVerified), ctx, accountID, email)
+}
+
 // Register mocks base method.
 func (m *MockIAccountRepository) Register(ctx context.Context, account *entity.Account) error {
 	m.ctrl.T.Helper()
@@ -83,3 +274,189 @@ func (mr *MockIAccountRepositoryMockRecorder) Register(ctx, account any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockIAccountRepository)(nil).Register), ctx, account)
 }
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockIAccountRepository) ReplaceRecoveryCodes(ctx context.Context, accountID int, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, accountID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockIAccountRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, accountID, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockIAccountRepository)(nil).ReplaceRecoveryCodes), ctx, accountID, codeHashes)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockIAccountRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, jti, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockIAccountRepositoryMockRecorder) RevokeAccessToken(ctx, jti, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockIAccountRepository)(nil).RevokeAccessToken), ctx, jti, expiresAt)
+}
+
+// RevokeAccountTokens mocks base method.
+func (m *MockIAccountRepository) RevokeAccountTokens(ctx context.Context, accountID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccountTokens", ctx, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccountTokens indicates an expected call of RevokeAccountTokens.
+func (mr *MockIAccountRepositoryMockRecorder) RevokeAccountTokens(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccountTokens", reflect.TypeOf((*MockIAccountRepository)(nil).RevokeAccountTokens), ctx, accountID)
+}
+
+// RevokeTokenFamily mocks base method.
+func (m *MockIAccountRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokenFamily indicates an expected call of RevokeTokenFamily.
+func (mr *MockIAccountRepositoryMockRecorder) RevokeTokenFamily(ctx, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokenFamily", reflect.TypeOf((*MockIAccountRepository)(nil).RevokeTokenFamily), ctx, familyID)
+}
+
+// SetLocked mocks base method.
+func (m *MockIAccountRepository) SetLocked(ctx context.Context, id int, lockedAt *time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocked", ctx, id, lockedAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocked indicates an expected call of SetLocked.
+func (mr *MockIAccountRepositoryMockRecorder) SetLocked(ctx, id, lockedAt, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocked", reflect.TypeOf((*MockIAccountRepository)(nil).SetLocked), ctx, id, lockedAt, reason)
+}
+
+// SetRoles mocks base method.
+func (m *MockIAccountRepository) SetRoles(ctx context.Context, id int, roles []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoles", ctx, id, roles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoles indicates an expected call of SetRoles.
+func (mr *MockIAccountRepositoryMockRecorder) SetRoles(ctx, id, roles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoles", reflect.TypeOf((*MockIAccountRepository)(nil).SetRoles), ctx, id, roles)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockIAccountRepository) SetTOTPSecret(ctx context.Context, accountID int, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, accountID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockIAccountRepositoryMockRecorder) SetTOTPSecret(ctx, accountID, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockIAccountRepository)(nil).SetTOTPSecret), ctx, accountID, secret)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockIAccountRepository) UpdatePassword(ctx context.Context, accountID int, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, accountID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockIAccountRepositoryMockRecorder) UpdatePassword(ctx, accountID, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockIAccountRepository)(nil).UpdatePassword), ctx, accountID, passwordHash)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockIAccountRepository) UpdateProfile(ctx context.Context, account *entity.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockIAccountRepositoryMockRecorder) UpdateProfile(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockIAccountRepository)(nil).UpdateProfile), ctx, account)
+}
+
+// UseAccountToken mocks base method.
+func (m *MockIAccountRepository) UseAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAccountToken", ctx, purpose, tokenHash, now)
+	ret0, _ := ret[0].(*entity.AccountToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAccountToken indicates an expected call of UseAccountToken.
+func (mr *MockIAccountRepositoryMockRecorder) UseAccountToken(ctx, purpose, tokenHash, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAccountToken", reflect.TypeOf((*MockIAccountRepository)(nil).UseAccountToken), ctx, purpose, tokenHash, now)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockIAccountRepository) UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, accountID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockIAccountRepositoryMockRecorder) UseRecoveryCode(ctx, accountID, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockIAccountRepository)(nil).UseRecoveryCode), ctx, accountID, codeHash)
+}
+
+// UseRefreshToken mocks base method.
+func (m *MockIAccountRepository) UseRefreshToken(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRefreshToken", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRefreshToken indicates an expected call of UseRefreshToken.
+func (mr *MockIAccountRepositoryMockRecorder) UseRefreshToken(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRefreshToken", reflect.TypeOf((*MockIAccountRepository)(nil).UseRefreshToken), ctx, id)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockIAccountRepository) UseTOTPStep(ctx context.Context, accountID int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, accountID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockIAccountRepositoryMockRecorder) UseTOTPStep(ctx, accountID, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockIAccountRepository)(nil).UseTOTPStep), ctx, accountID, step)
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/auth/repository/login_attempt_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/auth/repository/login_attempt_repository.go -destination=internal/auth/mocks/mock_login_attempt_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/auth/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockILoginAttemptRepository is a mock of ILoginAttemptRepository interface.
+type MockILoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockILoginAttemptRepositoryMockRecorder
+}
+
+// MockILoginAttemptRepositoryMockRecorder is the mock recorder for MockILoginAttemptRepository.
+type MockILoginAttemptRepositoryMockRecorder struct {
+	mock *MockILoginAttemptRepository
+}
+
+// NewMockILoginAttemptRepository creates a new mock instance.
+func NewMockILoginAttemptRepository(ctrl *gomock.Controller) *MockILoginAttemptRepository {
+	mock := &MockILoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockILoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILoginAttemptRepository) EXPECT() *MockILoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockILoginAttemptRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockILoginAttemptRepositoryMockRecorder) DeleteExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockILoginAttemptRepository)(nil).DeleteExpired), ctx, before)
+}
+
+// Forgive mocks base method.
+func (m *MockILoginAttemptRepository) Forgive(ctx context.Context, key string, at, previous time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Forgive", ctx, key, at, previous)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Forgive indicates an expected call of Forgive.
+func (mr *MockILoginAttemptRepositoryMockRecorder) Forgive(ctx, key, at, previous any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forgive", reflect.TypeOf((*MockILoginAttemptRepository)(nil).Forgive), ctx, key, at, previous)
+}
+
+// Lock mocks base method.
+func (m *MockILoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockILoginAttemptRepositoryMockRecorder) Lock(ctx, key, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockILoginAttemptRepository)(nil).Lock), ctx, key, until)
+}
+
+// RecordAttempt mocks base method.
+func (m *MockILoginAttemptRepository) RecordAttempt(ctx context.Context, key string, now, since time.Time) (*entity.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", ctx, key, now, since)
+	ret0, _ := ret[0].(*entity.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *MockILoginAttemptRepositoryMockRecorder) RecordAttempt(ctx, key, now, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockILoginAttemptRepository)(nil).RecordAttempt), ctx, key, now, since)
+}
+
+// Reset mocks base method.
+func (m *MockILoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockILoginAttemptRepositoryMockRecorder) Reset(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockILoginAttemptRepository)(nil).Reset), ctx, key)
+}
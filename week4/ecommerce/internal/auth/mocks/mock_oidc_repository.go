@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/auth/repository/oidc_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/auth/repository/oidc_repository.go -destination=internal/auth/mocks/mock_oidc_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/auth/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIOIDCRepository is a mock of IOIDCRepository interface.
+type MockIOIDCRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOIDCRepositoryMockRecorder
+}
+
+// MockIOIDCRepositoryMockRecorder is the mock recorder for MockIOIDCRepository.
+type MockIOIDCRepositoryMockRecorder struct {
+	mock *MockIOIDCRepository
+}
+
+// NewMockIOIDCRepository creates a new mock instance.
+func NewMockIOIDCRepository(ctrl *gomock.Controller) *MockIOIDCRepository {
+	mock := &MockIOIDCRepository{ctrl: ctrl}
+	mock.recorder = &MockIOIDCRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOIDCRepository) EXPECT() *MockIOIDCRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateLogin mocks base method.
+func (m *MockIOIDCRepository) CreateLogin(ctx context.Context, login *entity.OIDCLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLogin", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLogin indicates an expected call of CreateLogin.
+func (mr *MockIOIDCRepositoryMockRecorder) CreateLogin(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLogin", reflect.TypeOf((*MockIOIDCRepository)(nil).CreateLogin), ctx, login)
+}
+
+// DeleteExpiredLogins mocks base method.
+func (m *MockIOIDCRepository) DeleteExpiredLogins(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLogins", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredLogins indicates an expected call of DeleteExpiredLogins.
+func (mr *MockIOIDCRepositoryMockRecorder) DeleteExpiredLogins(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLogins", reflect.TypeOf((*MockIOIDCRepository)(nil).DeleteExpiredLogins), ctx, now)
+}
+
+// GetLinkedAccountID mocks base method.
+func (m *MockIOIDCRepository) GetLinkedAccountID(ctx context.Context, issuer, subject string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkedAccountID", ctx, issuer, subject)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkedAccountID indicates an expected call of GetLinkedAccountID.
+func (mr *MockIOIDCRepositoryMockRecorder) GetLinkedAccountID(ctx, issuer, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkedAccountID", reflect.TypeOf((*MockIOIDCRepository)(nil).GetLinkedAccountID), ctx, issuer, subject)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockIOIDCRepository) LinkIdentity(ctx context.Context, accountID int, issuer, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", ctx, accountID, issuer, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockIOIDCRepositoryMockRecorder) LinkIdentity(ctx, accountID, issuer, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockIOIDCRepository)(nil).LinkIdentity), ctx, accountID, issuer, subject)
+}
+
+// UseLogin mocks base method.
+func (m *MockIOIDCRepository) UseLogin(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLogin", ctx, stateHash, now)
+	ret0, _ := ret[0].(*entity.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLogin indicates an expected call of UseLogin.
+func (mr *MockIOIDCRepositoryMockRecorder) UseLogin(ctx, stateHash, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLogin", reflect.TypeOf((*MockIOIDCRepository)(nil).UseLogin), ctx, stateHash, now)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/auth/repository/signing_key_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/auth/repository/signing_key_repository.go -destination=internal/auth/mocks/mock_signing_key_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	utils "ecommerce/pkg/utils"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockISigningKeyRepository is a mock of ISigningKeyRepository interface.
+type MockISigningKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockISigningKeyRepositoryMockRecorder
+}
+
+// MockISigningKeyRepositoryMockRecorder is the mock recorder for MockISigningKeyRepository.
+type MockISigningKeyRepositoryMockRecorder struct {
+	mock *MockISigningKeyRepository
+}
+
+// NewMockISigningKeyRepository creates a new mock instance.
+func NewMockISigningKeyRepository(ctrl *gomock.Controller) *MockISigningKeyRepository {
+	mock := &MockISigningKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockISigningKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISigningKeyRepository) EXPECT() *MockISigningKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockISigningKeyRepository) Create(ctx context.Context, key *utils.SigningKey, newest time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key, newest)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockISigningKeyRepositoryMockRecorder) Create(ctx, key, newest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockISigningKeyRepository)(nil).Create), ctx, key, newest)
+}
+
+// DeleteRetired mocks base method.
+func (m *MockISigningKeyRepository) DeleteRetired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRetired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRetired indicates an expected call of DeleteRetired.
+func (mr *MockISigningKeyRepositoryMockRecorder) DeleteRetired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRetired", reflect.TypeOf((*MockISigningKeyRepository)(nil).DeleteRetired), ctx, before)
+}
+
+// GetAll mocks base method.
+func (m *MockISigningKeyRepository) GetAll(ctx context.Context) ([]*utils.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*utils.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockISigningKeyRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockISigningKeyRepository)(nil).GetAll), ctx)
+}
@@ -3,10 +3,57 @@ package repository
 import (
 	"context"
 	"ecommerce/internal/auth/entity"
+	"time"
 )
 
 type IAccountRepository interface {
 	Login(ctx context.Context, account *entity.Account) (*entity.Account, error)
 	Register(ctx context.Context, account *entity.Account) error
 	GetByUsername(ctx context.Context, username string) (*entity.Account, error)
+	GetByEmail(ctx context.Context, email string) (*entity.Account, error)
+	GetByID(ctx context.Context, id int) (*entity.Account, error)
+	GetAll(ctx context.Context) ([]*entity.Account, error)
+	// UpdateProfile saves the name and email of the account and its user.
+	// A changed email has to be verified again.
+	UpdateProfile(ctx context.Context, account *entity.Account) error
+	SetLocked(ctx context.Context, id int, lockedAt *time.Time, reason string) error
+	// SetRoles replaces the roles of the account. It returns
+	// entity.ErrRoleNotFound when one of them does not exist.
+	SetRoles(ctx context.Context, id int, roles []string) error
+	// Anonymize deletes the account and strips the personal data from its
+	// user, keeping the user row for their orders.
+	Anonymize(ctx context.Context, id int) error
+	UpdatePassword(ctx context.Context, accountID int, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, accountID int, email string) (bool, error)
+	// CreateAccountToken stores token and retires the account's earlier
+	// unused tokens for the same purpose.
+	CreateAccountToken(ctx context.Context, token *entity.AccountToken) error
+	// UseAccountToken marks an unused token unexpired at now as used and
+	// returns it, or sql.ErrNoRows.
+	UseAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error)
+	// GetAccountToken returns an unused token unexpired at now without using
+	// it, or sql.ErrNoRows.
+	GetAccountToken(ctx context.Context, purpose, tokenHash string, now time.Time) (*entity.AccountToken, error)
+	// RevokeAccountTokens signs the account out of every session.
+	RevokeAccountTokens(ctx context.Context, accountID int) error
+	SetTOTPSecret(ctx context.Context, accountID int, secret string) error
+	// EnableTOTP confirms the enrolment, with step as the code already used,
+	// and replaces the recovery codes.
+	EnableTOTP(ctx context.Context, accountID int, step int64, recoveryCodeHashes []string) error
+	// DisableTOTP removes the secret and the recovery codes.
+	DisableTOTP(ctx context.Context, accountID int) error
+	// UseTOTPStep records that the code of step was used. It reports false
+	// when that step or a later one already was.
+	UseTOTPStep(ctx context.Context, accountID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, accountID int, codeHashes []string) error
+	// UseRecoveryCode marks an unused code as used, reporting false when
+	// there is none.
+	UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error)
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id int) (bool, error)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+type IAPIKeyRepository interface {
+	GetAll(ctx context.Context) ([]*entity.APIKey, error)
+	// GetByHash gives sql.ErrNoRows for an unknown key.
+	GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	// Create gives entity.ErrAPIKeyExists when an unrevoked key has the name.
+	Create(ctx context.Context, key *entity.APIKey) error
+	// Revoke gives entity.ErrAPIKeyNotFound for an unknown key. Revoking a
+	// revoked key keeps when it was first revoked.
+	Revoke(ctx context.Context, id int, now time.Time) error
+	SetLastUsed(ctx context.Context, id int, now time.Time) error
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+// ILoginAttemptRepository counts failed logins per key. It has to be shared
+// by every instance of the application for the limits to hold.
+type ILoginAttemptRepository interface {
+	// RecordAttempt counts a login at now, before its password is checked,
+	// and returns the attempts as they were before it. Concurrent logins
+	// are counted one after the other, so each sees the ones before it.
+	// Failures before since are forgotten first.
+	RecordAttempt(ctx context.Context, key string, now, since time.Time) (*entity.LoginAttempts, error)
+	// Forgive takes back the attempt counted at for a login that succeeded.
+	// Its time is set back to previous unless another attempt came since.
+	Forgive(ctx context.Context, key string, at, previous time.Time) error
+	// Lock locks key out until then and starts its failures over, so it
+	// gets MaxFailures again once the lockout ends.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets the failures of key and lifts its lockout.
+	Reset(ctx context.Context, key string) error
+	// DeleteExpired drops keys without failures since before and without a
+	// lockout lasting past it.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"time"
+)
+
+type IOIDCRepository interface {
+	CreateLogin(ctx context.Context, login *entity.OIDCLogin) error
+	// UseLogin deletes and returns the sign-in with stateHash unexpired at
+	// now, or gives sql.ErrNoRows.
+	UseLogin(ctx context.Context, stateHash string, now time.Time) (*entity.OIDCLogin, error)
+	DeleteExpiredLogins(ctx context.Context, now time.Time) (int64, error)
+	// GetLinkedAccountID gives sql.ErrNoRows when the subject is not linked
+	// to an account.
+	GetLinkedAccountID(ctx context.Context, issuer, subject string) (int, error)
+	LinkIdentity(ctx context.Context, accountID int, issuer, subject string) error
+}
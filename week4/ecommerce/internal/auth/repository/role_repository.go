@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+)
+
+type IRoleRepository interface {
+	GetAll(ctx context.Context) ([]*entity.Role, error)
+	GetByID(ctx context.Context, id int) (*entity.Role, error)
+	Create(ctx context.Context, role *entity.Role) error
+	// Update saves the description and replaces the permissions of role.
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/pkg/utils"
+	"time"
+)
+
+type ISigningKeyRepository interface {
+	GetAll(ctx context.Context) ([]*utils.SigningKey, error)
+	// Create stores key unless a key activating after newest exists, so
+	// instances rotating at the same time add only one key. It reports
+	// whether the key was stored.
+	Create(ctx context.Context, key *utils.SigningKey, newest time.Time) (bool, error)
+	// DeleteRetired deletes keys replaced by a key active before before.
+	DeleteRetired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"errors"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// GetAccount returns the account of username without its password hash.
+func (u *AccountUsecase) GetAccount(ctx context.Context, username string) (*entity.Account, error) {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, entity.ErrAccountNotFound
+	}
+
+	account.Password = ""
+	return account, nil
+}
+
+// UpdateAccount changes the name and email of username's account. A new
+// email is unverified until the link sent to it is used.
+func (u *AccountUsecase) UpdateAccount(ctx context.Context, username string, update *entity.AccountUpdate) (*entity.Account, error) {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, entity.ErrAccountNotFound
+	}
+
+	email := strings.TrimSpace(update.Email)
+	emailChanged := email != "" && !strings.EqualFold(email, account.Email)
+	if emailChanged {
+		existing, err := u.repo.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != account.ID {
+			return nil, ErrEmailExists
+		}
+		account.Email = email
+	}
+	if name := strings.TrimSpace(update.Name); name != "" {
+		account.Name = name
+	}
+
+	if err = u.repo.UpdateProfile(ctx, account); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err = u.sendVerification(ctx, account); err != nil {
+			log.Printf("failed to send verification email to %s: %v", account.Username, err)
+		}
+	}
+
+	account.Password = ""
+	return account, nil
+}
+
+// DeleteAccount deletes username's account after checking the password and,
+// with two-factor authentication enabled, a code from the app or a recovery
+// code. The user behind it is anonymised rather than deleted so their orders
+// stay intact.
+func (u *AccountUsecase) DeleteAccount(ctx context.Context, username, password, code string) error {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return entity.ErrAccountNotFound
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
+		return entity.ErrWrongPassword
+	}
+
+	if account.TwoFactorEnabled() {
+		ok, err := u.checkSecondFactor(ctx, account, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return entity.ErrInvalidTwoFactorCode
+		}
+	}
+
+	return u.repo.Anonymize(ctx, account.ID)
+}
+
+func (u *AccountUsecase) GetAllAccounts(ctx context.Context) ([]*entity.Account, error) {
+	return u.repo.GetAll(ctx)
+}
+
+// LockAccount stops the account from logging in and signs it out of every
+// session. actor is the admin doing it, who cannot lock themselves out.
+func (u *AccountUsecase) LockAccount(ctx context.Context, actor string, id int, reason string) error {
+	account, err := u.otherAccount(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	now := u.now()
+	if err = u.repo.SetLocked(ctx, account.ID, &now, reason); err != nil {
+		return err
+	}
+
+	return u.repo.RevokeAccountTokens(ctx, account.ID)
+}
+
+// UnlockAccount lifts an admin's lock and a lockout after failed logins.
+func (u *AccountUsecase) UnlockAccount(ctx context.Context, id int) error {
+	account, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return entity.ErrAccountNotFound
+	}
+
+	err = u.repo.SetLocked(ctx, id, nil, "")
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.attempts.Reset(ctx, accountLoginKey(account.Username))
+}
+
+// SetRoles replaces the roles of an account. Tokens carry the permissions
+// of the roles, so the account is signed out to pick up the new ones on its
+// next login.
+func (u *AccountUsecase) SetRoles(ctx context.Context, actor string, id int, roles []string) error {
+	roles = uniqueRoles(roles)
+	if len(roles) == 0 {
+		return entity.ErrNoRoles
+	}
+
+	account, err := u.otherAccount(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err = u.repo.SetRoles(ctx, account.ID, roles); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrAccountNotFound
+		}
+		return err
+	}
+
+	return u.repo.RevokeAccountTokens(ctx, account.ID)
+}
+
+func uniqueRoles(roles []string) []string {
+	seen := make(map[string]bool, len(roles))
+	unique := make([]string, 0, len(roles))
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		if role != "" && !seen[role] {
+			seen[role] = true
+			unique = append(unique, role)
+		}
+	}
+
+	return unique
+}
+
+// otherAccount loads an account an admin is about to restrict, refusing
+// their own.
+func (u *AccountUsecase) otherAccount(ctx context.Context, actor string, id int) (*entity.Account, error) {
+	account, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, entity.ErrAccountNotFound
+	}
+	if account.Username == actor {
+		return nil, entity.ErrOwnAccount
+	}
+
+	return account, nil
+}
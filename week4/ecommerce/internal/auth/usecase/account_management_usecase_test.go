@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/totp"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (suite *AccountUsecaseTestSuite) TestUpdateAccount() {
+	verifiedAt := time.Now()
+	account := func() *entity.Account {
+		return &entity.Account{ID: 3, Name: "Test", Username: "testuser", Email: "test@example.com", Password: "hash", EmailVerifiedAt: &verifiedAt}
+	}
+
+	suite.Run("Name only keeps the email verified", func() {
+		suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account(), nil)
+		suite.mockRepo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Return(nil)
+
+		updated, err := suite.accountUsecase.UpdateAccount(context.Background(), "testuser", &entity.AccountUpdate{Name: "New Name"})
+		suite.NoError(err)
+		suite.Equal("New Name", updated.Name)
+		suite.Empty(updated.Password)
+		suite.Empty(suite.notifier.sent)
+	})
+
+	suite.Run("Email already used", func() {
+		suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account(), nil)
+		suite.mockRepo.EXPECT().GetByEmail(gomock.Any(), "taken@example.com").Return(&entity.Account{ID: 4}, nil)
+
+		_, err := suite.accountUsecase.UpdateAccount(context.Background(), "testuser", &entity.AccountUpdate{Email: "taken@example.com"})
+		suite.ErrorIs(err, ErrEmailExists)
+	})
+
+	suite.Run("New email is verified again", func() {
+		suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account(), nil)
+		suite.mockRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, nil)
+		suite.mockRepo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, account *entity.Account) error {
+				suite.Equal("new@example.com", account.Email)
+				account.EmailVerifiedAt = nil
+				return nil
+			})
+		suite.mockRepo.EXPECT().CreateAccountToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		updated, err := suite.accountUsecase.UpdateAccount(context.Background(), "testuser", &entity.AccountUpdate{Email: "new@example.com"})
+		suite.NoError(err)
+		suite.Nil(updated.EmailVerifiedAt)
+		suite.Require().Len(suite.notifier.sent, 1)
+		suite.Equal("new@example.com", suite.notifier.sent[0].To)
+	})
+}
+
+func (suite *AccountUsecaseTestSuite) TestDeleteAccount() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	enabledAt := now.Add(-time.Hour)
+	withTwoFactor := func() *entity.Account {
+		return &entity.Account{ID: 3, Password: string(hash), TOTPSecret: testTOTPSecret, TOTPLastStep: -1, TwoFactorEnabledAt: &enabledAt}
+	}
+
+	testCases := []struct {
+		name          string
+		account       *entity.Account
+		password      string
+		code          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:          "Wrong password",
+			account:       &entity.Account{ID: 3, Password: string(hash)},
+			password:      "guess",
+			mockBehavior:  func() {},
+			expectedError: entity.ErrWrongPassword,
+		},
+		{
+			name:     "Anonymises the account",
+			account:  &entity.Account{ID: 3, Password: string(hash)},
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Anonymize(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:          "Two-factor authentication needs a code besides the password",
+			account:       withTwoFactor(),
+			password:      "password123",
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:     "Code from the app",
+			account:  withTwoFactor(),
+			password: "password123",
+			code:     suite.totpCode(now),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseTOTPStep(gomock.Any(), 3, totp.Step(now)).Return(true, nil)
+				suite.mockRepo.EXPECT().Anonymize(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:     "Recovery code",
+			account:  withTwoFactor(),
+			password: "password123",
+			code:     "k3j7d-x7q2m-4hpa2-9vrtc-6ewq3-ybn5f",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), 3, hashToken("k3j7dx7q2m4hpa29vrtc6ewq3ybn5f")).Return(true, nil)
+				suite.mockRepo.EXPECT().Anonymize(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:     "Used recovery code",
+			account:  withTwoFactor(),
+			password: "password123",
+			code:     "k3j7d-x7q2m-4hpa2-9vrtc-6ewq3-ybn5f",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), 3, hashToken("k3j7dx7q2m4hpa29vrtc6ewq3ybn5f")).Return(false, nil)
+			},
+			expectedError: entity.ErrInvalidTwoFactorCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(tc.account, nil)
+			tc.mockBehavior()
+
+			err := suite.accountUsecase.DeleteAccount(context.Background(), "testuser", tc.password, tc.code)
+			suite.ErrorIs(err, tc.expectedError)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestLockAccount() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Locks and signs out",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "testuser"}, nil)
+				suite.mockRepo.EXPECT().SetLocked(gomock.Any(), 3, gomock.Not(gomock.Nil()), "chargebacks").Return(nil)
+				suite.mockRepo.EXPECT().RevokeAccountTokens(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name: "Own account",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "admin"}, nil)
+			},
+			expectedError: entity.ErrOwnAccount,
+		},
+		{
+			name: "Unknown account",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(nil, nil)
+			},
+			expectedError: entity.ErrAccountNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.accountUsecase.LockAccount(context.Background(), "admin", 3, "chargebacks")
+			suite.ErrorIs(err, tc.expectedError)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestLoginLockedAccount() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	lockedAt := time.Now()
+
+	suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").
+		Return(&entity.Account{ID: 3, Password: string(hash), LockedAt: &lockedAt}, nil).Times(2)
+	suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), "account:testuser", gomock.Any(), gomock.Any()).
+		Return(&entity.LoginAttempts{}, nil).Times(2)
+	suite.mockAttempts.EXPECT().Reset(gomock.Any(), "account:testuser").Return(nil)
+
+	_, err = suite.accountUsecase.Login(context.Background(), "testuser", "guess", "")
+	suite.NotErrorIs(err, entity.ErrAccountLocked)
+
+	_, err = suite.accountUsecase.Login(context.Background(), "testuser", "password123", "")
+	suite.ErrorIs(err, entity.ErrAccountLocked)
+}
+
+func (suite *AccountUsecaseTestSuite) TestSetRoles() {
+	testCases := []struct {
+		name          string
+		roles         []string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Replaces the roles and signs out",
+			roles: []string{"support", entity.RoleUser, "support"},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "testuser"}, nil)
+				suite.mockRepo.EXPECT().SetRoles(gomock.Any(), 3, []string{"support", entity.RoleUser}).Return(nil)
+				suite.mockRepo.EXPECT().RevokeAccountTokens(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:          "No roles",
+			roles:         []string{" "},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrNoRoles,
+		},
+		{
+			name:  "Own account",
+			roles: []string{entity.RoleUser},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "admin"}, nil)
+			},
+			expectedError: entity.ErrOwnAccount,
+		},
+		{
+			name:  "Unknown role",
+			roles: []string{"owner"},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "testuser"}, nil)
+				suite.mockRepo.EXPECT().SetRoles(gomock.Any(), 3, []string{"owner"}).Return(entity.ErrRoleNotFound)
+			},
+			expectedError: entity.ErrRoleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.accountUsecase.SetRoles(context.Background(), "admin", 3, tc.roles)
+			suite.ErrorIs(err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/notify"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePassword sets a new password for a logged in account after checking
+// the current one, and signs the account out of every session.
+func (u *AccountUsecase) ChangePassword(ctx context.Context, username, currentPassword, newPassword string) error {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return entity.ErrAccountNotFound
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(currentPassword)); err != nil {
+		return entity.ErrWrongPassword
+	}
+
+	return u.setPassword(ctx, account.ID, newPassword)
+}
+
+// RequestPasswordReset mails a reset link to the account with email. It
+// does not tell whether such an account exists, so it cannot be used to
+// probe for addresses.
+func (u *AccountUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	account, err := u.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return nil
+	}
+
+	token, err := u.createAccountToken(ctx, account, entity.PurposePasswordReset, entity.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.notifier.Send(ctx, notify.Message{
+		Event:   "password_reset",
+		To:      account.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use this link within %s to choose a new password: %s/reset-password?token=%s\n"+
+			"If you did not ask for this, you can ignore this email.", entity.PasswordResetTTL, u.appURL, token),
+		Data: map[string]interface{}{"token": token},
+	})
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset.
+// The token works once, and every existing session is signed out.
+func (u *AccountUsecase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	stored, err := u.repo.UseAccountToken(ctx, entity.PurposePasswordReset, hashToken(token), u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.setPassword(ctx, stored.AccountID, newPassword)
+}
+
+// VerifyEmail confirms the address a verification token was sent to.
+func (u *AccountUsecase) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := u.repo.UseAccountToken(ctx, entity.PurposeEmailVerification, hashToken(token), u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+
+	verified, err := u.repo.MarkEmailVerified(ctx, stored.AccountID, stored.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		// The address changed after the link was sent
+		return entity.ErrInvalidVerificationToken
+	}
+
+	return nil
+}
+
+// ResendVerification mails a new verification link; earlier links stop
+// working.
+func (u *AccountUsecase) ResendVerification(ctx context.Context, username string) error {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return entity.ErrAccountNotFound
+	}
+	if account.EmailVerifiedAt != nil {
+		return entity.ErrEmailAlreadyVerified
+	}
+
+	return u.sendVerification(ctx, account)
+}
+
+// IsEmailVerified is used to keep unverified accounts from ordering when
+// REQUIRE_VERIFIED_EMAIL is on.
+func (u *AccountUsecase) IsEmailVerified(ctx context.Context, username string) (bool, error) {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	return account != nil && account.EmailVerifiedAt != nil, nil
+}
+
+func (u *AccountUsecase) sendVerification(ctx context.Context, account *entity.Account) error {
+	token, err := u.createAccountToken(ctx, account, entity.PurposeEmailVerification, entity.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.notifier.Send(ctx, notify.Message{
+		Event:   "email_verification",
+		To:      account.Email,
+		Subject: "Confirm your email address",
+		Body:    fmt.Sprintf("Confirm your email address with this link: %s/verify-email?token=%s", u.appURL, token),
+		Data:    map[string]interface{}{"token": token},
+	})
+}
+
+func (u *AccountUsecase) createAccountToken(ctx context.Context, account *entity.Account, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.repo.CreateAccountToken(ctx, &entity.AccountToken{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     account.Email,
+		ExpiresAt: u.now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (u *AccountUsecase) setPassword(ctx context.Context, accountID int, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err = u.repo.UpdatePassword(ctx, accountID, string(hashedPassword)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrAccountNotFound
+		}
+		return err
+	}
+
+	// Whoever knew the old password may still hold a session
+	if err = u.repo.RevokeAccountTokens(ctx, accountID); err != nil {
+		log.Printf("failed to revoke sessions of account %d after a password change: %v", accountID, err)
+	}
+
+	return nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < entity.MinPasswordLength {
+		return entity.ErrWeakPassword
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"strings"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (suite *AccountUsecaseTestSuite) TestChangePassword() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	account := &entity.Account{ID: 3, Username: "testuser", Password: string(hash)}
+
+	testCases := []struct {
+		name          string
+		current       string
+		newPassword   string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:        "Changes the password and signs out every session",
+			current:     "old-password",
+			newPassword: "new-password",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account, nil)
+				suite.mockRepo.EXPECT().UpdatePassword(gomock.Any(), 3, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int, hash string) error {
+						suite.NoError(bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+						return nil
+					})
+				suite.mockRepo.EXPECT().RevokeAccountTokens(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:        "Wrong current password",
+			current:     "guess",
+			newPassword: "new-password",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account, nil)
+			},
+			expectedError: entity.ErrWrongPassword,
+		},
+		{
+			name:        "New password too short",
+			current:     "old-password",
+			newPassword: "short",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(account, nil)
+			},
+			expectedError: entity.ErrWeakPassword,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.accountUsecase.ChangePassword(context.Background(), "testuser", tc.current, tc.newPassword)
+			suite.ErrorIs(err, tc.expectedError)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestPasswordReset() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	suite.Run("Unknown email sends nothing", func() {
+		suite.mockRepo.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, nil)
+
+		suite.NoError(suite.accountUsecase.RequestPasswordReset(context.Background(), "nobody@example.com"))
+		suite.Empty(suite.notifier.sent)
+	})
+
+	var token string
+	suite.Run("Mails a reset link", func() {
+		suite.mockRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").
+			Return(&entity.Account{ID: 3, Email: "test@example.com"}, nil)
+		suite.mockRepo.EXPECT().CreateAccountToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, stored *entity.AccountToken) error {
+				suite.Equal(entity.PurposePasswordReset, stored.Purpose)
+				suite.Equal(now.Add(entity.PasswordResetTTL), stored.ExpiresAt)
+				return nil
+			})
+
+		suite.NoError(suite.accountUsecase.RequestPasswordReset(context.Background(), "test@example.com"))
+		suite.Require().Len(suite.notifier.sent, 1)
+		msg := suite.notifier.sent[0]
+		suite.Equal("test@example.com", msg.To)
+		token = msg.Data["token"].(string)
+		suite.Contains(msg.Body, "https://shop.example.com/reset-password?token="+token)
+	})
+
+	suite.Run("Resets with the token", func() {
+		suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), entity.PurposePasswordReset, hashToken(token), now).
+			Return(&entity.AccountToken{AccountID: 3}, nil)
+		suite.mockRepo.EXPECT().UpdatePassword(gomock.Any(), 3, gomock.Any()).Return(nil)
+		suite.mockRepo.EXPECT().RevokeAccountTokens(gomock.Any(), 3).Return(nil)
+
+		suite.NoError(suite.accountUsecase.ResetPassword(context.Background(), token, "new-password"))
+	})
+
+	suite.Run("Used or expired token", func() {
+		suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), entity.PurposePasswordReset, hashToken(token), now).
+			Return(nil, sql.ErrNoRows)
+
+		err := suite.accountUsecase.ResetPassword(context.Background(), token, "new-password")
+		suite.ErrorIs(err, entity.ErrInvalidResetToken)
+	})
+}
+
+func (suite *AccountUsecaseTestSuite) TestVerifyEmail() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Verifies the address",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), entity.PurposeEmailVerification, hashToken("token"), gomock.Any()).
+					Return(&entity.AccountToken{AccountID: 3, Email: "test@example.com"}, nil)
+				suite.mockRepo.EXPECT().MarkEmailVerified(gomock.Any(), 3, "test@example.com").Return(true, nil)
+			},
+		},
+		{
+			name: "Address changed since the link was sent",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&entity.AccountToken{AccountID: 3, Email: "old@example.com"}, nil)
+				suite.mockRepo.EXPECT().MarkEmailVerified(gomock.Any(), 3, "old@example.com").Return(false, nil)
+			},
+			expectedError: entity.ErrInvalidVerificationToken,
+		},
+		{
+			name: "Unknown token",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, sql.ErrNoRows)
+			},
+			expectedError: entity.ErrInvalidVerificationToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			suite.ErrorIs(suite.accountUsecase.VerifyEmail(context.Background(), "token"), tc.expectedError)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestResendVerification() {
+	verifiedAt := time.Now()
+
+	suite.Run("Already verified", func() {
+		suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").
+			Return(&entity.Account{ID: 3, EmailVerifiedAt: &verifiedAt}, nil)
+
+		err := suite.accountUsecase.ResendVerification(context.Background(), "testuser")
+		suite.ErrorIs(err, entity.ErrEmailAlreadyVerified)
+	})
+
+	suite.Run("Mails a new link", func() {
+		suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").
+			Return(&entity.Account{ID: 3, Email: "test@example.com"}, nil)
+		suite.mockRepo.EXPECT().CreateAccountToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		suite.NoError(suite.accountUsecase.ResendVerification(context.Background(), "testuser"))
+		suite.Require().Len(suite.notifier.sent, 1)
+		suite.True(strings.HasPrefix(suite.notifier.sent[0].Subject, "Confirm"))
+	})
+}
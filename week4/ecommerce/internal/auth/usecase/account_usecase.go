@@ -4,33 +4,74 @@ import (
 	"context"
 	"ecommerce/internal/auth/entity"
 	"ecommerce/internal/auth/repository"
+	"ecommerce/pkg/notify"
+	"ecommerce/pkg/utils"
 	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Update your AccountUsecase to use custom errors for better testing
  var (
-	ErrUsernameExists = errors.New("username already exists")
-	ErrEmailExists    = errors.New("email already exists")
+	ErrUsernameExists     = errors.New("username already exists")
+	ErrEmailExists        = errors.New("email already exists")
+	ErrInvalidCredentials = errors.New("invalid username or password")
 )
 
 type IAccountUsecase interface {
 	Register(ctx context.Context, account *entity.Account) error
-	Login(ctx context.Context, username, password string) (*entity.Account, error)
-	// GetAccountByID(id int) (*entity.Account, error)
-	// UpdateAccount(account *entity.Account) error
-	// DeleteAccount(id int) error
-	// ChangePassword(id int, oldPassword, newPassword string) error
+	Login(ctx context.Context, username, password, ip string) (*entity.Account, error)
+	IssueTokens(ctx context.Context, account *entity.Account) (*entity.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*entity.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string, claims *utils.Claims) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredTokens(ctx context.Context) error
+	DeleteExpiredLoginAttempts(ctx context.Context) error
+	ChangePassword(ctx context.Context, username, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, username string) error
+	IsEmailVerified(ctx context.Context, username string) (bool, error)
+	GetAccount(ctx context.Context, username string) (*entity.Account, error)
+	UpdateAccount(ctx context.Context, username string, update *entity.AccountUpdate) (*entity.Account, error)
+	DeleteAccount(ctx context.Context, username, password, code string) error
+	GetAllAccounts(ctx context.Context) ([]*entity.Account, error)
+	LockAccount(ctx context.Context, actor string, id int, reason string) error
+	UnlockAccount(ctx context.Context, id int) error
+	SetRoles(ctx context.Context, actor string, id int, roles []string) error
+	EnrolTwoFactor(ctx context.Context, username string) (*entity.TwoFactorEnrolment, error)
+	ConfirmTwoFactor(ctx context.Context, username, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, username, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error)
+	ResetTwoFactor(ctx context.Context, actor string, id int) error
+	CreateLoginChallenge(ctx context.Context, account *entity.Account) (*entity.LoginChallenge, error)
+	CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*entity.TokenPair, error)
 }
 
 type AccountUsecase struct {
-	repo repository.IAccountRepository
+	repo     repository.IAccountRepository
+	attempts repository.ILoginAttemptRepository
+	notifier notify.Notifier
+	// appURL is where the links in account emails point to
+	appURL string
+	// requireTwoFactor keeps elevated permissions out of the tokens of
+	// accounts without two-factor authentication
+	requireTwoFactor bool
+	now              func() time.Time
 }
 
-func NewAccountUsecase(repo repository.IAccountRepository) IAccountUsecase {
+func NewAccountUsecase(repo repository.IAccountRepository, attempts repository.ILoginAttemptRepository, notifier notify.Notifier, appURL string, requireTwoFactor bool) IAccountUsecase {
 	return &AccountUsecase{
-		repo: repo,
+		repo:             repo,
+		attempts:         attempts,
+		notifier:         notifier,
+		appURL:           strings.TrimSuffix(appURL, "/"),
+		requireTwoFactor: requireTwoFactor,
+		now:              time.Now,
 	}
 }
 
@@ -47,6 +88,10 @@ func (u *AccountUsecase) Register(ctx context.Context, account *entity.Account)
 		return ErrEmailExists
 	}
 
+	if err = validatePassword(account.Password); err != nil {
+		return err
+	}
+
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(account.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -58,18 +103,53 @@ func (u *AccountUsecase) Register(ctx context.Context, account *entity.Account)
 		SetPassword(string(hashedPassword))
 
 	// Save the account
-	return u.repo.Register(ctx, account)
+	if err = u.repo.Register(ctx, account); err != nil {
+		return err
+	}
+
+	// The account works without a verified email, so a failed mail only
+	// means the user has to ask for it again
+	if err = u.sendVerification(ctx, account); err != nil {
+		log.Printf("failed to send verification email to %s: %v", account.Username, err)
+	}
+
+	return nil
 }
 
-func (u *AccountUsecase) Login(ctx context.Context, username, password string) (*entity.Account, error) {
-	account, err := u.repo.GetByUsername(ctx, username)
+// Login checks the password of username. Logins are counted for the account
+// and for ip before the password is checked, and taken back when it is
+// right; past a few failures, logins have to wait longer and longer and
+// eventually get locked out for a while, see entity.LoginLimit. Accounts
+// with two-factor authentication then go on with CreateLoginChallenge.
+func (u *AccountUsecase) Login(ctx context.Context, username, password, ip string) (*entity.Account, error) {
+	attempts, err := u.startLogin(ctx, loginKeys(username, ip))
 	if err != nil {
-		return nil, errors.New("invalid username or password")
+		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password))
+	account, err := u.repo.GetByUsername(ctx, username)
 	if err != nil {
-		return nil, errors.New("invalid username or password")
+		return nil, ErrInvalidCredentials
+	}
+
+	if account == nil || bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)) != nil {
+		u.recordLoginFailure(ctx, attempts)
+		return nil, ErrInvalidCredentials
+	}
+
+	// With two-factor authentication the account only starts over once the
+	// code is right, or the password would buy unlimited guesses at it
+	reset := accountLoginKey(username)
+	if account.TwoFactorEnabled() {
+		reset = ""
+	}
+	if err = u.finishLogin(ctx, attempts, reset); err != nil {
+		return nil, err
+	}
+
+	// Only reported once the password is right, so it reveals nothing
+	if account.LockedAt != nil {
+		return nil, entity.ErrAccountLocked
 	}
 
 	return account, nil
@@ -4,23 +4,38 @@ import (
 	"context"
 	"ecommerce/internal/auth/entity"
 	mock_repository "ecommerce/internal/auth/mocks"
+	"ecommerce/pkg/notify"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 )
 
+type recordingNotifier struct {
+	sent []notify.Message
+	err  error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
 type AccountUsecaseTestSuite struct {
 	suite.Suite
 	mockCtrl       *gomock.Controller
 	mockRepo       *mock_repository.MockIAccountRepository
+	mockAttempts   *mock_repository.MockILoginAttemptRepository
+	notifier       *recordingNotifier
 	accountUsecase IAccountUsecase
 }
 
 func (suite *AccountUsecaseTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockRepo = mock_repository.NewMockIAccountRepository(suite.mockCtrl)
-	suite.accountUsecase = NewAccountUsecase(suite.mockRepo)
+	suite.mockAttempts = mock_repository.NewMockILoginAttemptRepository(suite.mockCtrl)
+	suite.notifier = &recordingNotifier{}
+	suite.accountUsecase = NewAccountUsecase(suite.mockRepo, suite.mockAttempts, suite.notifier, "https://shop.example.com/", true)
 }
 
 func (suite *AccountUsecaseTestSuite) TearDownTest() {
@@ -48,6 +63,7 @@ func (suite *AccountUsecaseTestSuite) TestRegister() {
             mockBehavior: func() {
                 suite.mockRepo.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
                 suite.mockRepo.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil)
+                suite.mockRepo.EXPECT().CreateAccountToken(gomock.Any(), gomock.Any()).Return(nil)
             },
             expectedError: nil,
         },
@@ -63,6 +79,18 @@ func (suite *AccountUsecaseTestSuite) TestRegister() {
             },
             expectedError: ErrUsernameExists,
         },
+        {
+            name: "Password too short",
+            input: &entity.Account{
+                Username: "testuser",
+                Email:    "test@example.com",
+                Password: "short",
+            },
+            mockBehavior: func() {
+                suite.mockRepo.EXPECT().Login(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+            },
+            expectedError: entity.ErrWeakPassword,
+        },
     }
 
     for _, tc := range testCases {
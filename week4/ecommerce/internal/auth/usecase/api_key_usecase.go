@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/repository"
+	"ecommerce/pkg/utils"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var apiKeyName = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// APIKeyUsecase manages the API keys other services call the API with. A
+// key is looked up on every request, so revoking it takes effect at once.
+type APIKeyUsecase struct {
+	repo repository.IAPIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeyUsecase(repo repository.IAPIKeyRepository) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (ku *APIKeyUsecase) GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error) {
+	return ku.repo.GetAll(ctx)
+}
+
+// CreateAPIKey creates a key with the name, permissions and expiry of key.
+// caller can only hand out permissions they have themselves.
+func (ku *APIKeyUsecase) CreateAPIKey(ctx context.Context, caller *utils.Claims, key *entity.APIKey) (*entity.CreatedAPIKey, error) {
+	key.Name = strings.TrimSpace(key.Name)
+	if !apiKeyName.MatchString(key.Name) {
+		return nil, entity.ErrInvalidAPIKeyName
+	}
+
+	permissions, err := validPermissions(key.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) == 0 {
+		return nil, entity.ErrAPIKeyNoPermissions
+	}
+	for _, permission := range permissions {
+		if !caller.HasPermission(permission) {
+			return nil, entity.ErrAPIKeyPermission
+		}
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(ku.now()) {
+		return nil, entity.ErrAPIKeyExpiry
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	raw := entity.APIKeyPrefix + secret
+
+	created := &entity.APIKey{
+		Name:        key.Name,
+		Prefix:      raw[:len(entity.APIKeyPrefix)+6],
+		KeyHash:     hashToken(raw),
+		Permissions: permissions,
+		CreatedBy:   caller.Username,
+		ExpiresAt:   key.ExpiresAt,
+	}
+	if err = ku.repo.Create(ctx, created); err != nil {
+		return nil, err
+	}
+
+	return &entity.CreatedAPIKey{APIKey: created, Key: raw}, nil
+}
+
+func (ku *APIKeyUsecase) RevokeAPIKey(ctx context.Context, id int) error {
+	return ku.repo.Revoke(ctx, id, ku.now())
+}
+
+// AuthenticateAPIKey gives the claims requests made with key act with, or
+// nil when the key is unknown, expired or revoked.
+func (ku *APIKeyUsecase) AuthenticateAPIKey(ctx context.Context, key string) (*utils.Claims, error) {
+	if !strings.HasPrefix(key, entity.APIKeyPrefix) {
+		return nil, nil
+	}
+
+	apiKey, err := ku.repo.GetByHash(ctx, hashToken(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := ku.now()
+	if !apiKey.Active(now) {
+		return nil, nil
+	}
+
+	// Only bookkeeping, so the request goes on if it cannot be saved
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= entity.APIKeyLastUsedInterval {
+		if err = ku.repo.SetLastUsed(ctx, apiKey.ID, now); err != nil {
+			log.Printf("api key %d: saving last use: %v", apiKey.ID, err)
+		}
+	}
+
+	return &utils.Claims{
+		Username:    entity.APIKeyUsernamePrefix + apiKey.Name,
+		Permissions: apiKey.Permissions,
+		APIKeyID:    apiKey.ID,
+	}, nil
+}
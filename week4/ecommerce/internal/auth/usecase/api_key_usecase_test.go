@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	mock_repository "ecommerce/internal/auth/mocks"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type APIKeyUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	mockRepo *mock_repository.MockIAPIKeyRepository
+	usecase  *APIKeyUsecase
+	now      time.Time
+}
+
+func (suite *APIKeyUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIAPIKeyRepository(suite.mockCtrl)
+	suite.usecase = NewAPIKeyUsecase(suite.mockRepo)
+	suite.now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.usecase.now = func() time.Time { return suite.now }
+}
+
+func (suite *APIKeyUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestAPIKeyUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyUsecaseTestSuite))
+}
+
+func (suite *APIKeyUsecaseTestSuite) TestCreateAPIKey() {
+	admin := &utils.Claims{
+		Username:    "admin",
+		Permissions: []string{rbac.InventoryWrite, rbac.OrderReadAny, rbac.APIKeyManage},
+	}
+	past := suite.now.Add(-time.Hour)
+
+	testCases := []struct {
+		name          string
+		key           *entity.APIKey
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Scoped key",
+			key:  &entity.APIKey{Name: "warehouse", Permissions: []string{rbac.OrderReadAny, rbac.InventoryWrite, rbac.OrderReadAny}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key *entity.APIKey) error {
+						suite.Equal([]string{rbac.InventoryWrite, rbac.OrderReadAny}, key.Permissions)
+						suite.Equal("admin", key.CreatedBy)
+						suite.True(strings.HasPrefix(key.Prefix, entity.APIKeyPrefix))
+						key.ID = 4
+						return nil
+					})
+			},
+		},
+		{
+			name:          "Invalid name",
+			key:           &entity.APIKey{Name: "Warehouse Sync", Permissions: []string{rbac.InventoryWrite}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidAPIKeyName,
+		},
+		{
+			name:          "No permissions",
+			key:           &entity.APIKey{Name: "warehouse"},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrAPIKeyNoPermissions,
+		},
+		{
+			name:          "Unknown permission",
+			key:           &entity.APIKey{Name: "warehouse", Permissions: []string{"stock:everything"}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrUnknownPermission,
+		},
+		{
+			name:          "Permission the creator lacks",
+			key:           &entity.APIKey{Name: "warehouse", Permissions: []string{rbac.RoleManage}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrAPIKeyPermission,
+		},
+		{
+			name:          "Expiry in the past",
+			key:           &entity.APIKey{Name: "warehouse", Permissions: []string{rbac.InventoryWrite}, ExpiresAt: &past},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrAPIKeyExpiry,
+		},
+		{
+			name: "Name in use",
+			key:  &entity.APIKey{Name: "warehouse", Permissions: []string{rbac.InventoryWrite}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(entity.ErrAPIKeyExists)
+			},
+			expectedError: entity.ErrAPIKeyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			created, err := suite.usecase.CreateAPIKey(context.Background(), admin, tc.key)
+			if tc.expectedError != nil {
+				suite.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			suite.NoError(err)
+			suite.Equal(4, created.ID)
+			suite.True(strings.HasPrefix(created.Key, created.Prefix))
+			suite.Equal(hashToken(created.Key), created.KeyHash)
+		})
+	}
+}
+
+func (suite *APIKeyUsecaseTestSuite) TestAuthenticateAPIKey() {
+	const raw = entity.APIKeyPrefix + "secret"
+	justUsed := suite.now.Add(-10 * time.Second)
+	expired := suite.now.Add(-time.Minute)
+	revoked := suite.now.Add(-time.Hour)
+	stored := func() *entity.APIKey {
+		return &entity.APIKey{ID: 4, Name: "warehouse", Permissions: []string{rbac.InventoryWrite}}
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		mockBehavior  func()
+		expectedValid bool
+		expectedError bool
+	}{
+		{
+			name: "Valid key records its use",
+			key:  raw,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(stored(), nil)
+				suite.mockRepo.EXPECT().SetLastUsed(gomock.Any(), 4, suite.now).Return(nil)
+			},
+			expectedValid: true,
+		},
+		{
+			name: "Recently used key is not written again",
+			key:  raw,
+			mockBehavior: func() {
+				key := stored()
+				key.LastUsedAt = &justUsed
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(key, nil)
+			},
+			expectedValid: true,
+		},
+		{
+			name: "Failing to record the use does not fail the request",
+			key:  raw,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(stored(), nil)
+				suite.mockRepo.EXPECT().SetLastUsed(gomock.Any(), 4, suite.now).Return(errors.New("db down"))
+			},
+			expectedValid: true,
+		},
+		{
+			name:         "Not an API key",
+			key:          "secret",
+			mockBehavior: func() {},
+		},
+		{
+			name: "Unknown key",
+			key:  raw,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(nil, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "Expired key",
+			key:  raw,
+			mockBehavior: func() {
+				key := stored()
+				key.ExpiresAt = &expired
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(key, nil)
+			},
+		},
+		{
+			name: "Revoked key",
+			key:  raw,
+			mockBehavior: func() {
+				key := stored()
+				key.RevokedAt = &revoked
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(key, nil)
+			},
+		},
+		{
+			name: "Lookup fails",
+			key:  raw,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByHash(gomock.Any(), hashToken(raw)).Return(nil, errors.New("db down"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			claims, err := suite.usecase.AuthenticateAPIKey(context.Background(), tc.key)
+			if tc.expectedError {
+				suite.Error(err)
+				return
+			}
+
+			suite.NoError(err)
+			if !tc.expectedValid {
+				suite.Nil(claims)
+				return
+			}
+
+			suite.Equal("api-key:warehouse", claims.Username)
+			suite.Equal(4, claims.APIKeyID)
+			suite.True(claims.HasPermission(rbac.InventoryWrite))
+		})
+	}
+}
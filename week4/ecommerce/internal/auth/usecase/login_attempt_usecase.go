@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/notify"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// loginKey is a key failed logins are counted under, with its limit.
+type loginKey struct {
+	key   string
+	limit entity.LoginLimit
+}
+
+func accountLoginKey(username string) string {
+	return "account:" + username
+}
+
+// loginKeys are the keys a login is throttled by. Unknown usernames are
+// counted too, so the limits do not tell which accounts exist.
+func loginKeys(username, ip string) []loginKey {
+	keys := []loginKey{{key: accountLoginKey(username), limit: entity.AccountLoginLimit}}
+	if ip != "" {
+		keys = append(keys, loginKey{key: "ip:" + ip, limit: entity.IPLoginLimit})
+	}
+
+	return keys
+}
+
+// loginAttempt is a login counted under a key by startLogin.
+type loginAttempt struct {
+	loginKey
+	at     time.Time
+	before *entity.LoginAttempts
+}
+
+// failures is the count of the key with this attempt.
+func (a loginAttempt) failures() int {
+	if a.at.Sub(a.before.LastFailureAt) > a.limit.Window {
+		return 1
+	}
+
+	return a.before.Failures + 1
+}
+
+// startLogin counts a login under every key before its password is checked,
+// and returns an *entity.LoginThrottledError while any of them is locked
+// out or backing off. Counting first means logins made at the same time see
+// each other, so a burst of them gets no more guesses than the limits allow.
+// The attempts are passed on to finishLogin or recordLoginFailure.
+func (u *AccountUsecase) startLogin(ctx context.Context, keys []loginKey) ([]loginAttempt, error) {
+	now := u.now()
+
+	attempts := make([]loginAttempt, 0, len(keys))
+	var wait time.Duration
+	for _, k := range keys {
+		before, err := u.attempts.RecordAttempt(ctx, k.key, now, now.Add(-k.limit.Window))
+		if err != nil {
+			u.forgiveAttempts(ctx, attempts)
+			return nil, err
+		}
+		attempts = append(attempts, loginAttempt{loginKey: k, at: now, before: before})
+
+		if retryAfter := before.RetryAfter(now, k.limit); retryAfter > wait {
+			wait = retryAfter
+		}
+	}
+
+	// A throttled login guessed nothing, so it is taken back. Counted, the
+	// retries of a locked out key would lock it out again and keep its
+	// failures from ever expiring.
+	if wait > 0 {
+		u.forgiveAttempts(ctx, attempts)
+		return nil, &entity.LoginThrottledError{RetryAfter: wait}
+	}
+
+	return attempts, nil
+}
+
+// forgiveAttempts takes back attempts of a login that was never checked.
+// The login fails either way, so errors are only logged.
+func (u *AccountUsecase) forgiveAttempts(ctx context.Context, attempts []loginAttempt) {
+	for _, a := range attempts {
+		if err := u.attempts.Forgive(ctx, a.key, a.at, a.before.LastFailureAt); err != nil {
+			log.Printf("failed to take back the login attempt of %s: %v", a.key, err)
+		}
+	}
+}
+
+// finishLogin takes back the attempts of a login whose password was right,
+// except under the reset key, if any, which starts over instead. The IP
+// keeps its count either way, so one working login does not let it guess at
+// other accounts again.
+func (u *AccountUsecase) finishLogin(ctx context.Context, attempts []loginAttempt, reset string) error {
+	for _, a := range attempts {
+		var err error
+		if a.key == reset {
+			err = u.attempts.Reset(ctx, a.key)
+		} else {
+			err = u.attempts.Forgive(ctx, a.key, a.at, a.before.LastFailureAt)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure locks out the keys whose counted attempts reached their
+// limit with this failed login. The login has failed either way, so errors
+// are only logged.
+func (u *AccountUsecase) recordLoginFailure(ctx context.Context, attempts []loginAttempt) {
+	for _, a := range attempts {
+		failures := a.failures()
+		if failures < a.limit.MaxFailures {
+			continue
+		}
+
+		until := a.at.Add(a.limit.Lockout)
+		if err := u.attempts.Lock(ctx, a.key, until); err != nil {
+			log.Printf("failed to lock out %s: %v", a.key, err)
+			continue
+		}
+
+		locked := &entity.LoginAttempts{Key: a.key, Failures: failures, LastFailureAt: a.at}
+		if err := u.notifier.Send(ctx, lockoutMessage(locked, until)); err != nil {
+			log.Printf("failed to send lockout notification for %s: %v", a.key, err)
+		}
+	}
+}
+
+// lockoutMessage is the audit event sent to the admins for a lockout.
+func lockoutMessage(attempts *entity.LoginAttempts, until time.Time) notify.Message {
+	to := os.Getenv("ADMIN_EMAIL")
+	if to == "" {
+		to = "admins"
+	}
+
+	return notify.Message{
+		Event:   "login_lockout",
+		To:      to,
+		Subject: fmt.Sprintf("Logins locked out for %s", attempts.Key),
+		Body: fmt.Sprintf("%s made %d failed logins in a row and is locked out until %s.",
+			attempts.Key, attempts.Failures, until.Format(time.RFC3339)),
+		Data: map[string]interface{}{
+			"key":          attempts.Key,
+			"failures":     attempts.Failures,
+			"locked_until": until,
+		},
+	}
+}
+
+// DeleteExpiredLoginAttempts drops the failures no limit remembers anymore.
+// It is run by the scheduler.
+func (u *AccountUsecase) DeleteExpiredLoginAttempts(ctx context.Context) error {
+	window := entity.AccountLoginLimit.Window
+	if entity.IPLoginLimit.Window > window {
+		window = entity.IPLoginLimit.Window
+	}
+
+	_, err := u.attempts.DeleteExpired(ctx, u.now().Add(-window))
+	return err
+}
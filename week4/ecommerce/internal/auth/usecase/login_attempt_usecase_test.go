@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/infra"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (suite *AccountUsecaseTestSuite) TestLoginThrottling() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	account := &entity.Account{ID: 3, Username: "alice", Password: string(hash)}
+
+	const accountKey, ipKey = "account:alice", "ip:203.0.113.7"
+	accountSince := now.Add(-entity.AccountLoginLimit.Window)
+	ipSince := now.Add(-entity.IPLoginLimit.Window)
+	lockedUntil := now.Add(10 * time.Minute)
+	earlier := now.Add(-30 * time.Minute)
+
+	testCases := []struct {
+		name               string
+		password           string
+		mockBehavior       func()
+		expectedError      error
+		expectedRetryAfter time.Duration
+		expectedLockouts   []string
+	}{
+		{
+			name:     "Successful login starts the account over and takes back the IP's attempt",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 2, LastFailureAt: now.Add(-time.Minute)}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, LastFailureAt: earlier}, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+				suite.mockAttempts.EXPECT().Reset(gomock.Any(), accountKey).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, earlier).Return(nil)
+			},
+		},
+		{
+			name:     "Wrong password stays counted for the account and the IP",
+			password: "guess",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, LastFailureAt: now}, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+			},
+			expectedError: ErrInvalidCredentials,
+		},
+		{
+			name:     "Unknown username counts like a wrong password",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), now, gomock.Any()).
+					Return(&entity.LoginAttempts{LastFailureAt: now}, nil).Times(2)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(nil, nil)
+			},
+			expectedError: ErrInvalidCredentials,
+		},
+		{
+			name:     "Last allowed failure locks the account out",
+			password: "guess",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 4, LastFailureAt: now.Add(-time.Minute)}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, Failures: 4, LastFailureAt: now.Add(-time.Minute)}, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+				suite.mockAttempts.EXPECT().Lock(gomock.Any(), accountKey, now.Add(entity.AccountLoginLimit.Lockout)).Return(nil)
+			},
+			expectedError:    ErrInvalidCredentials,
+			expectedLockouts: []string{accountKey},
+		},
+		{
+			name:     "Failed login - Backing off after recent failures",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 3, LastFailureAt: now.Add(-time.Second)}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), accountKey, now, now.Add(-time.Second)).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+			},
+			expectedRetryAfter: 3 * time.Second,
+		},
+		{
+			name:     "Failed login - Account locked out, even with the right password",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 1, LastFailureAt: now.Add(-5 * time.Minute), LockedUntil: &lockedUntil}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), accountKey, now, now.Add(-5*time.Minute)).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+			},
+			expectedRetryAfter: 10 * time.Minute,
+		},
+		{
+			name:     "Failed login - IP locked out",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, Failures: 3, LastFailureAt: now.Add(-time.Minute), LockedUntil: &lockedUntil}, nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), accountKey, now, now).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now.Add(-time.Minute)).Return(nil)
+			},
+			expectedRetryAfter: 10 * time.Minute,
+		},
+		{
+			name:     "Failed login - Another login of the account is still being checked",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 1, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, Failures: 1, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), accountKey, now, now).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+			},
+			expectedRetryAfter: entity.LoginBackoffBase,
+		},
+		{
+			// The IP does not back off, but logins still being checked use
+			// up its failures before any of them locked it out
+			name:     "Failed login - Concurrent logins used up the IP's failures",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, Failures: entity.IPLoginLimit.MaxFailures, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), accountKey, now, now).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+			},
+			expectedRetryAfter: entity.IPLoginLimit.Lockout,
+		},
+		{
+			name:     "Concurrent logins from one IP do not slow each other down",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, LastFailureAt: now}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, Failures: 3, LastFailureAt: now}, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+				suite.mockAttempts.EXPECT().Reset(gomock.Any(), accountKey).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+			},
+		},
+		{
+			name:     "Old failures no longer slow the login down",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), accountKey, now, accountSince).
+					Return(&entity.LoginAttempts{Key: accountKey, Failures: 4, LastFailureAt: now.Add(-2 * time.Hour)}, nil)
+				suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), ipKey, now, ipSince).
+					Return(&entity.LoginAttempts{Key: ipKey, LastFailureAt: earlier}, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+				suite.mockAttempts.EXPECT().Reset(gomock.Any(), accountKey).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, earlier).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.notifier.sent = nil
+			tc.mockBehavior()
+
+			result, err := suite.accountUsecase.Login(context.Background(), "alice", tc.password, "203.0.113.7")
+			switch {
+			case tc.expectedRetryAfter > 0:
+				var throttled *entity.LoginThrottledError
+				suite.Require().ErrorAs(err, &throttled)
+				suite.Equal(tc.expectedRetryAfter, throttled.RetryAfter)
+			case tc.expectedError != nil:
+				suite.EqualError(err, tc.expectedError.Error())
+			default:
+				suite.NoError(err)
+				suite.Equal(account, result)
+			}
+
+			var lockouts []string
+			for _, msg := range suite.notifier.sent {
+				suite.Equal("login_lockout", msg.Event)
+				lockouts = append(lockouts, msg.Data["key"].(string))
+			}
+			suite.Equal(tc.expectedLockouts, lockouts)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestUnlockAccountLiftsLoginLockout() {
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "alice"}, nil)
+	suite.mockRepo.EXPECT().SetLocked(gomock.Any(), 3, nil, "").Return(nil)
+	suite.mockAttempts.EXPECT().Reset(gomock.Any(), "account:alice").Return(nil)
+
+	suite.NoError(suite.accountUsecase.UnlockAccount(context.Background(), 3))
+
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 4).Return(nil, nil)
+	suite.ErrorIs(suite.accountUsecase.UnlockAccount(context.Background(), 4), entity.ErrAccountNotFound)
+}
+
+// throttledAccount is a usecase counting logins in memory with a clock the
+// test moves, for checking how the limits play out over several logins.
+func (suite *AccountUsecaseTestSuite) throttledAccount(now *time.Time) *AccountUsecase {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	account := &entity.Account{ID: 3, Username: "alice", Password: string(hash)}
+	suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil).AnyTimes()
+
+	u := NewAccountUsecase(suite.mockRepo, infra.NewLoginAttemptMemoryRepository(), suite.notifier, "https://shop.example.com/", true).(*AccountUsecase)
+	u.now = func() time.Time { return *now }
+
+	return u
+}
+
+func (suite *AccountUsecaseTestSuite) TestLoginAfterLockoutDespiteThrottledRetries() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	u := suite.throttledAccount(&now)
+	ctx := context.Background()
+
+	for i := 1; i <= entity.AccountLoginLimit.MaxFailures; i++ {
+		_, err := u.Login(ctx, "alice", "guess", "203.0.113.7")
+		suite.Require().ErrorIs(err, ErrInvalidCredentials)
+		now = now.Add(entity.LoginBackoff(i))
+	}
+	suite.Require().Len(suite.notifier.sent, 1)
+	lockedUntil := suite.notifier.sent[0].Data["locked_until"].(time.Time)
+
+	// Retrying while locked out neither extends the lockout nor counts
+	// towards the next one
+	var throttled *entity.LoginThrottledError
+	for now.Before(lockedUntil) {
+		_, err := u.Login(ctx, "alice", "password123", "203.0.113.7")
+		suite.Require().ErrorAs(err, &throttled)
+		now = now.Add(time.Minute)
+	}
+
+	account, err := u.Login(ctx, "alice", "password123", "203.0.113.7")
+	suite.Require().NoError(err)
+	suite.Equal(3, account.ID)
+	suite.Len(suite.notifier.sent, 1)
+}
+
+func (suite *AccountUsecaseTestSuite) TestLoginAfterBackoffDespiteThrottledRetries() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	u := suite.throttledAccount(&now)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := u.Login(ctx, "alice", "guess", "203.0.113.7")
+		suite.Require().ErrorIs(err, ErrInvalidCredentials)
+		now = now.Add(entity.LoginBackoff(i + 1))
+	}
+	backoffEnds := now
+	now = now.Add(-entity.LoginBackoff(2))
+
+	// Retrying too early does not push the backoff further out
+	var throttled *entity.LoginThrottledError
+	for i := 0; i < 5; i++ {
+		_, err := u.Login(ctx, "alice", "password123", "203.0.113.7")
+		suite.Require().ErrorAs(err, &throttled)
+		suite.Equal(backoffEnds.Sub(now), throttled.RetryAfter)
+	}
+
+	now = backoffEnds
+	_, err := u.Login(ctx, "alice", "password123", "203.0.113.7")
+	suite.NoError(err)
+}
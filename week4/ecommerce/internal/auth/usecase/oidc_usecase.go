@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/repository"
+	"ecommerce/pkg/notify"
+	"ecommerce/pkg/oidc"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// OIDCUsecase signs users in with the identity provider. An identity is
+// linked to an account the first time it is used: to the account with the
+// same verified email, or to a new account.
+type OIDCUsecase struct {
+	provider *oidc.Provider
+	repo     repository.IOIDCRepository
+	accounts repository.IAccountRepository
+	notifier notify.Notifier
+	now      func() time.Time
+}
+
+// NewOIDCUsecase takes a nil provider when sign-in with an identity provider
+// is not configured.
+func NewOIDCUsecase(provider *oidc.Provider, repo repository.IOIDCRepository, accounts repository.IAccountRepository, notifier notify.Notifier) *OIDCUsecase {
+	return &OIDCUsecase{
+		provider: provider,
+		repo:     repo,
+		accounts: accounts,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// StartLogin returns where to send the user to sign in, and the state the
+// provider sends them back with. The state has to be kept in the browser,
+// so a sign-in cannot be finished in someone else's.
+func (ou *OIDCUsecase) StartLogin(ctx context.Context) (authURL, state string, err error) {
+	if ou.provider == nil {
+		return "", "", entity.ErrOIDCDisabled
+	}
+
+	var nonce, verifier string
+	for _, value := range []*string{&state, &nonce, &verifier} {
+		if *value, err = oidc.RandomString(); err != nil {
+			return "", "", err
+		}
+	}
+
+	authURL, err = ou.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = ou.repo.CreateLogin(ctx, &entity.OIDCLogin{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    ou.now().Add(entity.OIDCLoginTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteLogin exchanges the code the provider sent the user back with and
+// returns their account, linking or creating it on first sign-in. Like
+// AccountUsecase.Login, it leaves two-factor authentication and the tokens
+// to the caller.
+func (ou *OIDCUsecase) CompleteLogin(ctx context.Context, state, code string) (*entity.Account, error) {
+	if ou.provider == nil {
+		return nil, entity.ErrOIDCDisabled
+	}
+
+	login, err := ou.repo.UseLogin(ctx, hashToken(state), ou.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := ou.provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrOIDCSignInFailed, err)
+	}
+
+	account, err := ou.linkedAccount(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	if account.LockedAt != nil {
+		return nil, entity.ErrAccountLocked
+	}
+
+	return account, nil
+}
+
+func (ou *OIDCUsecase) linkedAccount(ctx context.Context, identity *oidc.IDToken) (*entity.Account, error) {
+	accountID, err := ou.repo.GetLinkedAccountID(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		account, err := ou.accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, entity.ErrAccountNotFound
+		}
+		return account, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Only an email both sides verified says the identity and the account
+	// belong to the same person
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, entity.ErrOIDCEmailNotVerified
+	}
+
+	account, err := ou.accounts.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && account.EmailVerifiedAt == nil {
+		return nil, entity.ErrOIDCAccountConflict
+	}
+	if account == nil {
+		if account, err = ou.createAccount(ctx, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = ou.repo.LinkIdentity(ctx, account.ID, identity.Issuer, identity.Subject); err != nil {
+		return nil, err
+	}
+
+	if err = ou.notifier.Send(ctx, linkedMessage(account, identity)); err != nil {
+		log.Printf("failed to tell %s about the linked identity: %v", account.Username, err)
+	}
+
+	return account, nil
+}
+
+// createAccount registers an account for identity, with a random password
+// the user can replace through a password reset.
+func (ou *OIDCUsecase) createAccount(ctx context.Context, identity *oidc.IDToken) (*entity.Account, error) {
+	username, err := ou.freeUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &entity.Account{
+		Name:     identity.Name,
+		Username: username,
+		Email:    identity.Email,
+		Password: string(hashedPassword),
+	}
+	if err = ou.accounts.Register(ctx, account); err != nil {
+		return nil, err
+	}
+	if _, err = ou.accounts.MarkEmailVerified(ctx, account.ID, account.Email); err != nil {
+		return nil, err
+	}
+
+	// Reloaded for the roles Register gave it
+	created, err := ou.accounts.GetByID(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, entity.ErrAccountNotFound
+	}
+
+	return created, nil
+}
+
+// freeUsername picks a username from the preferred username or the email,
+// numbered when it is taken.
+func (ou *OIDCUsecase) freeUsername(ctx context.Context, identity *oidc.IDToken) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	for i := 1; i <= 20; i++ {
+		candidate := base
+		if i > 1 {
+			candidate += strconv.Itoa(i)
+		}
+
+		existing, err := ou.accounts.GetByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+
+	suffix, err := randomToken(4)
+	if err != nil {
+		return "", err
+	}
+
+	return base + "-" + strings.ToLower(suffix), nil
+}
+
+func linkedMessage(account *entity.Account, identity *oidc.IDToken) notify.Message {
+	return notify.Message{
+		Event:   "identity_linked",
+		To:      account.Email,
+		Subject: "Sign-in with your identity provider was added",
+		Body: fmt.Sprintf("Your account %s can now be signed in to through %s. "+
+			"If this was not you, contact us.", account.Username, identity.Issuer),
+		Data: map[string]interface{}{
+			"issuer":  identity.Issuer,
+			"subject": identity.Subject,
+		},
+	}
+}
+
+// DeleteExpiredLogins is run by the scheduler to drop sign-ins that were
+// never finished.
+func (ou *OIDCUsecase) DeleteExpiredLogins(ctx context.Context) error {
+	deleted, err := ou.repo.DeleteExpiredLogins(ctx, ou.now())
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("oidc login cleanup: %d expired sign-ins deleted", deleted)
+	}
+
+	return nil
+}
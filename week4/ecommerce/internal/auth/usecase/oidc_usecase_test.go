@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	mock_repository "ecommerce/internal/auth/mocks"
+	"ecommerce/pkg/config"
+	"ecommerce/pkg/oidc"
+	"ecommerce/pkg/oidc/oidctest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type OIDCUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockRepo     *mock_repository.MockIOIDCRepository
+	mockAccounts *mock_repository.MockIAccountRepository
+	notifier     *recordingNotifier
+	server       *oidctest.Server
+	usecase      *OIDCUsecase
+}
+
+func (suite *OIDCUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIOIDCRepository(suite.mockCtrl)
+	suite.mockAccounts = mock_repository.NewMockIAccountRepository(suite.mockCtrl)
+	suite.notifier = &recordingNotifier{}
+
+	server, err := oidctest.NewServer()
+	suite.Require().NoError(err)
+	suite.server = server
+
+	cfg := config.NewOIDCConfig(server.Issuer(), oidctest.ClientID, oidctest.ClientSecret, oidctest.RedirectURL, nil)
+	suite.usecase = NewOIDCUsecase(oidc.NewProvider(cfg, nil), suite.mockRepo, suite.mockAccounts, suite.notifier)
+}
+
+func (suite *OIDCUsecaseTestSuite) TearDownTest() {
+	suite.server.Close()
+	suite.mockCtrl.Finish()
+}
+
+func TestOIDCUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(OIDCUsecaseTestSuite))
+}
+
+// signIn starts a sign-in, lets user sign in at the fake provider and
+// returns what the provider sends back. The sign-in is handed out once by
+// the mocked UseLogin.
+func (suite *OIDCUsecaseTestSuite) signIn(user oidctest.User) (state, code string) {
+	var login *entity.OIDCLogin
+	suite.mockRepo.EXPECT().CreateLogin(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, l *entity.OIDCLogin) error {
+			login = l
+			return nil
+		})
+
+	authURL, state, err := suite.usecase.StartLogin(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(hashToken(state), login.StateHash)
+
+	suite.server.SignInAs(user)
+	code, returnedState, err := suite.server.Authorize(authURL)
+	suite.Require().NoError(err)
+	suite.Require().Equal(state, returnedState)
+
+	suite.mockRepo.EXPECT().UseLogin(gomock.Any(), hashToken(state), gomock.Any()).Return(login, nil)
+	return state, code
+}
+
+func (suite *OIDCUsecaseTestSuite) TestCompleteLogin() {
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+	lockedAt := time.Now().Add(-time.Hour)
+	issuer := suite.server.Issuer()
+	alice := oidctest.User{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice", PreferredUsername: "Alice"}
+
+	testCases := []struct {
+		name             string
+		user             oidctest.User
+		mockBehavior     func()
+		expectedAccount  int
+		expectedError    error
+		expectedMessages int
+	}{
+		{
+			name: "Linked identity",
+			user: alice,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(3, nil)
+				suite.mockAccounts.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, Username: "alice"}, nil)
+			},
+			expectedAccount: 3,
+		},
+		{
+			name: "Links to the account with the same verified email",
+			user: alice,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(0, sql.ErrNoRows)
+				suite.mockAccounts.EXPECT().GetByEmail(gomock.Any(), "alice@example.com").
+					Return(&entity.Account{ID: 3, Username: "alice", Email: "alice@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+				suite.mockRepo.EXPECT().LinkIdentity(gomock.Any(), 3, issuer, "u-1").Return(nil)
+			},
+			expectedAccount:  3,
+			expectedMessages: 1,
+		},
+		{
+			name: "Creates an account with a free username",
+			user: alice,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(0, sql.ErrNoRows)
+				suite.mockAccounts.EXPECT().GetByEmail(gomock.Any(), "alice@example.com").Return(nil, nil)
+				suite.mockAccounts.EXPECT().GetByUsername(gomock.Any(), "alice").Return(&entity.Account{ID: 3}, nil)
+				suite.mockAccounts.EXPECT().GetByUsername(gomock.Any(), "alice2").Return(nil, nil)
+				suite.mockAccounts.EXPECT().Register(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, account *entity.Account) error {
+						suite.Equal("alice2", account.Username)
+						suite.Equal("Alice", account.Name)
+						suite.NotEmpty(account.Password)
+						account.ID = 8
+						return nil
+					})
+				suite.mockAccounts.EXPECT().MarkEmailVerified(gomock.Any(), 8, "alice@example.com").Return(true, nil)
+				suite.mockAccounts.EXPECT().GetByID(gomock.Any(), 8).
+					Return(&entity.Account{ID: 8, Username: "alice2", Email: "alice@example.com", Roles: []string{entity.RoleUser}}, nil)
+				suite.mockRepo.EXPECT().LinkIdentity(gomock.Any(), 8, issuer, "u-1").Return(nil)
+			},
+			expectedAccount:  8,
+			expectedMessages: 1,
+		},
+		{
+			name: "Email the provider has not verified",
+			user: oidctest.User{Subject: "u-1", Email: "alice@example.com"},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(0, sql.ErrNoRows)
+			},
+			expectedError: entity.ErrOIDCEmailNotVerified,
+		},
+		{
+			name: "Account with the email has not verified it",
+			user: alice,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(0, sql.ErrNoRows)
+				suite.mockAccounts.EXPECT().GetByEmail(gomock.Any(), "alice@example.com").
+					Return(&entity.Account{ID: 3, Username: "alice", Email: "alice@example.com"}, nil)
+			},
+			expectedError: entity.ErrOIDCAccountConflict,
+		},
+		{
+			name: "Locked account",
+			user: alice,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetLinkedAccountID(gomock.Any(), issuer, "u-1").Return(3, nil)
+				suite.mockAccounts.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Account{ID: 3, LockedAt: &lockedAt}, nil)
+			},
+			expectedError: entity.ErrAccountLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.notifier.sent = nil
+			state, code := suite.signIn(tc.user)
+			tc.mockBehavior()
+
+			account, err := suite.usecase.CompleteLogin(context.Background(), state, code)
+			if tc.expectedError != nil {
+				suite.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			suite.NoError(err)
+			suite.Equal(tc.expectedAccount, account.ID)
+			suite.Len(suite.notifier.sent, tc.expectedMessages)
+		})
+	}
+}
+
+func (suite *OIDCUsecaseTestSuite) TestCompleteLoginRejectsBadCallbacks() {
+	suite.Run("Unknown or used state", func() {
+		suite.mockRepo.EXPECT().UseLogin(gomock.Any(), hashToken("state"), gomock.Any()).Return(nil, sql.ErrNoRows)
+
+		_, err := suite.usecase.CompleteLogin(context.Background(), "state", "code")
+		suite.ErrorIs(err, entity.ErrInvalidOIDCState)
+	})
+
+	suite.Run("Code from another sign-in", func() {
+		state, _ := suite.signIn(oidctest.User{Subject: "u-1"})
+
+		_, err := suite.usecase.CompleteLogin(context.Background(), state, "forged")
+		suite.ErrorIs(err, entity.ErrOIDCSignInFailed)
+	})
+
+	suite.Run("Not configured", func() {
+		_, err := NewOIDCUsecase(nil, suite.mockRepo, suite.mockAccounts, suite.notifier).
+			CompleteLogin(context.Background(), "state", "code")
+		suite.ErrorIs(err, entity.ErrOIDCDisabled)
+	})
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/internal/auth/repository"
+	"ecommerce/pkg/rbac"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var roleName = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// RoleUsecase manages roles and the permissions they grant. Changes reach
+// an account's tokens on its next login or refresh, so within
+// AccessTokenTTL.
+type RoleUsecase struct {
+	repo repository.IRoleRepository
+}
+
+func NewRoleUsecase(repo repository.IRoleRepository) *RoleUsecase {
+	return &RoleUsecase{
+		repo: repo,
+	}
+}
+
+func (ru *RoleUsecase) GetPermissions() []rbac.Permission {
+	return rbac.Permissions
+}
+
+func (ru *RoleUsecase) GetAllRoles(ctx context.Context) ([]*entity.Role, error) {
+	return ru.repo.GetAll(ctx)
+}
+
+func (ru *RoleUsecase) CreateRole(ctx context.Context, role *entity.Role) error {
+	role.Name = strings.TrimSpace(role.Name)
+	if !roleName.MatchString(role.Name) {
+		return entity.ErrInvalidRoleName
+	}
+
+	permissions, err := validPermissions(role.Permissions)
+	if err != nil {
+		return err
+	}
+	role.Permissions = permissions
+
+	return ru.repo.Create(ctx, role)
+}
+
+// UpdateRole replaces the description and permissions of a role. Its name
+// is fixed, as it is what accounts and the built-in roles refer to.
+func (ru *RoleUsecase) UpdateRole(ctx context.Context, role *entity.Role) (*entity.Role, error) {
+	permissions, err := validPermissions(role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+
+	existing, err := ru.repo.GetByID(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Otherwise nobody might be left to undo it
+	if existing.Name == entity.RoleAdmin && !containsString(permissions, rbac.RoleManage) {
+		return nil, entity.ErrAdminLockout
+	}
+
+	if err = ru.repo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return ru.repo.GetByID(ctx, role.ID)
+}
+
+func (ru *RoleUsecase) DeleteRole(ctx context.Context, id int) error {
+	role, err := ru.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.Name == entity.RoleUser || role.Name == entity.RoleAdmin {
+		return entity.ErrBuiltInRole
+	}
+
+	return ru.repo.Delete(ctx, id)
+}
+
+func validPermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	valid := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !rbac.Valid(permission) {
+			return nil, entity.ErrUnknownPermission
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			valid = append(valid, permission)
+		}
+	}
+
+	sort.Strings(valid)
+	return valid, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
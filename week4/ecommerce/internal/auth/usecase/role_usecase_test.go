@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/entity"
+	mock_repository "ecommerce/internal/auth/mocks"
+	"ecommerce/pkg/rbac"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RoleUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockRepo    *mock_repository.MockIRoleRepository
+	roleUsecase *RoleUsecase
+}
+
+func (suite *RoleUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIRoleRepository(suite.mockCtrl)
+	suite.roleUsecase = NewRoleUsecase(suite.mockRepo)
+}
+
+func (suite *RoleUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestRoleUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(RoleUsecaseTestSuite))
+}
+
+func (suite *RoleUsecaseTestSuite) TestCreateRole() {
+	testCases := []struct {
+		name          string
+		role          *entity.Role
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Creates with sorted unique permissions",
+			role: &entity.Role{Name: "support", Permissions: []string{rbac.OrderReadAny, rbac.ReviewModerate, rbac.OrderReadAny}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, role *entity.Role) error {
+						suite.Equal([]string{rbac.OrderReadAny, rbac.ReviewModerate}, role.Permissions)
+						return nil
+					})
+			},
+		},
+		{
+			name:          "Invalid name",
+			role:          &entity.Role{Name: "Support Team"},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidRoleName,
+		},
+		{
+			name:          "Unknown permission",
+			role:          &entity.Role{Name: "support", Permissions: []string{"order:refund"}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrUnknownPermission,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			suite.ErrorIs(suite.roleUsecase.CreateRole(context.Background(), tc.role), tc.expectedError)
+		})
+	}
+}
+
+func (suite *RoleUsecaseTestSuite) TestUpdateAdminRole() {
+	admin := &entity.Role{ID: 2, Name: entity.RoleAdmin}
+
+	suite.Run("Keeps role:manage", func() {
+		suite.mockRepo.EXPECT().GetByID(gomock.Any(), 2).Return(admin, nil)
+
+		_, err := suite.roleUsecase.UpdateRole(context.Background(), &entity.Role{ID: 2, Permissions: []string{rbac.ProductWrite}})
+		suite.ErrorIs(err, entity.ErrAdminLockout)
+	})
+
+	suite.Run("Updates", func() {
+		suite.mockRepo.EXPECT().GetByID(gomock.Any(), 2).Return(admin, nil).Times(2)
+		suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := suite.roleUsecase.UpdateRole(context.Background(), &entity.Role{ID: 2, Permissions: []string{rbac.RoleManage}})
+		suite.NoError(err)
+	})
+}
+
+func (suite *RoleUsecaseTestSuite) TestDeleteRole() {
+	suite.Run("Built-in role", func() {
+		suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Role{ID: 1, Name: entity.RoleUser}, nil)
+
+		suite.ErrorIs(suite.roleUsecase.DeleteRole(context.Background(), 1), entity.ErrBuiltInRole)
+	})
+
+	suite.Run("Custom role", func() {
+		suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Role{ID: 3, Name: "support"}, nil)
+		suite.mockRepo.EXPECT().Delete(gomock.Any(), 3).Return(nil)
+
+		suite.NoError(suite.roleUsecase.DeleteRole(context.Background(), 3))
+	})
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/auth/repository"
+	"ecommerce/pkg/utils"
+	"log"
+	"time"
+)
+
+// KeyPublishLead is how long a new signing key is published before it
+// signs anything. It has to cover the key reload interval of every instance
+// and how long other services cache the JWKS.
+const KeyPublishLead = time.Hour
+
+// SigningKeyUsecase keeps the access token signing keys rotated and loaded.
+type SigningKeyUsecase struct {
+	repo      repository.ISigningKeyRepository
+	algorithm string
+	rotation  time.Duration
+	now       func() time.Time
+}
+
+// NewSigningKeyUsecase rotates to a new algorithm key every rotation.
+// rotation must be longer than KeyPublishLead.
+func NewSigningKeyUsecase(repo repository.ISigningKeyRepository, algorithm string, rotation time.Duration) *SigningKeyUsecase {
+	return &SigningKeyUsecase{
+		repo:      repo,
+		algorithm: algorithm,
+		rotation:  rotation,
+		now:       time.Now,
+	}
+}
+
+// RotateKeys adds the next signing key when it is due, deletes keys that
+// can no longer verify a live token and loads the result for signing. It
+// runs at startup and then on a schedule, which is also how an instance
+// learns about keys added by another one.
+func (u *SigningKeyUsecase) RotateKeys(ctx context.Context) error {
+	keys, err := u.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := u.now()
+	var newest time.Time
+	if len(keys) > 0 {
+		newest = keys[len(keys)-1].ActiveAt
+	}
+
+	if len(keys) == 0 || !now.Before(newest.Add(u.rotation-KeyPublishLead)) {
+		if err = u.addKey(ctx, now, newest, len(keys) == 0); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := u.repo.DeleteRetired(ctx, now.Add(-utils.AccessTokenTTL))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("signing keys: %d retired keys deleted", deleted)
+	}
+
+	keys, err = u.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	utils.SetSigningKeys(keys...)
+	return nil
+}
+
+// addKey creates the successor of the key active at newest. The first key
+// signs straight away; later ones wait at least KeyPublishLead so they are
+// known everywhere first.
+func (u *SigningKeyUsecase) addKey(ctx context.Context, now, newest time.Time, first bool) error {
+	activeAt := now
+	if !first {
+		activeAt = newest.Add(u.rotation)
+		if lead := now.Add(KeyPublishLead); activeAt.Before(lead) {
+			activeAt = lead
+		}
+	}
+
+	key, err := utils.GenerateSigningKey(u.algorithm, activeAt)
+	if err != nil {
+		return err
+	}
+
+	created, err := u.repo.Create(ctx, key, newest)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Printf("signing keys: key %s added, active from %s", key.ID, activeAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	mock_repository "ecommerce/internal/auth/mocks"
+	"ecommerce/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type SigningKeyUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	mockRepo *mock_repository.MockISigningKeyRepository
+	usecase  *SigningKeyUsecase
+	now      time.Time
+}
+
+func (suite *SigningKeyUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockISigningKeyRepository(suite.mockCtrl)
+	suite.usecase = NewSigningKeyUsecase(suite.mockRepo, utils.AlgEdDSA, 30*24*time.Hour)
+	suite.now = time.Now().Truncate(time.Second)
+	suite.usecase.now = func() time.Time { return suite.now }
+}
+
+func (suite *SigningKeyUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestSigningKeyUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(SigningKeyUsecaseTestSuite))
+}
+
+func (suite *SigningKeyUsecaseTestSuite) key(activeAt time.Time) *utils.SigningKey {
+	key, err := utils.GenerateSigningKey(utils.AlgEdDSA, activeAt)
+	suite.Require().NoError(err)
+	return key
+}
+
+func (suite *SigningKeyUsecaseTestSuite) TestRotateKeys() {
+	testCases := []struct {
+		name string
+		// ActiveAt of the stored keys, relative to now
+		stored []time.Duration
+		// ActiveAt of the key expected to be added, relative to now
+		added *time.Duration
+	}{
+		{
+			name:  "First key signs immediately",
+			added: durationPtr(0),
+		},
+		{
+			name:   "Current key is not due",
+			stored: []time.Duration{-30*24*time.Hour + KeyPublishLead + time.Minute},
+		},
+		{
+			name:   "Successor is published ahead of the rotation",
+			stored: []time.Duration{-30*24*time.Hour + KeyPublishLead},
+			added:  durationPtr(KeyPublishLead),
+		},
+		{
+			name:   "Overdue rotation still waits for the publish lead",
+			stored: []time.Duration{-60 * 24 * time.Hour},
+			added:  durationPtr(KeyPublishLead),
+		},
+		{
+			name:   "Successor already published",
+			stored: []time.Duration{-30 * 24 * time.Hour, 30 * time.Minute},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var keys []*utils.SigningKey
+			for _, offset := range tc.stored {
+				keys = append(keys, suite.key(suite.now.Add(offset)))
+			}
+
+			suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(keys, nil).Times(2)
+			if tc.added != nil {
+				var newest time.Time
+				if len(keys) > 0 {
+					newest = keys[len(keys)-1].ActiveAt
+				}
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any(), newest).
+					DoAndReturn(func(_ context.Context, key *utils.SigningKey, _ time.Time) (bool, error) {
+						suite.Equal(suite.now.Add(*tc.added), key.ActiveAt)
+						suite.Equal(utils.AlgEdDSA, key.Algorithm)
+						return true, nil
+					})
+			}
+			suite.mockRepo.EXPECT().DeleteRetired(gomock.Any(), suite.now.Add(-utils.AccessTokenTTL)).Return(int64(0), nil)
+
+			suite.NoError(suite.usecase.RotateKeys(context.Background()))
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/utils"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+)
+
+// IssueTokens starts a new session for a logged in account with an access
+// token and the first refresh token of a new family.
+func (u *AccountUsecase) IssueTokens(ctx context.Context, account *entity.Account) (*entity.TokenPair, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(ctx, account, familyID)
+}
+
+func (u *AccountUsecase) issueTokens(ctx context.Context, account *entity.Account, familyID string) (*entity.TokenPair, error) {
+	permissions, setupRequired := u.tokenPermissions(account)
+	accessToken, claims, err := utils.GenerateJWT(account.Username, account.Role, account.Roles, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repo.CreateRefreshToken(ctx, &entity.RefreshToken{
+		AccountID:       account.ID,
+		FamilyID:        familyID,
+		TokenHash:       hashToken(refreshToken),
+		AccessJTI:       claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       u.now().Add(entity.RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.TokenPair{
+		Token:        accessToken,
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+
+		TwoFactorSetupRequired: setupRequired,
+	}, nil
+}
+
+// Refresh trades a refresh token for a new access and refresh token. Each
+// refresh token works once: presenting a used one means it was copied, so
+// the whole family is revoked and the legitimate holder has to log in again.
+func (u *AccountUsecase) Refresh(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	stored, err := u.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, u.revokeReused(ctx, stored)
+	}
+	if !u.now().Before(stored.ExpiresAt) {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	// Two requests racing with the same token count as reuse as well
+	ok, err := u.repo.UseRefreshToken(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, u.revokeReused(ctx, stored)
+	}
+
+	// The account may have been deleted or locked since the login
+	account, err := u.repo.GetByUsername(ctx, stored.Username)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.LockedAt != nil {
+		if err = u.repo.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	return u.issueTokens(ctx, account, stored.FamilyID)
+}
+
+func (u *AccountUsecase) revokeReused(ctx context.Context, stored *entity.RefreshToken) error {
+	log.Printf("refresh token reuse for %s, revoking token family", stored.Username)
+
+	if err := u.repo.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+		return err
+	}
+
+	return entity.ErrRefreshTokenReused
+}
+
+// Logout ends the session of refreshToken and revokes the access token in
+// claims. Either may be missing; an unknown refresh token is ignored so
+// logging out twice is not an error.
+func (u *AccountUsecase) Logout(ctx context.Context, refreshToken string, claims *utils.Claims) error {
+	if refreshToken != "" {
+		stored, err := u.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if stored != nil {
+			if err = u.repo.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if claims != nil && claims.ID != "" && claims.ExpiresAt != nil {
+		return u.repo.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time)
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether the access token with the given ID was revoked
+// by a logout or a detected refresh token reuse.
+func (u *AccountUsecase) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return u.repo.IsAccessTokenRevoked(ctx, jti)
+}
+
+// DeleteExpiredTokens is run by the scheduler to drop tokens that can no
+// longer be presented.
+func (u *AccountUsecase) DeleteExpiredTokens(ctx context.Context) error {
+	deleted, err := u.repo.DeleteExpiredTokens(ctx, u.now())
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("token cleanup: %d expired tokens deleted", deleted)
+	}
+
+	return nil
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what is stored for a refresh token. The token is random, so
+// an unsalted fast hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *AccountUsecaseTestSuite) TestRefresh() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	key, err := utils.GenerateSigningKey(utils.AlgEdDSA, time.Now().Add(-time.Hour))
+	suite.Require().NoError(err)
+	utils.SetSigningKeys(key)
+
+	used := now.Add(-time.Minute)
+	stored := func() *entity.RefreshToken {
+		return &entity.RefreshToken{
+			ID:        7,
+			AccountID: 3,
+			Username:  "testuser",
+			FamilyID:  "family",
+			ExpiresAt: now.Add(time.Hour),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Rotates the token within its family",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), hashToken("token")).Return(stored(), nil)
+				suite.mockRepo.EXPECT().UseRefreshToken(gomock.Any(), 7).Return(true, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").
+					Return(&entity.Account{ID: 3, Username: "testuser", Role: "user"}, nil)
+				suite.mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, token *entity.RefreshToken) error {
+						suite.Equal("family", token.FamilyID)
+						suite.Equal(3, token.AccountID)
+						suite.NotEqual(hashToken("token"), token.TokenHash)
+						suite.Equal(now.Add(entity.RefreshTokenTTL), token.ExpiresAt)
+						return nil
+					})
+			},
+		},
+		{
+			name: "Unknown token",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: entity.ErrInvalidRefreshToken,
+		},
+		{
+			name: "Expired token",
+			mockBehavior: func() {
+				token := stored()
+				token.ExpiresAt = now
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(token, nil)
+			},
+			expectedError: entity.ErrInvalidRefreshToken,
+		},
+		{
+			name: "Reused token revokes the family",
+			mockBehavior: func() {
+				token := stored()
+				token.UsedAt = &used
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(token, nil)
+				suite.mockRepo.EXPECT().RevokeTokenFamily(gomock.Any(), "family").Return(nil)
+			},
+			expectedError: entity.ErrRefreshTokenReused,
+		},
+		{
+			name: "Concurrent use revokes the family",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(stored(), nil)
+				suite.mockRepo.EXPECT().UseRefreshToken(gomock.Any(), 7).Return(false, nil)
+				suite.mockRepo.EXPECT().RevokeTokenFamily(gomock.Any(), "family").Return(nil)
+			},
+			expectedError: entity.ErrRefreshTokenReused,
+		},
+		{
+			name: "Deleted account",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(stored(), nil)
+				suite.mockRepo.EXPECT().UseRefreshToken(gomock.Any(), 7).Return(true, nil)
+				suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "testuser").Return(nil, nil)
+				suite.mockRepo.EXPECT().RevokeTokenFamily(gomock.Any(), "family").Return(nil)
+			},
+			expectedError: entity.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			tokens, err := suite.accountUsecase.Refresh(context.Background(), "token")
+			if tc.expectedError != nil {
+				suite.ErrorIs(err, tc.expectedError)
+				suite.Nil(tokens)
+			} else {
+				suite.NoError(err)
+				suite.NotEmpty(tokens.AccessToken)
+				suite.NotEmpty(tokens.RefreshToken)
+				suite.NotEqual("token", tokens.RefreshToken)
+			}
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestLogout() {
+	expiresAt := time.Date(2024, 6, 1, 12, 15, 0, 0, time.UTC)
+	claims := &utils.Claims{
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		refreshToken string
+		claims       *utils.Claims
+		mockBehavior func()
+	}{
+		{
+			name:         "Revokes the session and the access token",
+			refreshToken: "token",
+			claims:       claims,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), hashToken("token")).
+					Return(&entity.RefreshToken{ID: 7, FamilyID: "family"}, nil)
+				suite.mockRepo.EXPECT().RevokeTokenFamily(gomock.Any(), "family").Return(nil)
+				suite.mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), "jti", expiresAt).Return(nil)
+			},
+		},
+		{
+			name:         "Unknown refresh token is ignored",
+			refreshToken: "token",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+			},
+		},
+		{
+			name:   "Access token only",
+			claims: claims,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), "jti", expiresAt).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			suite.NoError(suite.accountUsecase.Logout(context.Background(), tc.refreshToken, tc.claims))
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/totp"
+	"encoding/base32"
+	"errors"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultTOTPIssuer = "ecommerce"
+
+// EnrolTwoFactor creates a new TOTP secret for the account. It is not asked
+// for at login until ConfirmTwoFactor proves the app was set up with it.
+func (u *AccountUsecase) EnrolTwoFactor(ctx context.Context, username string) (*entity.TwoFactorEnrolment, error) {
+	account, err := u.accountByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if account.TwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repo.SetTOTPSecret(ctx, account.ID, secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+
+	return &entity.TwoFactorEnrolment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(issuer, account.Username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with the first code
+// from the app and returns the recovery codes, which are only shown now.
+func (u *AccountUsecase) ConfirmTwoFactor(ctx context.Context, username, code string) ([]string, error) {
+	account, err := u.accountByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if account.TwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorEnabled
+	}
+	if account.TOTPSecret == "" {
+		return nil, entity.ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(account.TOTPSecret, normalizeCode(code), u.now(), -1)
+	if !ok {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repo.EnableTOTP(ctx, account.ID, step, hashes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off. It takes the
+// password and a code, so a stolen session alone cannot do it.
+func (u *AccountUsecase) DisableTwoFactor(ctx context.Context, username, password, code string) error {
+	account, err := u.accountByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !account.TwoFactorEnabled() {
+		return entity.ErrTwoFactorNotEnabled
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
+		return entity.ErrWrongPassword
+	}
+
+	ok, err := u.checkSecondFactor(ctx, account, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	return u.repo.DisableTOTP(ctx, account.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, for when they run out
+// or leaked. It takes a code from the app, not a recovery code.
+func (u *AccountUsecase) RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error) {
+	account, err := u.accountByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !account.TwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorNotEnabled
+	}
+
+	ok, err := u.useTOTPCode(ctx, account, normalizeCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.repo.ReplaceRecoveryCodes(ctx, account.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// ResetTwoFactor turns two-factor authentication off for an account holder
+// who lost their app and recovery codes, and signs them out everywhere.
+func (u *AccountUsecase) ResetTwoFactor(ctx context.Context, actor string, id int) error {
+	account, err := u.otherAccount(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err = u.repo.DisableTOTP(ctx, account.ID); err != nil {
+		return err
+	}
+
+	return u.repo.RevokeAccountTokens(ctx, account.ID)
+}
+
+// CreateLoginChallenge is the first step of logging in to an account with
+// two-factor authentication, made once the password was right.
+func (u *AccountUsecase) CreateLoginChallenge(ctx context.Context, account *entity.Account) (*entity.LoginChallenge, error) {
+	token, err := u.createAccountToken(ctx, account, entity.PurposeLoginChallenge, entity.LoginChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.LoginChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int(entity.LoginChallengeTTL.Seconds()),
+	}, nil
+}
+
+// CompleteLogin exchanges a login challenge and a code from the app, or a
+// recovery code, for tokens. A wrong code counts as a failed login, but
+// leaves the challenge usable until it expires.
+func (u *AccountUsecase) CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*entity.TokenPair, error) {
+	challengeHash := hashToken(challengeToken)
+	challenge, err := u.repo.GetAccountToken(ctx, entity.PurposeLoginChallenge, challengeHash, u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := u.repo.GetByID(ctx, challenge.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || !account.TwoFactorEnabled() {
+		return nil, entity.ErrInvalidLoginChallenge
+	}
+
+	attempts, err := u.startLogin(ctx, loginKeys(account.Username, ip))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := u.checkSecondFactor(ctx, account, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		u.recordLoginFailure(ctx, attempts)
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	// Used only now, and atomically, so a challenge gives one session
+	_, err = u.repo.UseAccountToken(ctx, entity.PurposeLoginChallenge, challengeHash, u.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.finishLogin(ctx, attempts, accountLoginKey(account.Username)); err != nil {
+		return nil, err
+	}
+	if account.LockedAt != nil {
+		return nil, entity.ErrAccountLocked
+	}
+
+	return u.IssueTokens(ctx, account)
+}
+
+// tokenPermissions are the permissions put in the account's tokens. When
+// two-factor authentication is required for elevated permissions, accounts
+// without it only get the others, and setupRequired is set.
+func (u *AccountUsecase) tokenPermissions(account *entity.Account) (permissions []string, setupRequired bool) {
+	if !u.requireTwoFactor || account.TwoFactorEnabled() {
+		return account.Permissions, false
+	}
+
+	permissions = []string{}
+	for _, permission := range account.Permissions {
+		if rbac.Elevated(permission) {
+			setupRequired = true
+			continue
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, setupRequired
+}
+
+// checkSecondFactor accepts a code from the app or an unused recovery code.
+func (u *AccountUsecase) checkSecondFactor(ctx context.Context, account *entity.Account, code string) (bool, error) {
+	code = normalizeCode(code)
+	if code == "" {
+		return false, nil
+	}
+	if len(code) == totp.Digits {
+		return u.useTOTPCode(ctx, account, code)
+	}
+
+	return u.repo.UseRecoveryCode(ctx, account.ID, hashToken(code))
+}
+
+func (u *AccountUsecase) useTOTPCode(ctx context.Context, account *entity.Account, code string) (bool, error) {
+	step, ok := totp.Validate(account.TOTPSecret, code, u.now(), account.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	return u.repo.UseTOTPStep(ctx, account.ID, step)
+}
+
+func (u *AccountUsecase) accountByUsername(ctx context.Context, username string) (*entity.Account, error) {
+	account, err := u.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, entity.ErrAccountNotFound
+	}
+
+	return account, nil
+}
+
+// normalizeCode lets codes be typed with spaces or dashes and in any case.
+func normalizeCode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes returns codes like
+// "k3j7d-x7q2m-4hpa2-9vrtc-6ewq3-ybn5f" and the hashes that are stored for
+// them. 150 random bits make the codes as hard to guess as tokens, so the
+// fast hash of hashToken is enough.
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < entity.RecoveryCodeCount; i++ {
+		raw := make([]byte, 19)
+		if _, err = rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:30]
+		groups := make([]string, 0, 6)
+		for j := 0; j < len(code); j += 5 {
+			groups = append(groups, code[j:j+5])
+		}
+		codes = append(codes, strings.Join(groups, "-"))
+		hashes = append(hashes, hashToken(code))
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/auth/entity"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/totp"
+	"ecommerce/pkg/utils"
+	"strings"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func (suite *AccountUsecaseTestSuite) totpCode(t time.Time) string {
+	code, err := totp.Code(testTOTPSecret, totp.Step(t))
+	suite.Require().NoError(err)
+	return code
+}
+
+// recordAttempts counts a login with no earlier failures under both keys.
+func (suite *AccountUsecaseTestSuite) recordAttempts(now time.Time) {
+	suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), now, gomock.Any()).
+		Return(&entity.LoginAttempts{LastFailureAt: now}, nil).Times(2)
+}
+
+func (suite *AccountUsecaseTestSuite) TestConfirmTwoFactor() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+	enabledAt := now.Add(-time.Hour)
+	var stored []string
+
+	testCases := []struct {
+		name          string
+		account       *entity.Account
+		code          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:    "Enables with the first code",
+			account: &entity.Account{ID: 3, Username: "alice", TOTPSecret: testTOTPSecret, TOTPLastStep: -1},
+			code:    suite.totpCode(now),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().EnableTOTP(gomock.Any(), 3, totp.Step(now), gomock.Len(entity.RecoveryCodeCount)).
+					DoAndReturn(func(_ context.Context, _ int, _ int64, hashes []string) error {
+						stored = hashes
+						return nil
+					})
+			},
+		},
+		{
+			name:          "Not enrolled",
+			account:       &entity.Account{ID: 3, Username: "alice", TOTPLastStep: -1},
+			code:          "123456",
+			mockBehavior:  func() {},
+			expectedError: entity.ErrTwoFactorNotEnrolled,
+		},
+		{
+			name:          "Wrong code",
+			account:       &entity.Account{ID: 3, Username: "alice", TOTPSecret: testTOTPSecret, TOTPLastStep: -1},
+			code:          suite.totpCode(now.Add(-time.Hour)),
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:          "Already enabled",
+			account:       &entity.Account{ID: 3, Username: "alice", TOTPSecret: testTOTPSecret, TwoFactorEnabledAt: &enabledAt},
+			code:          suite.totpCode(now),
+			mockBehavior:  func() {},
+			expectedError: entity.ErrTwoFactorEnabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(tc.account, nil)
+			tc.mockBehavior()
+
+			codes, err := suite.accountUsecase.ConfirmTwoFactor(context.Background(), "alice", tc.code)
+			if tc.expectedError != nil {
+				suite.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			suite.NoError(err)
+			suite.Require().Len(codes, entity.RecoveryCodeCount)
+
+			// Codes carry 150 random bits and are stored by the hash of
+			// what checkSecondFactor looks up
+			for i, code := range codes {
+				suite.Regexp(`^[a-z2-7]{5}(-[a-z2-7]{5}){5}$`, code)
+				suite.Equal(hashToken(normalizeCode(strings.ToUpper(code))), stored[i])
+			}
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestCompleteLogin() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	key, err := utils.GenerateSigningKey(utils.AlgEdDSA, time.Now().Add(-time.Hour))
+	suite.Require().NoError(err)
+	utils.SetSigningKeys(key)
+
+	enabledAt := now.Add(-time.Hour)
+	account := func() *entity.Account {
+		return &entity.Account{
+			ID:                 3,
+			Username:           "alice",
+			Permissions:        []string{rbac.OrderCreate},
+			TOTPSecret:         testTOTPSecret,
+			TOTPLastStep:       -1,
+			TwoFactorEnabledAt: &enabledAt,
+		}
+	}
+	challengeHash := hashToken("challenge")
+	challenge := &entity.AccountToken{ID: 9, AccountID: 3, Purpose: entity.PurposeLoginChallenge, TokenHash: challengeHash}
+
+	const accountKey, ipKey = "account:alice", "ip:203.0.113.7"
+
+	testCases := []struct {
+		name          string
+		code          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Code from the app",
+			code: suite.totpCode(now),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(account(), nil)
+				suite.recordAttempts(now)
+				suite.mockRepo.EXPECT().UseTOTPStep(gomock.Any(), 3, totp.Step(now)).Return(true, nil)
+				suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				suite.mockAttempts.EXPECT().Reset(gomock.Any(), accountKey).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+				suite.mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Recovery code, typed in upper case",
+			code: "K3J7D-X7Q2M-4HPA2-9VRTC-6EWQ3-YBN5F",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(account(), nil)
+				suite.recordAttempts(now)
+				suite.mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), 3, hashToken("k3j7dx7q2m4hpa29vrtc6ewq3ybn5f")).Return(true, nil)
+				suite.mockRepo.EXPECT().UseAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				suite.mockAttempts.EXPECT().Reset(gomock.Any(), accountKey).Return(nil)
+				suite.mockAttempts.EXPECT().Forgive(gomock.Any(), ipKey, now, now).Return(nil)
+				suite.mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Wrong code counts as a failed login and keeps the challenge",
+			code: suite.totpCode(now.Add(-time.Hour)),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(account(), nil)
+				suite.recordAttempts(now)
+			},
+			expectedError: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Code already used",
+			code: suite.totpCode(now),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(challenge, nil)
+				used := account()
+				used.TOTPLastStep = totp.Step(now)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(used, nil)
+				suite.recordAttempts(now)
+			},
+			expectedError: entity.ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Expired or used challenge",
+			code: suite.totpCode(now),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetAccountToken(gomock.Any(), entity.PurposeLoginChallenge, challengeHash, now).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: entity.ErrInvalidLoginChallenge,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			tokens, err := suite.accountUsecase.CompleteLogin(context.Background(), "challenge", tc.code, "203.0.113.7")
+			if tc.expectedError != nil {
+				suite.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			suite.NoError(err)
+			suite.NotEmpty(tokens.AccessToken)
+			suite.False(tokens.TwoFactorSetupRequired)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestLoginWithTwoFactorKeepsThrottle() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	enabledAt := time.Now().Add(-time.Hour)
+	account := &entity.Account{ID: 3, Username: "alice", Password: string(hash), TwoFactorEnabledAt: &enabledAt}
+
+	// No Reset of the account's failures until the code is right, only
+	// the attempt is taken back
+	suite.mockAttempts.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&entity.LoginAttempts{}, nil).Times(2)
+	suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+	suite.mockAttempts.EXPECT().Forgive(gomock.Any(), "account:alice", gomock.Any(), gomock.Any()).Return(nil)
+	suite.mockAttempts.EXPECT().Forgive(gomock.Any(), "ip:203.0.113.7", gomock.Any(), gomock.Any()).Return(nil)
+
+	result, err := suite.accountUsecase.Login(context.Background(), "alice", "password123", "203.0.113.7")
+	suite.NoError(err)
+	suite.True(result.TwoFactorEnabled())
+}
+
+func (suite *AccountUsecaseTestSuite) TestTokenPermissions() {
+	enabledAt := time.Now()
+	permissions := []string{rbac.OrderCreate, rbac.PriceWrite}
+
+	testCases := []struct {
+		name                string
+		require             bool
+		account             *entity.Account
+		expectedPermissions []string
+		expectedSetup       bool
+	}{
+		{
+			name:                "Not required",
+			account:             &entity.Account{Permissions: permissions},
+			expectedPermissions: permissions,
+		},
+		{
+			name:                "Required and enabled",
+			require:             true,
+			account:             &entity.Account{Permissions: permissions, TwoFactorEnabledAt: &enabledAt},
+			expectedPermissions: permissions,
+		},
+		{
+			name:                "Required but not enabled drops elevated permissions",
+			require:             true,
+			account:             &entity.Account{Permissions: permissions},
+			expectedPermissions: []string{rbac.OrderCreate},
+			expectedSetup:       true,
+		},
+		{
+			name:                "Customers are not asked to set it up",
+			require:             true,
+			account:             &entity.Account{Permissions: []string{rbac.OrderCreate, rbac.WishlistWrite}},
+			expectedPermissions: []string{rbac.OrderCreate, rbac.WishlistWrite},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			u := suite.accountUsecase.(*AccountUsecase)
+			u.requireTwoFactor = tc.require
+
+			got, setup := u.tokenPermissions(tc.account)
+			suite.Equal(tc.expectedPermissions, got)
+			suite.Equal(tc.expectedSetup, setup)
+		})
+	}
+}
+
+func (suite *AccountUsecaseTestSuite) TestDisableTwoFactor() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.accountUsecase.(*AccountUsecase).now = func() time.Time { return now }
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	suite.Require().NoError(err)
+	enabledAt := now.Add(-time.Hour)
+	account := &entity.Account{
+		ID:                 3,
+		Username:           "alice",
+		Password:           string(hash),
+		TOTPSecret:         testTOTPSecret,
+		TOTPLastStep:       -1,
+		TwoFactorEnabledAt: &enabledAt,
+	}
+
+	testCases := []struct {
+		name          string
+		password      string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:     "Password and code",
+			password: "password123",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UseTOTPStep(gomock.Any(), 3, totp.Step(now)).Return(true, nil)
+				suite.mockRepo.EXPECT().DisableTOTP(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name:          "Wrong password",
+			password:      "guess",
+			mockBehavior:  func() {},
+			expectedError: entity.ErrWrongPassword,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.mockRepo.EXPECT().GetByUsername(gomock.Any(), "alice").Return(account, nil)
+			tc.mockBehavior()
+
+			err := suite.accountUsecase.DisableTwoFactor(context.Background(), "alice", tc.password, suite.totpCode(now))
+			suite.ErrorIs(err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DownloadTTL is how long a download grant stays valid after purchase.
+	DownloadTTL = 72 * time.Hour
+	// DownloadLimit is how many times one order line can be downloaded.
+	DownloadLimit = 5
+)
+
+var (
+	ErrNotDigitalReady  = errors.New("only products without stock that are not bundles or bundle components can become digital")
+	ErrNoLicenceKeys    = errors.New("no licence keys given")
+	ErrNoDownloadFile   = errors.New("a file is required")
+	ErrInvalidLink      = errors.New("download link is invalid or has expired")
+	ErrDownloadUsedUp   = errors.New("download grant has expired or reached its download limit")
+	ErrNoLicenceKeyLeft = errors.New("not enough licence keys left")
+)
+
+// DownloadGrant allows downloading the file of one order line until
+// ExpiresAt, at most MaxDownloads times. URL is a signed link to it.
+type DownloadGrant struct {
+	ID            int       `json:"id"`
+	OrderLineID   int       `json:"order_line_id"`
+	ProductID     int       `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	MaxDownloads  int       `json:"max_downloads"`
+	DownloadCount int       `json:"download_count"`
+	URL           string    `json:"url,omitempty"`
+}
+
+// Usable reports whether the grant can still be downloaded at now.
+func (g *DownloadGrant) Usable(now time.Time) bool {
+	return now.Before(g.ExpiresAt) && g.DownloadCount < g.MaxDownloads
+}
+
+// AssignedKey is a licence key handed out for an order line.
+type AssignedKey struct {
+	OrderLineID int       `json:"order_line_id"`
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Key         string    `json:"licence_key"`
+	AssignedAt  time.Time `json:"assigned_at"`
+}
+
+// Delivery is everything digital an order delivers.
+type Delivery struct {
+	OrderID     int              `json:"order_id"`
+	Downloads   []*DownloadGrant `json:"downloads"`
+	LicenceKeys []*AssignedKey   `json:"licence_keys"`
+}
+
+// KeyPool counts the licence keys of a product.
+type KeyPool struct {
+	ProductID int `json:"product_id"`
+	Available int `json:"available"`
+	Assigned  int `json:"assigned"`
+	Added     int `json:"added,omitempty"`
+}
+
+// File is a stored download.
+type File struct {
+	Key  string
+	Name string
+}
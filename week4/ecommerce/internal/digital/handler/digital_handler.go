@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"ecommerce/internal/digital/entity"
+	"ecommerce/internal/digital/usecase"
+	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/policy"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DigitalHandler struct {
+	uc *usecase.DigitalUsecase
+}
+
+func NewDigitalHandler(uc *usecase.DigitalUsecase) *DigitalHandler {
+	return &DigitalHandler{
+		uc: uc,
+	}
+}
+
+func digitalError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrNoLicenceKeys),
+		errors.Is(err, entity.ErrNoDownloadFile):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidLink):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrNotDigitalReady):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrDownloadUsedUp):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows),
+		errors.Is(err, policy.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+func readFormFile(c *fiber.Ctx) (string, []byte, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, entity.ErrNoDownloadFile
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	return header.Filename, data, err
+}
+
+// SetDownloadFile handles PUT /products/:id/file with a multipart "file"
+// and makes the product a download.
+func (h *DigitalHandler) SetDownloadFile(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	name, data, err := readFormFile(c)
+	if err != nil {
+		return digitalError(c, err)
+	}
+
+	if err = h.uc.SetDownloadFile(c.Context(), id, name, data); err != nil {
+		return digitalError(c, err)
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// AddLicenceKeys handles POST /products/:id/licence-keys with either
+// {"keys": ["AAAA-1111", ...]} or a multipart "file" holding one key per
+// line, and makes the product a licence key product.
+func (h *DigitalHandler) AddLicenceKeys(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		Keys []string `json:"keys"`
+	}
+	if _, data, err := readFormFile(c); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			request.Keys = append(request.Keys, scanner.Text())
+		}
+		if err = scanner.Err(); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	pool, err := h.uc.AddLicenceKeys(c.Context(), id, request.Keys)
+	if err != nil {
+		return digitalError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(pool)
+}
+
+func (h *DigitalHandler) GetKeyPool(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	pool, err := h.uc.GetKeyPool(c.Context(), id)
+	if err != nil {
+		return digitalError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(pool)
+}
+
+// GetOrderDelivery handles GET /orders/:id/downloads for the buyer and
+// admins, returning licence keys and signed download links.
+func (h *DigitalHandler) GetOrderDelivery(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	delivery, err := h.uc.GetDelivery(c.Context(), middleware.Claims(c), id)
+	if err != nil {
+		return digitalError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(delivery)
+}
+
+// Download handles GET /downloads/:id?expires=...&signature=... The link
+// itself is the credential, so the route needs no login.
+func (h *DigitalHandler) Download(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return digitalError(c, entity.ErrInvalidLink)
+	}
+
+	file, data, err := h.uc.Download(c.Context(), id, expires, c.Query("signature"))
+	if err != nil {
+		return digitalError(c, err)
+	}
+
+	c.Attachment(file.Name)
+	c.Set(fiber.HeaderContentType, http.DetectContentType(data))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Status(fiber.StatusOK).Send(data)
+}
@@ -0,0 +1,256 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/digital/entity"
+	productEntity "ecommerce/internal/product/entity"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type DigitalPGRepository struct {
+	DB *sql.DB
+}
+
+func NewDigitalPGRepository(db *sql.DB) *DigitalPGRepository {
+	return &DigitalPGRepository{
+		DB: db,
+	}
+}
+
+func (r *DigitalPGRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// makeDigital turns the product into a digital product delivered as kind.
+// Only products that never held stock and take no part in a bundle qualify,
+// and a product cannot switch between downloads and licence keys.
+func makeDigital(ctx context.Context, tx *sql.Tx, productID int, kind productEntity.DigitalKind) error {
+	var stock int
+	var isBundle, isComponent bool
+	var current productEntity.DigitalKind
+	query := `SELECT stock, is_bundle, COALESCE(digital, ''),
+			EXISTS (SELECT 1 FROM bundle_components WHERE component_id = products.id)
+		FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, productID).Scan(&stock, &isBundle, &current, &isComponent)
+	if err != nil {
+		return err
+	}
+
+	if current == kind {
+		return nil
+	}
+	if current != "" || stock != 0 || isBundle || isComponent {
+		return entity.ErrNotDigitalReady
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET digital = $1 WHERE id = $2`, kind, productID)
+	return err
+}
+
+// SetDownloadFile makes the product a download served from file and returns
+// the file it replaces, if any, so the caller can delete it.
+func (r *DigitalPGRepository) SetDownloadFile(ctx context.Context, productID int, file entity.File) (*entity.File, error) {
+	var old *entity.File
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := makeDigital(ctx, tx, productID, productEntity.DigitalDownload); err != nil {
+			return err
+		}
+
+		var oldKey, oldName sql.NullString
+		query := `SELECT file_key, file_name FROM products WHERE id = $1`
+		if err := tx.QueryRowContext(ctx, query, productID).Scan(&oldKey, &oldName); err != nil {
+			return err
+		}
+		if oldKey.Valid && oldKey.String != file.Key {
+			old = &entity.File{Key: oldKey.String, Name: oldName.String}
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE products SET file_key = $1, file_name = $2 WHERE id = $3`, file.Key, file.Name, productID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return old, nil
+}
+
+// AddLicenceKeys adds keys to the product's pool and makes it a licence key
+// product. Keys already in the pool are skipped; the number added is
+// returned.
+func (r *DigitalPGRepository) AddLicenceKeys(ctx context.Context, productID int, keys []string) (int, error) {
+	var added int64
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := makeDigital(ctx, tx, productID, productEntity.DigitalLicenceKey); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO licence_keys (product_id, licence_key)
+			SELECT $1, k FROM UNNEST($2::text[]) AS k
+			ON CONFLICT (product_id, licence_key) DO NOTHING`
+		result, err := tx.ExecContext(ctx, query, productID, pq.Array(keys))
+		if err != nil {
+			return err
+		}
+
+		added, err = result.RowsAffected()
+		return err
+	})
+
+	return int(added), err
+}
+
+func (r *DigitalPGRepository) GetKeyPool(ctx context.Context, productID int) (*entity.KeyPool, error) {
+	pool := &entity.KeyPool{ProductID: productID}
+	query := `SELECT COUNT(*) FILTER (WHERE assigned_at IS NULL), COUNT(*) FILTER (WHERE assigned_at IS NOT NULL)
+		FROM licence_keys WHERE product_id = $1`
+	err := r.DB.QueryRowContext(ctx, query, productID).Scan(&pool.Available, &pool.Assigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func (r *DigitalPGRepository) GetOrderOwner(ctx context.Context, orderID int) (string, error) {
+	var username string
+	query := `SELECT COALESCE(u.username, '') FROM orders o JOIN users u ON u.id = o.user_id WHERE o.id = $1`
+	err := r.DB.QueryRowContext(ctx, query, orderID).Scan(&username)
+	return username, err
+}
+
+// GetDelivery returns the download grants and licence keys of an order.
+func (r *DigitalPGRepository) GetDelivery(ctx context.Context, orderID int) (*entity.Delivery, error) {
+	delivery := &entity.Delivery{
+		OrderID:     orderID,
+		Downloads:   []*entity.DownloadGrant{},
+		LicenceKeys: []*entity.AssignedKey{},
+	}
+
+	query := `SELECT g.id, g.order_line_id, g.product_id, COALESCE(p.name, ''), g.expires_at, g.max_downloads, g.download_count
+		FROM download_grants g
+		JOIN order_lines ol ON ol.id = g.order_line_id
+		JOIN products p ON p.id = g.product_id
+		WHERE ol.order_id = $1
+		ORDER BY g.id`
+	rows, err := r.DB.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		grant := &entity.DownloadGrant{}
+		err = rows.Scan(&grant.ID, &grant.OrderLineID, &grant.ProductID, &grant.ProductName, &grant.ExpiresAt, &grant.MaxDownloads, &grant.DownloadCount)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Downloads = append(delivery.Downloads, grant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT k.order_line_id, k.product_id, COALESCE(p.name, ''), k.licence_key, k.assigned_at
+		FROM licence_keys k
+		JOIN order_lines ol ON ol.id = k.order_line_id
+		JOIN products p ON p.id = k.product_id
+		WHERE ol.order_id = $1
+		ORDER BY k.order_line_id, k.id`
+	keyRows, err := r.DB.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer keyRows.Close()
+
+	for keyRows.Next() {
+		key := &entity.AssignedKey{}
+		if err = keyRows.Scan(&key.OrderLineID, &key.ProductID, &key.ProductName, &key.Key, &key.AssignedAt); err != nil {
+			return nil, err
+		}
+		delivery.LicenceKeys = append(delivery.LicenceKeys, key)
+	}
+
+	return delivery, keyRows.Err()
+}
+
+// UseDownload counts one download against the grant and returns the file to
+// serve. Expired and used up grants return entity.ErrDownloadUsedUp.
+func (r *DigitalPGRepository) UseDownload(ctx context.Context, grantID int) (*entity.File, error) {
+	query := `UPDATE download_grants g SET download_count = g.download_count + 1
+		FROM products p
+		WHERE g.id = $1 AND p.id = g.product_id
+		  AND g.expires_at > CURRENT_TIMESTAMP AND g.download_count < g.max_downloads
+		  AND p.file_key IS NOT NULL
+		RETURNING p.file_key, COALESCE(p.file_name, '')`
+
+	file := &entity.File{}
+	err := r.DB.QueryRowContext(ctx, query, grantID).Scan(&file.Key, &file.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrDownloadUsedUp
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// AssignLicenceKeys hands qty free keys of the product to an order line
+// inside the order's transaction.
+func AssignLicenceKeys(ctx context.Context, tx *sql.Tx, orderLineID, productID, qty int) ([]string, error) {
+	query := `UPDATE licence_keys SET order_line_id = $1, assigned_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM licence_keys
+			WHERE product_id = $2 AND assigned_at IS NULL
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING licence_key`
+	rows, err := tx.QueryContext(ctx, query, orderLineID, productID, qty)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) < qty {
+		return nil, entity.ErrNoLicenceKeyLeft
+	}
+
+	return keys, nil
+}
+
+// GrantDownload lets an order line download the product's file for
+// entity.DownloadTTL, entity.DownloadLimit times per item bought.
+func GrantDownload(ctx context.Context, tx *sql.Tx, orderLineID, productID, qty int) error {
+	query := `INSERT INTO download_grants (order_line_id, product_id, expires_at, max_downloads)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second', $4)`
+	_, err := tx.ExecContext(ctx, query, orderLineID, productID, int(entity.DownloadTTL.Seconds()), entity.DownloadLimit*qty)
+	return err
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/digital/repository/digital_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/digital/repository/digital_repository.go -destination=internal/digital/mocks/mock_digital_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/digital/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIDigitalRepository is a mock of IDigitalRepository interface.
+type MockIDigitalRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIDigitalRepositoryMockRecorder
+}
+
+// MockIDigitalRepositoryMockRecorder is the mock recorder for MockIDigitalRepository.
+type MockIDigitalRepositoryMockRecorder struct {
+	mock *MockIDigitalRepository
+}
+
+// NewMockIDigitalRepository creates a new mock instance.
+func NewMockIDigitalRepository(ctrl *gomock.Controller) *MockIDigitalRepository {
+	mock := &MockIDigitalRepository{ctrl: ctrl}
+	mock.recorder = &MockIDigitalRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIDigitalRepository) EXPECT() *MockIDigitalRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddLicenceKeys mocks base method.
+func (m *MockIDigitalRepository) AddLicenceKeys(ctx context.Context, productID int, keys []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLicenceKeys", ctx, productID, keys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLicenceKeys indicates an expected call of AddLicenceKeys.
+func (mr *MockIDigitalRepositoryMockRecorder) AddLicenceKeys(ctx, productID, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLicenceKeys", reflect.TypeOf((*MockIDigitalRepository)(nil).AddLicenceKeys), ctx, productID, keys)
+}
+
+// GetDelivery mocks base method.
+func (m *MockIDigitalRepository) GetDelivery(ctx context.Context, orderID int) (*entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", ctx, orderID)
+	ret0, _ := ret[0].(*entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *MockIDigitalRepositoryMockRecorder) GetDelivery(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*MockIDigitalRepository)(nil).GetDelivery), ctx, orderID)
+}
+
+// GetKeyPool mocks base method.
+func (m *MockIDigitalRepository) GetKeyPool(ctx context.Context, productID int) (*entity.KeyPool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyPool", ctx, productID)
+	ret0, _ := ret[0].(*entity.KeyPool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyPool indicates an expected call of GetKeyPool.
+func (mr *MockIDigitalRepositoryMockRecorder) GetKeyPool(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyPool", reflect.TypeOf((*MockIDigitalRepository)(nil).GetKeyPool), ctx, productID)
+}
+
+// GetOrderOwner mocks base method.
+func (m *MockIDigitalRepository) GetOrderOwner(ctx context.Context, orderID int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderOwner", ctx, orderID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderOwner indicates an expected call of GetOrderOwner.
+func (mr *MockIDigitalRepositoryMockRecorder) GetOrderOwner(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderOwner", reflect.TypeOf((*MockIDigitalRepository)(nil).GetOrderOwner), ctx, orderID)
+}
+
+// SetDownloadFile mocks base method.
+func (m *MockIDigitalRepository) SetDownloadFile(ctx context.Context, productID int, file entity.File) (*entity.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownloadFile", ctx, productID, file)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDownloadFile indicates an expected call of SetDownloadFile.
+func (mr *MockIDigitalRepositoryMockRecorder) SetDownloadFile(ctx, productID, file any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownloadFile", reflect.TypeOf((*MockIDigitalRepository)(nil).SetDownloadFile), ctx, productID, file)
+}
+
+// UseDownload mocks base method.
+func (m *MockIDigitalRepository) UseDownload(ctx context.Context, grantID int) (*entity.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseDownload", ctx, grantID)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseDownload indicates an expected call of UseDownload.
+func (mr *MockIDigitalRepositoryMockRecorder) UseDownload(ctx, grantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseDownload", reflect.TypeOf((*MockIDigitalRepository)(nil).UseDownload), ctx, grantID)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/digital/entity"
+)
+
+type IDigitalRepository interface {
+	SetDownloadFile(ctx context.Context, productID int, file entity.File) (*entity.File, error)
+	AddLicenceKeys(ctx context.Context, productID int, keys []string) (int, error)
+	GetKeyPool(ctx context.Context, productID int) (*entity.KeyPool, error)
+	GetOrderOwner(ctx context.Context, orderID int) (string, error)
+	GetDelivery(ctx context.Context, orderID int) (*entity.Delivery, error)
+	UseDownload(ctx context.Context, grantID int) (*entity.File, error)
+}
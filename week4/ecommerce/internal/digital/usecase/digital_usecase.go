@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"ecommerce/internal/digital/entity"
+	"ecommerce/internal/digital/repository"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/storage"
+	"ecommerce/pkg/utils"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// LinkTTL is how long a signed download link works. Links are handed out
+// fresh on every request for the order's downloads.
+const LinkTTL = 15 * time.Minute
+
+type DigitalUsecase struct {
+	digitalRepo repository.IDigitalRepository
+	storage     storage.Storage
+	signingKey  []byte
+	now         func() time.Time
+}
+
+// NewDigitalUsecase keeps download files in storage, which must not be
+// publicly readable, and signs download links with signingKey.
+func NewDigitalUsecase(digitalRepo repository.IDigitalRepository, storage storage.Storage, signingKey []byte) *DigitalUsecase {
+	return &DigitalUsecase{
+		digitalRepo: digitalRepo,
+		storage:     storage,
+		signingKey:  signingKey,
+		now:         time.Now,
+	}
+}
+
+// SetDownloadFile stores data as the file buyers of the product download
+// and removes the file it replaces.
+func (du *DigitalUsecase) SetDownloadFile(ctx context.Context, productID int, name string, data []byte) error {
+	if len(data) == 0 {
+		return entity.ErrNoDownloadFile
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	file := entity.File{
+		Key:  fmt.Sprintf("digital/%d/%s%s", productID, hex.EncodeToString(b), path.Ext(name)),
+		Name: path.Base(name),
+	}
+	if _, err := du.storage.Put(ctx, file.Key, data, http.DetectContentType(data)); err != nil {
+		return err
+	}
+
+	old, err := du.digitalRepo.SetDownloadFile(ctx, productID, file)
+	if err != nil {
+		du.deleteFile(ctx, file.Key)
+		return err
+	}
+
+	if old != nil {
+		du.deleteFile(ctx, old.Key)
+	}
+
+	return nil
+}
+
+func (du *DigitalUsecase) deleteFile(ctx context.Context, key string) {
+	if err := du.storage.Delete(ctx, key); err != nil {
+		log.Printf("failed to delete stored file %s: %v", key, err)
+	}
+}
+
+// AddLicenceKeys adds keys to the product's pool, one per non-empty line or
+// entry. Duplicates are ignored.
+func (du *DigitalUsecase) AddLicenceKeys(ctx context.Context, productID int, keys []string) (*entity.KeyPool, error) {
+	seen := make(map[string]bool, len(keys))
+	var clean []string
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			clean = append(clean, key)
+		}
+	}
+	if len(clean) == 0 {
+		return nil, entity.ErrNoLicenceKeys
+	}
+
+	added, err := du.digitalRepo.AddLicenceKeys(ctx, productID, clean)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := du.digitalRepo.GetKeyPool(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	pool.Added = added
+
+	return pool, nil
+}
+
+func (du *DigitalUsecase) GetKeyPool(ctx context.Context, productID int) (*entity.KeyPool, error) {
+	return du.digitalRepo.GetKeyPool(ctx, productID)
+}
+
+// GetDelivery returns the order's licence keys and download grants, with a
+// freshly signed link for every grant that can still be used. Only the
+// buyer and callers allowed to read any order may see it; anyone else gets
+// policy.ErrNotFound.
+func (du *DigitalUsecase) GetDelivery(ctx context.Context, caller *utils.Claims, orderID int) (*entity.Delivery, error) {
+	owner, err := du.digitalRepo.GetOrderOwner(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err = policy.Authorize(caller, owner, rbac.OrderReadAny); err != nil {
+		return nil, err
+	}
+
+	delivery, err := du.digitalRepo.GetDelivery(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := du.now()
+	for _, grant := range delivery.Downloads {
+		if grant.Usable(now) {
+			grant.URL = du.downloadURL(grant.ID, now.Add(LinkTTL))
+		}
+	}
+
+	return delivery, nil
+}
+
+func signedMessage(grantID int, expires int64) string {
+	return fmt.Sprintf("download:%d:%d", grantID, expires)
+}
+
+func (du *DigitalUsecase) downloadURL(grantID int, expires time.Time) string {
+	unix := expires.Unix()
+	return fmt.Sprintf("/downloads/%d?expires=%d&signature=%s", grantID, unix, utils.Sign(du.signingKey, signedMessage(grantID, unix)))
+}
+
+// Download checks a signed link, counts the download against its grant and
+// returns the file.
+func (du *DigitalUsecase) Download(ctx context.Context, grantID int, expires int64, signature string) (*entity.File, []byte, error) {
+	if du.now().Unix() > expires || !utils.ValidSignature(du.signingKey, signedMessage(grantID, expires), signature) {
+		return nil, nil, entity.ErrInvalidLink
+	}
+
+	file, err := du.digitalRepo.UseDownload(ctx, grantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := du.storage.Get(ctx, file.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, data, nil
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/digital/entity"
+	mock_repository "ecommerce/internal/digital/mocks"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/storage"
+	"ecommerce/pkg/utils"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	buyer    = &utils.Claims{Username: "alice", Permissions: []string{rbac.OrderCreate}}
+	stranger = &utils.Claims{Username: "mallory", Permissions: []string{rbac.OrderCreate}}
+	admin    = &utils.Claims{Username: "admin", Permissions: []string{rbac.OrderReadAny}}
+)
+
+type DigitalUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl       *gomock.Controller
+	mockRepo       *mock_repository.MockIDigitalRepository
+	storage        *storage.LocalStorage
+	now            time.Time
+	digitalUsecase *DigitalUsecase
+}
+
+func (suite *DigitalUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIDigitalRepository(suite.mockCtrl)
+	suite.storage = storage.NewLocalStorage(suite.T().TempDir(), "")
+	suite.now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.digitalUsecase = NewDigitalUsecase(suite.mockRepo, suite.storage, []byte("secret"))
+	suite.digitalUsecase.now = func() time.Time { return suite.now }
+}
+
+func (suite *DigitalUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestDigitalUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(DigitalUsecaseTestSuite))
+}
+
+// signedLink returns the grant ID, expiry and signature of a link issued by
+// GetDelivery.
+func (suite *DigitalUsecaseTestSuite) signedLink() (int, int64, string) {
+	suite.mockRepo.EXPECT().GetOrderOwner(gomock.Any(), 9).Return("alice", nil)
+	suite.mockRepo.EXPECT().GetDelivery(gomock.Any(), 9).Return(&entity.Delivery{
+		OrderID: 9,
+		Downloads: []*entity.DownloadGrant{
+			{ID: 4, ExpiresAt: suite.now.Add(time.Hour), MaxDownloads: 5},
+		},
+	}, nil)
+
+	delivery, err := suite.digitalUsecase.GetDelivery(context.Background(), buyer, 9)
+	suite.Require().NoError(err)
+
+	link, err := url.Parse(delivery.Downloads[0].URL)
+	suite.Require().NoError(err)
+	suite.Require().Equal("/downloads/4", link.Path)
+
+	expires, err := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+	suite.Require().NoError(err)
+
+	return 4, expires, link.Query().Get("signature")
+}
+
+func (suite *DigitalUsecaseTestSuite) TestGetDelivery() {
+	testCases := []struct {
+		name          string
+		caller        *utils.Claims
+		ownerErr      error
+		grant         *entity.DownloadGrant
+		expectURL     bool
+		expectedError error
+	}{
+		{
+			name:      "Buyer gets a link",
+			caller:    buyer,
+			grant:     &entity.DownloadGrant{ID: 1, ExpiresAt: suite.now.Add(time.Hour), MaxDownloads: 5},
+			expectURL: true,
+		},
+		{
+			name:      "Admin gets a link",
+			caller:    admin,
+			grant:     &entity.DownloadGrant{ID: 1, ExpiresAt: suite.now.Add(time.Hour), MaxDownloads: 5},
+			expectURL: true,
+		},
+		{
+			name:      "No link for a used up grant",
+			caller:    buyer,
+			grant:     &entity.DownloadGrant{ID: 1, ExpiresAt: suite.now.Add(time.Hour), MaxDownloads: 5, DownloadCount: 5},
+			expectURL: false,
+		},
+		{
+			name:      "No link for an expired grant",
+			caller:    buyer,
+			grant:     &entity.DownloadGrant{ID: 1, ExpiresAt: suite.now.Add(-time.Hour), MaxDownloads: 5},
+			expectURL: false,
+		},
+		{
+			name:          "Failed delivery - Another user's order",
+			caller:        stranger,
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed delivery - API key named like the buyer",
+			caller:        &utils.Claims{Username: "alice", APIKeyID: 2},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed delivery - No caller",
+			caller:        nil,
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed delivery - Order does not exist",
+			caller:        admin,
+			ownerErr:      sql.ErrNoRows,
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.mockRepo.EXPECT().GetOrderOwner(gomock.Any(), 9).Return("alice", tc.ownerErr)
+			if tc.expectedError == nil {
+				suite.mockRepo.EXPECT().GetDelivery(gomock.Any(), 9).Return(&entity.Delivery{
+					OrderID:   9,
+					Downloads: []*entity.DownloadGrant{tc.grant},
+				}, nil)
+			}
+
+			delivery, err := suite.digitalUsecase.GetDelivery(context.Background(), tc.caller, 9)
+			suite.Equal(tc.expectedError, err)
+			if err == nil {
+				suite.Equal(tc.expectURL, delivery.Downloads[0].URL != "")
+			}
+		})
+	}
+}
+
+func (suite *DigitalUsecaseTestSuite) TestDownload() {
+	_, err := suite.storage.Put(context.Background(), "digital/1/book.pdf", []byte("%PDF"), "application/pdf")
+	suite.Require().NoError(err)
+
+	grantID, expires, signature := suite.signedLink()
+
+	suite.mockRepo.EXPECT().UseDownload(gomock.Any(), grantID).Return(&entity.File{Key: "digital/1/book.pdf", Name: "book.pdf"}, nil)
+	file, data, err := suite.digitalUsecase.Download(context.Background(), grantID, expires, signature)
+	suite.NoError(err)
+	suite.Equal("book.pdf", file.Name)
+	suite.Equal([]byte("%PDF"), data)
+
+	// Tampering with any part of the link invalidates it
+	_, _, err = suite.digitalUsecase.Download(context.Background(), grantID+1, expires, signature)
+	suite.Equal(entity.ErrInvalidLink, err)
+	_, _, err = suite.digitalUsecase.Download(context.Background(), grantID, expires+3600, signature)
+	suite.Equal(entity.ErrInvalidLink, err)
+
+	// Links stop working after LinkTTL
+	suite.now = suite.now.Add(LinkTTL + time.Second)
+	_, _, err = suite.digitalUsecase.Download(context.Background(), grantID, expires, signature)
+	suite.Equal(entity.ErrInvalidLink, err)
+}
+
+func (suite *DigitalUsecaseTestSuite) TestDownloadUsedUp() {
+	grantID, expires, signature := suite.signedLink()
+
+	suite.mockRepo.EXPECT().UseDownload(gomock.Any(), grantID).Return(nil, entity.ErrDownloadUsedUp)
+	_, _, err := suite.digitalUsecase.Download(context.Background(), grantID, expires, signature)
+	suite.Equal(entity.ErrDownloadUsedUp, err)
+}
+
+func (suite *DigitalUsecaseTestSuite) TestAddLicenceKeys() {
+	suite.mockRepo.EXPECT().AddLicenceKeys(gomock.Any(), 1, []string{"AAAA-1111", "BBBB-2222"}).Return(2, nil)
+	suite.mockRepo.EXPECT().GetKeyPool(gomock.Any(), 1).Return(&entity.KeyPool{ProductID: 1, Available: 2}, nil)
+
+	keys := strings.Split(" AAAA-1111 \n\nBBBB-2222\nAAAA-1111\n", "\n")
+	pool, err := suite.digitalUsecase.AddLicenceKeys(context.Background(), 1, keys)
+	suite.NoError(err)
+	suite.Equal(2, pool.Added)
+
+	_, err = suite.digitalUsecase.AddLicenceKeys(context.Background(), 1, []string{" ", ""})
+	suite.Equal(entity.ErrNoLicenceKeys, err)
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// Reason explains why the on-hand quantity of a product changed.
+type Reason string
+
+const (
+	ReasonSale         Reason = "sale"
+	ReasonCancellation Reason = "cancellation"
+	ReasonReturn       Reason = "return"
+	ReasonReceipt      Reason = "receipt"
+	ReasonAdjustment   Reason = "adjustment"
+	ReasonStockTake    Reason = "stock_take"
+	ReasonTransfer     Reason = "transfer"
+)
+
+// Movement is one entry of the inventory ledger. Quantity is signed: positive
+// values add stock, negative values remove it. The stock of a product, and of
+// a product in each warehouse, always equals the sum of its movements.
+type Movement struct {
+	ID          int        `json:"id"`
+	ProductID   int        `json:"product_id"`
+	WarehouseID *int       `json:"warehouse_id,omitempty"`
+	Quantity    int        `json:"quantity"`
+	Reason      Reason     `json:"reason"`
+	Note        string     `json:"note,omitempty"`
+	Actor       string     `json:"actor,omitempty"`
+	OrderID     *int       `json:"order_id,omitempty"`
+	StockAfter  int        `json:"stock_after"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// StockMismatch reports a product whose stock differs from its ledger. When
+// WarehouseID is set the mismatch is in that warehouse's stock level.
+type StockMismatch struct {
+	ProductID   int  `json:"product_id"`
+	WarehouseID *int `json:"warehouse_id,omitempty"`
+	Stock       int  `json:"stock"`
+	LedgerTotal int  `json:"ledger_total"`
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// ReorderPolicy controls when a product is reported as low on stock.
+// A zero Threshold disables alerts for the product. A field left nil keeps
+// its stored value.
+type ReorderPolicy struct {
+	ProductID    int  `json:"product_id"`
+	Threshold    *int `json:"threshold"`
+	LeadTimeDays *int `json:"lead_time_days"`
+}
+
+// LowStockAlert is raised once when stock falls to or below the threshold
+// and resolved when it climbs back above it.
+type LowStockAlert struct {
+	ID          int        `json:"id"`
+	ProductID   int        `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	Stock       int        `json:"stock"`
+	Threshold   int        `json:"threshold"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SalesVelocity is the raw data a reorder suggestion is computed from.
+type SalesVelocity struct {
+	ProductID    int
+	ProductName  string
+	Stock        int
+	Threshold    int
+	LeadTimeDays int
+	SoldQty      int
+	WindowDays   int
+}
+
+type ReorderSuggestion struct {
+	ProductID         int      `json:"product_id"`
+	ProductName       string   `json:"product_name"`
+	Stock             int      `json:"stock"`
+	Threshold         int      `json:"threshold"`
+	DailySales        float64  `json:"daily_sales"`
+	DaysOfStockLeft   *float64 `json:"days_of_stock_left"`
+	SuggestedQuantity int      `json:"suggested_quantity"`
+}
+
+// Suggest estimates how long the stock lasts at the recent sales rate and
+// how much to order so it covers the supplier lead time plus coverDays.
+// When a threshold is set the suggestion also lifts stock above it.
+func (v SalesVelocity) Suggest(coverDays int) ReorderSuggestion {
+	suggestion := ReorderSuggestion{
+		ProductID:   v.ProductID,
+		ProductName: v.ProductName,
+		Stock:       v.Stock,
+		Threshold:   v.Threshold,
+	}
+
+	if v.WindowDays > 0 {
+		suggestion.DailySales = float64(v.SoldQty) / float64(v.WindowDays)
+	}
+
+	if suggestion.DailySales > 0 {
+		daysLeft := math.Round(float64(v.Stock)/suggestion.DailySales*10) / 10
+		suggestion.DaysOfStockLeft = &daysLeft
+	}
+
+	target := int(math.Ceil(suggestion.DailySales * float64(v.LeadTimeDays+coverDays)))
+	if v.Threshold > 0 {
+		target = max(target, v.Threshold+1)
+	}
+	suggestion.SuggestedQuantity = max(0, target-v.Stock)
+
+	return suggestion
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"database/sql"
+	"ecommerce/internal/inventory/entity"
+	"ecommerce/internal/inventory/infra"
+	"ecommerce/internal/inventory/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InventoryHandler struct {
+	uc *usecase.InventoryUsecase
+}
+
+func NewInventoryHandler(uc *usecase.InventoryUsecase) *InventoryHandler {
+	return &InventoryHandler{
+		uc: uc,
+	}
+}
+
+type stockRequest struct {
+	WarehouseID *int          `json:"warehouse_id"`
+	Quantity    int           `json:"quantity"`
+	Counted     int           `json:"counted"`
+	Reason      entity.Reason `json:"reason"`
+	Note        string        `json:"note"`
+}
+
+func parseStockRequest(c *fiber.Ctx) (int, *stockRequest, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, nil, errors.New("Invalid ID")
+	}
+
+	var request stockRequest
+	if err = c.BodyParser(&request); err != nil {
+		return 0, nil, err
+	}
+
+	return id, &request, nil
+}
+
+func stockError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidQuantity),
+		errors.Is(err, usecase.ErrInvalidReason),
+		errors.Is(err, infra.ErrInsufficientStock),
+		errors.Is(err, infra.ErrBundleStock),
+		errors.Is(err, infra.ErrDigitalStock):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+// ReceiveStock handles POST /products/:id/stock/receive with {"warehouse_id": 2, "quantity": 10, "note": "PO-42"}
+func (h *InventoryHandler) ReceiveStock(c *fiber.Ctx) error {
+	id, request, err := parseStockRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	movement, err := h.uc.ReceiveStock(middleware.ActorContext(c), id, request.WarehouseID, request.Quantity, request.Note)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(movement)
+}
+
+// AdjustStock handles POST /products/:id/stock/adjust with {"quantity": -2, "reason": "adjustment", "note": "damaged"}
+func (h *InventoryHandler) AdjustStock(c *fiber.Ctx) error {
+	id, request, err := parseStockRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	movement, err := h.uc.AdjustStock(middleware.ActorContext(c), id, request.WarehouseID, request.Quantity, request.Reason, request.Note)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(movement)
+}
+
+// StockTake handles POST /products/:id/stock/stock-take with {"counted": 37}
+func (h *InventoryHandler) StockTake(c *fiber.Ctx) error {
+	id, request, err := parseStockRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	movement, err := h.uc.StockTake(middleware.ActorContext(c), id, request.WarehouseID, request.Counted, request.Note)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(movement)
+}
+
+func (h *InventoryHandler) GetStockHistory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	movements, err := h.uc.GetStockHistory(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(movements)
+}
+
+// SetReorderPolicy handles PUT /products/:id/reorder-policy with {"threshold": 5, "lead_time_days": 7}.
+// A field left out keeps its value.
+func (h *InventoryHandler) SetReorderPolicy(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var policy entity.ReorderPolicy
+	if err = c.BodyParser(&policy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	policy.ProductID = id
+
+	err = h.uc.SetReorderPolicy(c.Context(), &policy)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidReorderPolicy):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(policy)
+}
+
+func (h *InventoryHandler) GetLowStockAlerts(c *fiber.Ctx) error {
+	alerts, err := h.uc.GetLowStockAlerts(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(alerts)
+}
+
+// GetReorderSuggestions handles GET /inventory/reorder-suggestions?days=30&cover_days=30
+func (h *InventoryHandler) GetReorderSuggestions(c *fiber.Ctx) error {
+	suggestions, err := h.uc.GetReorderSuggestions(
+		c.Context(),
+		c.QueryInt("days", usecase.DefaultSalesWindowDays),
+		c.QueryInt("cover_days", usecase.DefaultCoverDays),
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(suggestions)
+}
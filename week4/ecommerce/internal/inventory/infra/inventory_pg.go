@@ -0,0 +1,267 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/inventory/entity"
+	warehouseEntity "ecommerce/internal/warehouse/entity"
+	"ecommerce/pkg/utils"
+	"errors"
+)
+
+var (
+	ErrInsufficientStock = warehouseEntity.ErrInsufficientStock
+	ErrBundleStock       = errors.New("bundles have no stock of their own, change the stock of their components")
+	ErrDigitalStock      = errors.New("digital products have no warehouse stock")
+)
+
+type InventoryPGRepository struct {
+	DB *sql.DB
+}
+
+func NewInventoryPGRepository(db *sql.DB) *InventoryPGRepository {
+	return &InventoryPGRepository{
+		DB: db,
+	}
+}
+
+// ApplyMovement changes the stock of movement.ProductID in
+// movement.WarehouseID (the default warehouse when nil) by movement.Quantity
+// and records the movement in the ledger. The product and warehouse rows stay
+// locked until tx ends, so every stock change in the code base should go
+// through here.
+func ApplyMovement(ctx context.Context, tx *sql.Tx, movement *entity.Movement) error {
+	if movement.WarehouseID == nil {
+		id, err := defaultWarehouseID(ctx, tx)
+		if err != nil {
+			return err
+		}
+		movement.WarehouseID = &id
+	}
+
+	var stock int
+	var isBundle, isDigital bool
+	query := `SELECT stock, is_bundle, digital IS NOT NULL FROM products WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, movement.ProductID).Scan(&stock, &isBundle, &isDigital)
+	if err != nil {
+		return err
+	}
+	if isBundle {
+		return ErrBundleStock
+	}
+	if isDigital {
+		return ErrDigitalStock
+	}
+
+	warehouseStock, err := lockWarehouseStock(ctx, tx, *movement.WarehouseID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if warehouseStock+movement.Quantity < 0 {
+		return ErrInsufficientStock
+	}
+
+	query = `INSERT INTO warehouse_stock (warehouse_id, product_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (warehouse_id, product_id) DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity`
+	_, err = tx.ExecContext(ctx, query, *movement.WarehouseID, movement.ProductID, movement.Quantity)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET stock = stock + $1 WHERE id = $2`, movement.Quantity, movement.ProductID)
+	if err != nil {
+		return err
+	}
+
+	movement.StockAfter = stock + movement.Quantity
+
+	return insertMovement(ctx, tx, movement)
+}
+
+// ApplyCount sets the stock of movement.ProductID to counted and records the
+// difference as movement.Quantity. With a warehouse the count replaces that
+// warehouse's stock level, otherwise it replaces the product total and the
+// difference is booked against the default warehouse.
+func ApplyCount(ctx context.Context, tx *sql.Tx, movement *entity.Movement, counted int) error {
+	if counted < 0 {
+		return errors.New("invalid stock")
+	}
+
+	var stock int
+	err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1 FOR UPDATE`, movement.ProductID).Scan(&stock)
+	if err != nil {
+		return err
+	}
+
+	if movement.WarehouseID != nil {
+		stock, err = lockWarehouseStock(ctx, tx, *movement.WarehouseID, movement.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+
+	movement.Quantity = counted - stock
+
+	return ApplyMovement(ctx, tx, movement)
+}
+
+func defaultWarehouseID(ctx context.Context, tx *sql.Tx) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM warehouses WHERE is_default`).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.New("no default warehouse configured")
+	}
+
+	return id, err
+}
+
+func lockWarehouseStock(ctx context.Context, tx *sql.Tx, warehouseID, productID int) (int, error) {
+	var quantity int
+	query := `SELECT quantity FROM warehouse_stock WHERE warehouse_id = $1 AND product_id = $2 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, warehouseID, productID).Scan(&quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return quantity, err
+}
+
+func insertMovement(ctx context.Context, tx *sql.Tx, movement *entity.Movement) error {
+	if movement.Actor == "" {
+		movement.Actor = utils.ActorFromContext(ctx)
+	}
+
+	query := `INSERT INTO inventory_movements (product_id, warehouse_id, quantity, reason, note, actor, order_id, stock_after)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at`
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		movement.ProductID,
+		movement.WarehouseID,
+		movement.Quantity,
+		movement.Reason,
+		movement.Note,
+		movement.Actor,
+		movement.OrderID,
+		movement.StockAfter,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+func (r *InventoryPGRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *InventoryPGRepository) Adjust(ctx context.Context, movement *entity.Movement) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return ApplyMovement(ctx, tx, movement)
+	})
+}
+
+func (r *InventoryPGRepository) StockTake(ctx context.Context, movement *entity.Movement, counted int) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return ApplyCount(ctx, tx, movement, counted)
+	})
+}
+
+func (r *InventoryPGRepository) GetHistory(ctx context.Context, productID int) ([]*entity.Movement, error) {
+	query := `SELECT id, product_id, warehouse_id, quantity, reason, COALESCE(note, ''), COALESCE(actor, ''), order_id, stock_after, created_at
+		FROM inventory_movements
+		WHERE product_id = $1
+		ORDER BY id DESC`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*entity.Movement
+	for rows.Next() {
+		movement := &entity.Movement{}
+		var warehouseID, orderID sql.NullInt64
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&warehouseID,
+			&movement.Quantity,
+			&movement.Reason,
+			&movement.Note,
+			&movement.Actor,
+			&orderID,
+			&movement.StockAfter,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if warehouseID.Valid {
+			id := int(warehouseID.Int64)
+			movement.WarehouseID = &id
+		}
+
+		if orderID.Valid {
+			id := int(orderID.Int64)
+			movement.OrderID = &id
+		}
+
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+// Verify compares every product's stock with its ledger, and every warehouse
+// stock level with the movements booked against that warehouse.
+func (r *InventoryPGRepository) Verify(ctx context.Context) ([]*entity.StockMismatch, error) {
+	query := `SELECT p.id, NULL::INT, p.stock, COALESCE(m.total, 0)
+		FROM products p
+		LEFT JOIN (SELECT product_id, SUM(quantity) AS total FROM inventory_movements GROUP BY product_id) m ON m.product_id = p.id
+		WHERE p.stock <> COALESCE(m.total, 0)
+		UNION ALL
+		SELECT COALESCE(ws.product_id, m.product_id), COALESCE(ws.warehouse_id, m.warehouse_id), COALESCE(ws.quantity, 0), COALESCE(m.total, 0)
+		FROM warehouse_stock ws
+		FULL JOIN (
+			SELECT product_id, warehouse_id, SUM(quantity) AS total
+			FROM inventory_movements
+			GROUP BY product_id, warehouse_id
+		) m ON m.product_id = ws.product_id AND m.warehouse_id = ws.warehouse_id
+		WHERE COALESCE(ws.quantity, 0) <> COALESCE(m.total, 0)
+		ORDER BY 1, 2 NULLS FIRST`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []*entity.StockMismatch
+	for rows.Next() {
+		mismatch := &entity.StockMismatch{}
+		var warehouseID sql.NullInt64
+		err := rows.Scan(&mismatch.ProductID, &warehouseID, &mismatch.Stock, &mismatch.LedgerTotal)
+		if err != nil {
+			return nil, err
+		}
+
+		if warehouseID.Valid {
+			id := int(warehouseID.Int64)
+			mismatch.WarehouseID = &id
+		}
+
+		mismatches = append(mismatches, mismatch)
+	}
+
+	return mismatches, rows.Err()
+}
@@ -0,0 +1,146 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/inventory/entity"
+)
+
+// SetReorderPolicy keeps the stored value of fields left nil and sets
+// policy to the result.
+func (r *InventoryPGRepository) SetReorderPolicy(ctx context.Context, policy *entity.ReorderPolicy) error {
+	query := `UPDATE products
+		SET reorder_threshold = COALESCE($1, reorder_threshold), lead_time_days = COALESCE($2, lead_time_days)
+		WHERE id = $3
+		RETURNING reorder_threshold, lead_time_days`
+
+	var threshold, leadTimeDays int
+	err := r.DB.QueryRowContext(ctx, query, policy.Threshold, policy.LeadTimeDays, policy.ProductID).Scan(&threshold, &leadTimeDays)
+	if err != nil {
+		return err
+	}
+
+	policy.Threshold, policy.LeadTimeDays = &threshold, &leadTimeDays
+	return nil
+}
+
+// RaiseLowStockAlerts opens an alert for every product that is at or below
+// its threshold and has no open alert yet, and returns only the new ones.
+// Bundles and digital products are not stocked themselves and never alert.
+func (r *InventoryPGRepository) RaiseLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error) {
+	query := `WITH raised AS (
+			INSERT INTO low_stock_alerts (product_id, stock, threshold)
+			SELECT p.id, p.stock, p.reorder_threshold
+			FROM products p
+			WHERE p.reorder_threshold > 0
+			  AND p.deleted_at IS NULL
+			  AND NOT p.is_bundle AND p.digital IS NULL
+			  AND p.stock <= p.reorder_threshold
+			  AND NOT EXISTS (SELECT 1 FROM low_stock_alerts a WHERE a.product_id = p.id AND a.resolved_at IS NULL)
+			ON CONFLICT DO NOTHING
+			RETURNING id, product_id, stock, threshold, created_at
+		)
+		SELECT r.id, r.product_id, r.stock, r.threshold, r.created_at, COALESCE(p.name, '')
+		FROM raised r
+		JOIN products p ON p.id = r.product_id
+		ORDER BY r.id`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanLowStockAlerts(rows)
+}
+
+// ResolveLowStockAlerts closes open alerts of products whose stock is back
+// above the threshold, so the next drop raises a new alert.
+func (r *InventoryPGRepository) ResolveLowStockAlerts(ctx context.Context) (int, error) {
+	query := `UPDATE low_stock_alerts a
+		SET resolved_at = CURRENT_TIMESTAMP
+		FROM products p
+		WHERE a.product_id = p.id
+		  AND a.resolved_at IS NULL
+		  AND (p.stock > p.reorder_threshold OR p.reorder_threshold = 0 OR p.deleted_at IS NOT NULL
+		       OR p.is_bundle OR p.digital IS NOT NULL)`
+	result, err := r.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+func (r *InventoryPGRepository) GetOpenLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error) {
+	query := `SELECT a.id, a.product_id, a.stock, a.threshold, a.created_at, COALESCE(p.name, '')
+		FROM low_stock_alerts a
+		JOIN products p ON p.id = a.product_id
+		WHERE a.resolved_at IS NULL
+		ORDER BY a.created_at`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanLowStockAlerts(rows)
+}
+
+func scanLowStockAlerts(rows *sql.Rows) ([]*entity.LowStockAlert, error) {
+	defer rows.Close()
+
+	var alerts []*entity.LowStockAlert
+	for rows.Next() {
+		alert := &entity.LowStockAlert{}
+		if err := rows.Scan(&alert.ID, &alert.ProductID, &alert.Stock, &alert.Threshold, &alert.CreatedAt, &alert.ProductName); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// GetSalesVelocity returns, for every stocked product, the quantity sold
+// during the last windowDays days. It is read from the sale movements of the
+// ledger, which also take components out of stock for the bundles they are
+// sold in, net of the cancellations that put items back. Cancellations of
+// sales made before the window can bring a product below zero, which counts
+// as nothing sold.
+func (r *InventoryPGRepository) GetSalesVelocity(ctx context.Context, windowDays int) ([]*entity.SalesVelocity, error) {
+	query := `SELECT p.id, COALESCE(p.name, ''), p.stock, p.reorder_threshold, p.lead_time_days, GREATEST(COALESCE(s.sold, 0), 0)
+		FROM products p
+		LEFT JOIN (
+			SELECT m.product_id, -SUM(m.quantity) AS sold
+			FROM inventory_movements m
+			WHERE m.reason IN ($2, $3)
+			  AND m.created_at >= NOW() - make_interval(days => $1)
+			GROUP BY m.product_id
+		) s ON s.product_id = p.id
+		WHERE p.deleted_at IS NULL
+		  AND NOT p.is_bundle AND p.digital IS NULL
+		ORDER BY p.id`
+	rows, err := r.DB.QueryContext(ctx, query, windowDays, entity.ReasonSale, entity.ReasonCancellation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var velocities []*entity.SalesVelocity
+	for rows.Next() {
+		velocity := &entity.SalesVelocity{WindowDays: windowDays}
+		err := rows.Scan(
+			&velocity.ProductID,
+			&velocity.ProductName,
+			&velocity.Stock,
+			&velocity.Threshold,
+			&velocity.LeadTimeDays,
+			&velocity.SoldQty,
+		)
+		if err != nil {
+			return nil, err
+		}
+		velocities = append(velocities, velocity)
+	}
+
+	return velocities, rows.Err()
+}
@@ -0,0 +1,94 @@
+package infra
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Sales are read from the ledger, where a bundle sale takes its components
+// out of stock, rather than from order lines, which only name the bundle.
+// Cancelled items are taken off what was sold.
+func TestSalesVelocityCountsSaleMovements(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`-SUM\(m\.quantity\) AS sold\s+FROM inventory_movements m\s+WHERE m\.reason IN \(\$2, \$3\)`).
+		WithArgs(30, entity.ReasonSale, entity.ReasonCancellation).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stock", "reorder_threshold", "lead_time_days", "sold"}).
+			AddRow(5, "Desk lamp", 12, 4, 7, 45))
+
+	velocities, err := NewInventoryPGRepository(db).GetSalesVelocity(context.Background(), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := entity.SalesVelocity{ProductID: 5, ProductName: "Desk lamp", Stock: 12, Threshold: 4, LeadTimeDays: 7, SoldQty: 45, WindowDays: 30}
+	if len(velocities) != 1 || *velocities[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, velocities)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A policy sent with only a threshold keeps the stored lead time.
+func TestSetReorderPolicyKeepsOmittedFields(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	threshold := 5
+	mock.ExpectQuery(`SET reorder_threshold = COALESCE\(\$1, reorder_threshold\), lead_time_days = COALESCE\(\$2, lead_time_days\)`).
+		WithArgs(&threshold, nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"reorder_threshold", "lead_time_days"}).AddRow(5, 14))
+
+	policy := &entity.ReorderPolicy{ProductID: 3, Threshold: &threshold}
+	if err = NewInventoryPGRepository(db).SetReorderPolicy(context.Background(), policy); err != nil {
+		t.Fatal(err)
+	}
+
+	if *policy.Threshold != 5 || policy.LeadTimeDays == nil || *policy.LeadTimeDays != 14 {
+		t.Errorf("expected threshold 5 and lead time 14, got %+v", policy)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// New alerts come back with the product name from the same query.
+func TestRaiseLowStockAlertsJoinsNames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`WITH raised AS \([\s\S]*\)\s+SELECT [\s\S]*FROM raised r\s+JOIN products p`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "stock", "threshold", "created_at", "name"}).
+			AddRow(1, 5, 2, 4, nil, "Desk lamp").
+			AddRow(2, 6, 0, 3, nil, "Lamp shade"))
+
+	alerts, err := NewInventoryPGRepository(db).RaiseLowStockAlerts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(alerts) != 2 || alerts[0].ProductName != "Desk lamp" || alerts[1].ProductName != "Lamp shade" {
+		t.Errorf("expected alerts with names, got %+v", alerts)
+	}
+
+	// No further query per alert
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
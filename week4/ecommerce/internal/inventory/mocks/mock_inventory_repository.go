@@ -0,0 +1,173 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/inventory/repository/inventory_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/inventory/repository/inventory_repository.go -destination=internal/inventory/mocks/mock_inventory_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/inventory/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIInventoryRepository is a mock of IInventoryRepository interface.
+type MockIInventoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIInventoryRepositoryMockRecorder
+}
+
+// MockIInventoryRepositoryMockRecorder is the mock recorder for MockIInventoryRepository.
+type MockIInventoryRepositoryMockRecorder struct {
+	mock *MockIInventoryRepository
+}
+
+// NewMockIInventoryRepository creates a new mock instance.
+func NewMockIInventoryRepository(ctrl *gomock.Controller) *MockIInventoryRepository {
+	mock := &MockIInventoryRepository{ctrl: ctrl}
+	mock.recorder = &MockIInventoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIInventoryRepository) EXPECT() *MockIInventoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Adjust mocks base method.
+func (m *MockIInventoryRepository) Adjust(ctx context.Context, movement *entity.Movement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", ctx, movement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockIInventoryRepositoryMockRecorder) Adjust(ctx, movement any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockIInventoryRepository)(nil).Adjust), ctx, movement)
+}
+
+// GetHistory mocks base method.
+func (m *MockIInventoryRepository) GetHistory(ctx context.Context, productID int) ([]*entity.Movement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, productID)
+	ret0, _ := ret[0].([]*entity.Movement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockIInventoryRepositoryMockRecorder) GetHistory(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockIInventoryRepository)(nil).GetHistory), ctx, productID)
+}
+
+// GetOpenLowStockAlerts mocks base method.
+func (m *MockIInventoryRepository) GetOpenLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenLowStockAlerts", ctx)
+	ret0, _ := ret[0].([]*entity.LowStockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenLowStockAlerts indicates an expected call of GetOpenLowStockAlerts.
+func (mr *MockIInventoryRepositoryMockRecorder) GetOpenLowStockAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenLowStockAlerts", reflect.TypeOf((*MockIInventoryRepository)(nil).GetOpenLowStockAlerts), ctx)
+}
+
+// GetSalesVelocity mocks base method.
+func (m *MockIInventoryRepository) GetSalesVelocity(ctx context.Context, windowDays int) ([]*entity.SalesVelocity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSalesVelocity", ctx, windowDays)
+	ret0, _ := ret[0].([]*entity.SalesVelocity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSalesVelocity indicates an expected call of GetSalesVelocity.
+func (mr *MockIInventoryRepositoryMockRecorder) GetSalesVelocity(ctx, windowDays any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSalesVelocity", reflect.TypeOf((*MockIInventoryRepository)(nil).GetSalesVelocity), ctx, windowDays)
+}
+
+// RaiseLowStockAlerts mocks base method.
+func (m *MockIInventoryRepository) RaiseLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RaiseLowStockAlerts", ctx)
+	ret0, _ := ret[0].([]*entity.LowStockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RaiseLowStockAlerts indicates an expected call of RaiseLowStockAlerts.
+func (mr *MockIInventoryRepositoryMockRecorder) RaiseLowStockAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaiseLowStockAlerts", reflect.TypeOf((*MockIInventoryRepository)(nil).RaiseLowStockAlerts), ctx)
+}
+
+// ResolveLowStockAlerts mocks base method.
+func (m *MockIInventoryRepository) ResolveLowStockAlerts(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveLowStockAlerts", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveLowStockAlerts indicates an expected call of ResolveLowStockAlerts.
+func (mr *MockIInventoryRepositoryMockRecorder) ResolveLowStockAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveLowStockAlerts", reflect.TypeOf((*MockIInventoryRepository)(nil).ResolveLowStockAlerts), ctx)
+}
+
+// SetReorderPolicy mocks base method.
+func (m *MockIInventoryRepository) SetReorderPolicy(ctx context.Context, policy *entity.ReorderPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReorderPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReorderPolicy indicates an expected call of SetReorderPolicy.
+func (mr *MockIInventoryRepositoryMockRecorder) SetReorderPolicy(ctx, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReorderPolicy", reflect.TypeOf((*MockIInventoryRepository)(nil).SetReorderPolicy), ctx, policy)
+}
+
+// StockTake mocks base method.
+func (m *MockIInventoryRepository) StockTake(ctx context.Context, movement *entity.Movement, counted int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StockTake", ctx, movement, counted)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StockTake indicates an expected call of StockTake.
+func (mr *MockIInventoryRepositoryMockRecorder) StockTake(ctx, movement, counted any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StockTake", reflect.TypeOf((*MockIInventoryRepository)(nil).StockTake), ctx, movement, counted)
+}
+
+// Verify mocks base method.
+func (m *MockIInventoryRepository) Verify(ctx context.Context) ([]*entity.StockMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx)
+	ret0, _ := ret[0].([]*entity.StockMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockIInventoryRepositoryMockRecorder) Verify(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockIInventoryRepository)(nil).Verify), ctx)
+}
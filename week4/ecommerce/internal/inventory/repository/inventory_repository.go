@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+)
+
+type IInventoryRepository interface {
+	Adjust(ctx context.Context, movement *entity.Movement) error
+	StockTake(ctx context.Context, movement *entity.Movement, counted int) error
+	GetHistory(ctx context.Context, productID int) ([]*entity.Movement, error)
+	Verify(ctx context.Context) ([]*entity.StockMismatch, error)
+	SetReorderPolicy(ctx context.Context, policy *entity.ReorderPolicy) error
+	RaiseLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error)
+	ResolveLowStockAlerts(ctx context.Context) (int, error)
+	GetOpenLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error)
+	GetSalesVelocity(ctx context.Context, windowDays int) ([]*entity.SalesVelocity, error)
+}
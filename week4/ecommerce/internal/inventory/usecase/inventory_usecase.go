@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+	"ecommerce/internal/inventory/repository"
+	"ecommerce/pkg/notify"
+	"errors"
+)
+
+var (
+	ErrInvalidQuantity = errors.New("invalid quantity")
+	ErrInvalidReason   = errors.New("invalid reason, expected adjustment or return")
+)
+
+type InventoryUsecase struct {
+	inventoryRepo repository.IInventoryRepository
+	notifier      notify.Notifier
+}
+
+func NewInventoryUsecase(inventoryRepo repository.IInventoryRepository, notifier notify.Notifier) *InventoryUsecase {
+	return &InventoryUsecase{
+		inventoryRepo: inventoryRepo,
+		notifier:      notifier,
+	}
+}
+
+// ReceiveStock books incoming goods for a product. A nil warehouseID means
+// the default warehouse.
+func (iu *InventoryUsecase) ReceiveStock(ctx context.Context, productID int, warehouseID *int, quantity int, note string) (*entity.Movement, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	movement := &entity.Movement{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    quantity,
+		Reason:      entity.ReasonReceipt,
+		Note:        note,
+	}
+
+	return movement, iu.inventoryRepo.Adjust(ctx, movement)
+}
+
+// AdjustStock applies a manual correction or a customer return. Returns
+// must add stock; adjustments may go either way.
+func (iu *InventoryUsecase) AdjustStock(ctx context.Context, productID int, warehouseID *int, quantity int, reason entity.Reason, note string) (*entity.Movement, error) {
+	if reason != entity.ReasonAdjustment && reason != entity.ReasonReturn {
+		return nil, ErrInvalidReason
+	}
+
+	if quantity == 0 || (reason == entity.ReasonReturn && quantity < 0) {
+		return nil, ErrInvalidQuantity
+	}
+
+	movement := &entity.Movement{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    quantity,
+		Reason:      reason,
+		Note:        note,
+	}
+
+	return movement, iu.inventoryRepo.Adjust(ctx, movement)
+}
+
+// StockTake records a physical count and corrects the stock to match it.
+// With a warehouse the count covers only that warehouse.
+func (iu *InventoryUsecase) StockTake(ctx context.Context, productID int, warehouseID *int, counted int, note string) (*entity.Movement, error) {
+	if counted < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	movement := &entity.Movement{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Reason:      entity.ReasonStockTake,
+		Note:        note,
+	}
+
+	return movement, iu.inventoryRepo.StockTake(ctx, movement, counted)
+}
+
+func (iu *InventoryUsecase) GetStockHistory(ctx context.Context, productID int) ([]*entity.Movement, error) {
+	return iu.inventoryRepo.GetHistory(ctx, productID)
+}
+
+// Verify returns every product whose stock differs from the sum of its movements.
+func (iu *InventoryUsecase) Verify(ctx context.Context) ([]*entity.StockMismatch, error) {
+	return iu.inventoryRepo.Verify(ctx)
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+	mock_repository "ecommerce/internal/inventory/mocks"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type InventoryUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl         *gomock.Controller
+	mockRepo         *mock_repository.MockIInventoryRepository
+	notifier         *recordingNotifier
+	inventoryUsecase InventoryUsecase
+}
+
+func (suite *InventoryUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIInventoryRepository(suite.mockCtrl)
+	suite.notifier = &recordingNotifier{}
+	suite.inventoryUsecase = *NewInventoryUsecase(suite.mockRepo, suite.notifier)
+}
+
+func (suite *InventoryUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestInventoryUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(InventoryUsecaseTestSuite))
+}
+
+func (suite *InventoryUsecaseTestSuite) TestReceiveStock() {
+	testCases := []struct {
+		name          string
+		quantity      int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:     "Successful stock receipt",
+			quantity: 10,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Adjust(gomock.Any(), &entity.Movement{
+					ProductID: 1,
+					Quantity:  10,
+					Reason:    entity.ReasonReceipt,
+					Note:      "PO-42",
+				}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed stock receipt - Invalid quantity",
+			quantity:      0,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidQuantity,
+		},
+		{
+			name:     "Failed stock receipt - Database error",
+			quantity: 5,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Adjust(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.inventoryUsecase.ReceiveStock(context.Background(), 1, nil, tc.quantity, "PO-42")
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+			} else {
+				suite.NoError(err)
+			}
+		})
+	}
+}
+
+func (suite *InventoryUsecaseTestSuite) TestAdjustStock() {
+	testCases := []struct {
+		name          string
+		quantity      int
+		reason        entity.Reason
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:     "Successful manual adjustment",
+			quantity: -2,
+			reason:   entity.ReasonAdjustment,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Adjust(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:     "Successful return",
+			quantity: 1,
+			reason:   entity.ReasonReturn,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Adjust(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed adjustment - Negative return",
+			quantity:      -1,
+			reason:        entity.ReasonReturn,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidQuantity,
+		},
+		{
+			name:          "Failed adjustment - Reserved reason",
+			quantity:      1,
+			reason:        entity.ReasonSale,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			movement, err := suite.inventoryUsecase.AdjustStock(context.Background(), 1, nil, tc.quantity, tc.reason, "")
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+			} else {
+				suite.NoError(err)
+				suite.Equal(tc.reason, movement.Reason)
+				suite.Equal(tc.quantity, movement.Quantity)
+			}
+		})
+	}
+}
+
+func (suite *InventoryUsecaseTestSuite) TestStockTake() {
+	warehouseID := 2
+	testCases := []struct {
+		name          string
+		counted       int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:    "Successful stock-take",
+			counted: 37,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().StockTake(gomock.Any(), gomock.Any(), 37).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed stock-take - Negative count",
+			counted:       -1,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidQuantity,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			movement, err := suite.inventoryUsecase.StockTake(context.Background(), 1, &warehouseID, tc.counted, "")
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+			} else {
+				suite.NoError(err)
+				suite.Equal(entity.ReasonStockTake, movement.Reason)
+				suite.Equal(&warehouseID, movement.WarehouseID)
+			}
+		})
+	}
+}
+
+func (suite *InventoryUsecaseTestSuite) TestVerify() {
+	mismatches := []*entity.StockMismatch{{ProductID: 1, Stock: 10, LedgerTotal: 8}}
+	suite.mockRepo.EXPECT().Verify(gomock.Any()).Return(mismatches, nil)
+
+	result, err := suite.inventoryUsecase.Verify(context.Background())
+	suite.NoError(err)
+	suite.Equal(mismatches, result)
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+	"ecommerce/pkg/notify"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+var ErrInvalidReorderPolicy = errors.New("threshold and lead time must not be negative")
+
+const (
+	// DefaultSalesWindowDays is how far back sales are counted for velocity.
+	DefaultSalesWindowDays = 30
+	// DefaultCoverDays is how many days of sales a reorder should cover on
+	// top of the supplier lead time.
+	DefaultCoverDays = 30
+)
+
+// SetReorderPolicy changes the fields given in policy and fills in the
+// others from the stored policy.
+func (iu *InventoryUsecase) SetReorderPolicy(ctx context.Context, policy *entity.ReorderPolicy) error {
+	if (policy.Threshold != nil && *policy.Threshold < 0) || (policy.LeadTimeDays != nil && *policy.LeadTimeDays < 0) {
+		return ErrInvalidReorderPolicy
+	}
+
+	return iu.inventoryRepo.SetReorderPolicy(ctx, policy)
+}
+
+// CheckLowStock raises an alert and sends a notification for every product
+// that has crossed its threshold since the last check, then resolves the
+// alerts of products that were restocked. It is run by the scheduler.
+func (iu *InventoryUsecase) CheckLowStock(ctx context.Context) error {
+	alerts, err := iu.inventoryRepo.RaiseLowStockAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		// The alert is already stored, so a failed delivery is only logged
+		if err := iu.notifier.Send(ctx, lowStockMessage(alert)); err != nil {
+			log.Printf("failed to send low stock notification for product %d: %v", alert.ProductID, err)
+		}
+	}
+
+	_, err = iu.inventoryRepo.ResolveLowStockAlerts(ctx)
+	return err
+}
+
+func lowStockMessage(alert *entity.LowStockAlert) notify.Message {
+	to := os.Getenv("ADMIN_EMAIL")
+	if to == "" {
+		to = "admins"
+	}
+
+	return notify.Message{
+		Event:   "low_stock",
+		To:      to,
+		Subject: fmt.Sprintf("Low stock: %s", alert.ProductName),
+		Body:    fmt.Sprintf("%s (#%d) has %d left, reorder threshold is %d.", alert.ProductName, alert.ProductID, alert.Stock, alert.Threshold),
+		Data: map[string]interface{}{
+			"product_id": alert.ProductID,
+			"stock":      alert.Stock,
+			"threshold":  alert.Threshold,
+		},
+	}
+}
+
+func (iu *InventoryUsecase) GetLowStockAlerts(ctx context.Context) ([]*entity.LowStockAlert, error) {
+	return iu.inventoryRepo.GetOpenLowStockAlerts(ctx)
+}
+
+// GetReorderSuggestions lists products that should be reordered based on
+// sales over the last windowDays, most urgent first.
+func (iu *InventoryUsecase) GetReorderSuggestions(ctx context.Context, windowDays, coverDays int) ([]entity.ReorderSuggestion, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultSalesWindowDays
+	}
+	if coverDays < 0 {
+		coverDays = DefaultCoverDays
+	}
+
+	velocities, err := iu.inventoryRepo.GetSalesVelocity(ctx, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []entity.ReorderSuggestion{}
+	for _, velocity := range velocities {
+		suggestion := velocity.Suggest(coverDays)
+		if suggestion.SuggestedQuantity > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return daysLeft(suggestions[i]) < daysLeft(suggestions[j])
+	})
+
+	return suggestions, nil
+}
+
+// daysLeft ranks products without recent sales after those that are selling.
+func daysLeft(s entity.ReorderSuggestion) float64 {
+	if s.DaysOfStockLeft == nil {
+		return float64(1 << 30)
+	}
+
+	return *s.DaysOfStockLeft
+}
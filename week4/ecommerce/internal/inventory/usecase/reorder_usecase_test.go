@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/inventory/entity"
+	"ecommerce/pkg/notify"
+	"errors"
+
+	"go.uber.org/mock/gomock"
+)
+
+type recordingNotifier struct {
+	sent []notify.Message
+	err  error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func (suite *InventoryUsecaseTestSuite) TestSetReorderPolicy() {
+	testCases := []struct {
+		name          string
+		policy        *entity.ReorderPolicy
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:   "Successful policy update",
+			policy: &entity.ReorderPolicy{ProductID: 1, Threshold: intPtr(5), LeadTimeDays: intPtr(7)},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().SetReorderPolicy(gomock.Any(), &entity.ReorderPolicy{ProductID: 1, Threshold: intPtr(5), LeadTimeDays: intPtr(7)}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "Successful policy update - Threshold only",
+			policy: &entity.ReorderPolicy{ProductID: 1, Threshold: intPtr(5)},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().SetReorderPolicy(gomock.Any(), &entity.ReorderPolicy{ProductID: 1, Threshold: intPtr(5)}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed policy update - Negative threshold",
+			policy:        &entity.ReorderPolicy{ProductID: 1, Threshold: intPtr(-1)},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidReorderPolicy,
+		},
+		{
+			name:          "Failed policy update - Negative lead time",
+			policy:        &entity.ReorderPolicy{ProductID: 1, LeadTimeDays: intPtr(-1)},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidReorderPolicy,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			err := suite.inventoryUsecase.SetReorderPolicy(context.Background(), tc.policy)
+
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *InventoryUsecaseTestSuite) TestCheckLowStock() {
+	testCases := []struct {
+		name          string
+		notifyErr     error
+		mockBehavior  func()
+		expectedSent  int
+		expectedError error
+	}{
+		{
+			name: "Notifies once per new alert",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().RaiseLowStockAlerts(gomock.Any()).Return([]*entity.LowStockAlert{
+					{ID: 1, ProductID: 1, ProductName: "Mouse", Stock: 2, Threshold: 5},
+					{ID: 2, ProductID: 2, ProductName: "Keyboard", Stock: 0, Threshold: 3},
+				}, nil)
+				suite.mockRepo.EXPECT().ResolveLowStockAlerts(gomock.Any()).Return(0, nil)
+			},
+			expectedSent:  2,
+			expectedError: nil,
+		},
+		{
+			name:      "Delivery failure does not stop the check",
+			notifyErr: errors.New("webhook down"),
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().RaiseLowStockAlerts(gomock.Any()).Return([]*entity.LowStockAlert{
+					{ID: 1, ProductID: 1, ProductName: "Mouse", Stock: 2, Threshold: 5},
+				}, nil)
+				suite.mockRepo.EXPECT().ResolveLowStockAlerts(gomock.Any()).Return(1, nil)
+			},
+			expectedSent:  1,
+			expectedError: nil,
+		},
+		{
+			name: "Failed check - Repository error",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().RaiseLowStockAlerts(gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			expectedSent:  0,
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.notifier.sent = nil
+			suite.notifier.err = tc.notifyErr
+			tc.mockBehavior()
+
+			err := suite.inventoryUsecase.CheckLowStock(context.Background())
+
+			suite.Equal(tc.expectedError, err)
+			suite.Len(suite.notifier.sent, tc.expectedSent)
+			for _, msg := range suite.notifier.sent {
+				suite.Equal("low_stock", msg.Event)
+			}
+		})
+	}
+}
+
+func (suite *InventoryUsecaseTestSuite) TestGetReorderSuggestions() {
+	suite.mockRepo.EXPECT().GetSalesVelocity(gomock.Any(), 30).Return([]*entity.SalesVelocity{
+		// 1 a day with 7 days lead time and 30 days cover: order 37 - 10
+		{ProductID: 1, Stock: 10, LeadTimeDays: 7, SoldQty: 30, WindowDays: 30},
+		// no sales but below threshold: lift stock to threshold + 1
+		{ProductID: 2, Stock: 1, Threshold: 5, LeadTimeDays: 7, WindowDays: 30},
+		// no sales and no threshold: nothing to order
+		{ProductID: 3, Stock: 0, LeadTimeDays: 7, WindowDays: 30},
+		// 3 a day, runs out first
+		{ProductID: 4, Stock: 15, LeadTimeDays: 0, SoldQty: 90, WindowDays: 30},
+	}, nil)
+
+	suggestions, err := suite.inventoryUsecase.GetReorderSuggestions(context.Background(), 30, 30)
+
+	suite.NoError(err)
+	suite.Require().Len(suggestions, 3)
+	suite.Equal(4, suggestions[0].ProductID)
+	suite.Equal(75, suggestions[0].SuggestedQuantity)
+	suite.Equal(5.0, *suggestions[0].DaysOfStockLeft)
+	suite.Equal(1, suggestions[1].ProductID)
+	suite.Equal(27, suggestions[1].SuggestedQuantity)
+	suite.Equal(2, suggestions[2].ProductID)
+	suite.Equal(5, suggestions[2].SuggestedQuantity)
+	suite.Nil(suggestions[2].DaysOfStockLeft)
+}
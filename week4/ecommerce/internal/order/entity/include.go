@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Include describes which relations are loaded together with orders.
+type Include struct {
+	Lines    bool
+	Products bool
+	User     bool
+}
+
+// DefaultInclude is used when the client does not ask for a specific expansion.
+var DefaultInclude = Include{Lines: true, Products: true}
+
+// ParseInclude reads a comma separated list such as "lines,products,user".
+// An empty string yields DefaultInclude. Asking for products implies lines.
+func ParseInclude(raw string) (Include, error) {
+	if strings.TrimSpace(raw) == "" {
+		return DefaultInclude, nil
+	}
+
+	var include Include
+	for _, part := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(part) {
+		case "lines":
+			include.Lines = true
+		case "products":
+			include.Lines = true
+			include.Products = true
+		case "user":
+			include.User = true
+		case "":
+		default:
+			return Include{}, fmt.Errorf("unknown include %q", part)
+		}
+	}
+
+	return include, nil
+}
@@ -5,4 +5,12 @@ type InvoiceItem struct {
 	Quantity    int
 	UnitPrice   float64
 	TotalPrice  float64
+
+	// Components of a bundle, printed underneath its line
+	Components []InvoiceComponent
+}
+
+type InvoiceComponent struct {
+	ProductName string
+	Quantity    int
 }
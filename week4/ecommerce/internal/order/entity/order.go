@@ -2,6 +2,7 @@ package entity
 
 import (
 	userDomain "ecommerce/internal/user/entity"
+	warehouseEntity "ecommerce/internal/warehouse/entity"
 	"time"
 )
 
@@ -11,6 +12,16 @@ type Order struct {
 	OrderDate  *time.Time `json:"created_at,omitempty"`
 	TotalPrice float64    `json:"total_price,omitempty"`
 
+	Status      Status     `json:"status,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+
+	// Version is bumped on every change; updates sent with a version only
+	// apply when it is still current
+	Version int `json:"version,omitempty"`
+
+	// ShipTo is used to pick the nearest warehouse when the order is created
+	ShipTo *warehouseEntity.Location `json:"ship_to,omitempty"`
+
 	User  userDomain.User `json:"user,omitempty"`
 	Lines []OrderLine     `json:"lines,omitempty"`
 }
@@ -2,8 +2,17 @@ package entity
 
 import (
 	"ecommerce/internal/product/entity"
+	warehouseEntity "ecommerce/internal/warehouse/entity"
+	"errors"
 )
 
+var ErrInvalidQuantity = errors.New("quantity must be at least 1")
+
+// ErrLineNotUpdatable is returned when an order line is changed from or to
+// a bundle or digital product, whose stock and deliveries are fixed when the
+// order is created.
+var ErrLineNotUpdatable = errors.New("bundle and digital order lines cannot be changed")
+
 type OrderLine struct {
 	ID        int     `json:"id,omitempty"`
 	OrderID   int     `json:"order_id,omitempty"`
@@ -11,6 +20,23 @@ type OrderLine struct {
 	Qty       int     `json:"qty,omitempty"`
 	Total     float64 `json:"total,omitempty"`
 
+	// Allocations lists the warehouses the line ships from, set on creation
+	Allocations []warehouseEntity.Allocation `json:"allocations,omitempty"`
+
+	// Components lists what a bundle line was made of, set on creation
+	Components []LineComponent `json:"components,omitempty"`
+
+	// LicenceKeys handed out for a licence key product, set on creation
+	LicenceKeys []string `json:"licence_keys,omitempty"`
+
 	Product entity.Product `json:"product,omitempty"`
 	Order   Order          `json:"order,omitempty"`
 }
+
+// LineComponent is one component product taken out of stock for a bundle
+// line, Qty items in total.
+type LineComponent struct {
+	ProductID   int                          `json:"product_id"`
+	Qty         int                          `json:"qty"`
+	Allocations []warehouseEntity.Allocation `json:"allocations,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "errors"
+
+var ErrInvalidStatusChange = errors.New("invalid order status change")
+
+type Status string
+
+const (
+	StatusPlaced    Status = "placed"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+)
+
+// CanBecome reports whether an order in status s may move to next. Orders
+// only move forward: placed, shipped, delivered.
+func (s Status) CanBecome(next Status) bool {
+	switch next {
+	case StatusShipped:
+		return s == StatusPlaced
+	case StatusDelivered:
+		return s == StatusPlaced || s == StatusShipped
+	default:
+		return false
+	}
+}
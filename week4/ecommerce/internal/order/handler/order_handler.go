@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"database/sql"
 	"ecommerce/internal/order/entity"
 	"ecommerce/internal/order/usecase"
 	utils "ecommerce/internal/order/utils"
+	warehouseEntity "ecommerce/internal/warehouse/entity"
 	"ecommerce/pkg/config"
+	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/policy"
 	globalUtils "ecommerce/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"mime/multipart"
 	"os"
@@ -29,7 +34,11 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if err := h.orderUsecase.CreateOrder(c.Context(), &order); err != nil {
+	err := h.orderUsecase.CreateOrder(middleware.ActorContext(c), middleware.Claims(c), &order)
+	if isNotFound(err) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -37,7 +46,12 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 }
 
 func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
-	orders, err := h.orderUsecase.GetAllOrders(c.Context())
+	include, err := entity.ParseInclude(c.Query("include"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orders, err := h.orderUsecase.GetAllOrders(c.Context(), include)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -52,25 +66,34 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	order, err := h.orderUsecase.GetOrderByID(c.Context(), id)
+	order, err := h.orderUsecase.GetOrderByID(c.Context(), middleware.Claims(c), id)
+	if isNotFound(err) || (err == nil && order == nil) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	c.Set(fiber.HeaderETag, globalUtils.VersionTag(order.Version, ""))
+
 	return c.Status(fiber.StatusOK).JSON(order)
 }
 
 func (h *OrderHandler) GetUserOrders(c *fiber.Ctx) error {
 	username := c.Params("username")
-	var (
-		err    error
-		orders []*entity.Order
-	)
+	include, err := entity.ParseInclude(c.Query("include"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var orders []*entity.Order
 	if username == "" {
-		orders, err = h.orderUsecase.GetAllOrders(c.Context())
+		orders, err = h.orderUsecase.GetAllOrders(c.Context(), include)
 	} else {
-		orders, err = h.orderUsecase.GetUserOrders(c.Context(), username)
-
+		orders, err = h.orderUsecase.GetUserOrders(c.Context(), middleware.Claims(c), username, include)
+	}
+	if errors.Is(err, policy.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
 	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -79,16 +102,78 @@ func (h *OrderHandler) GetUserOrders(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(orders)
 }
 
+// UpdateOrder handles PUT /orders/:id. An If-Match header, or a version in
+// the body, makes the update conditional: when the order changed since,
+// nothing is applied and the current order is returned with 409.
 func (h *OrderHandler) UpdateOrder(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
 	var order entity.Order
-	if err := c.BodyParser(&order); err != nil {
+	if err = c.BodyParser(&order); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	order.ID = id
+
+	order.Version, err = middleware.ExpectedVersion(c, order.Version)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if err := h.orderUsecase.UpdateOrder(c.Context(), &order); err != nil {
+	err = h.orderUsecase.UpdateOrder(c.Context(), middleware.Claims(c), &order)
+	if errors.Is(err, globalUtils.ErrVersionConflict) {
+		current, err := h.orderUsecase.GetOrderByID(c.Context(), middleware.Claims(c), id)
+		if err != nil || current == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": globalUtils.ErrVersionConflict.Error()})
+		}
+
+		c.Set(fiber.HeaderETag, globalUtils.VersionTag(current.Version, ""))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": globalUtils.ErrVersionConflict.Error(), "current": current})
+	}
+	if errors.Is(err, entity.ErrInvalidQuantity) ||
+		errors.Is(err, entity.ErrLineNotUpdatable) ||
+		errors.Is(err, warehouseEntity.ErrInsufficientStock) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if isNotFound(err) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	c.Set(fiber.HeaderETag, globalUtils.VersionTag(order.Version, ""))
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// UpdateOrderStatus handles PUT /orders/:id/status with {"status": "delivered"}
+func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		Status entity.Status `json:"status"`
+	}
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	order, err := h.orderUsecase.UpdateOrderStatus(c.Context(), id, request.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidStatusChange):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
 	return c.Status(fiber.StatusOK).JSON(order)
 }
 
@@ -99,7 +184,7 @@ func (h *OrderHandler) DeleteOrder(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	if err := h.orderUsecase.DeleteOrder(c.Context(), id); err != nil {
+	if err := h.orderUsecase.DeleteOrder(middleware.ActorContext(c), id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -113,7 +198,10 @@ func (h *OrderHandler) GetInvoice(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
 	}
 
-	invoices, err := h.orderUsecase.GetInvoice(c.Context(), orderID)
+	invoices, err := h.orderUsecase.GetInvoice(c.Context(), middleware.Claims(c), orderID)
+	if isNotFound(err) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -127,7 +215,10 @@ func (h *OrderHandler) PrintInvoice(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
-	invoiceData, err := h.orderUsecase.GetInvoice(c.Context(), orderId)
+	invoiceData, err := h.orderUsecase.GetInvoice(c.Context(), middleware.Claims(c), orderId)
+	if isNotFound(err) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate invoice data"})
 	}
@@ -191,3 +282,9 @@ func (h *OrderHandler) PrintInvoice(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"pdf_path": pdfPath})
 }
+
+// isNotFound reports whether err means the order does not exist or belongs
+// to someone the caller may not act for; both are answered with 404.
+func isNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, policy.ErrNotFound)
+}
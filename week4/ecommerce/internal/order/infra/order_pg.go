@@ -3,8 +3,18 @@ package infra
 import (
 	"context"
 	"database/sql"
+	digitalInfra "ecommerce/internal/digital/infra"
+	inventoryEntity "ecommerce/internal/inventory/entity"
+	inventoryInfra "ecommerce/internal/inventory/infra"
 	"ecommerce/internal/order/entity"
+	productEntity "ecommerce/internal/product/entity"
+	userEntity "ecommerce/internal/user/entity"
+	warehouseEntity "ecommerce/internal/warehouse/entity"
+	warehouseInfra "ecommerce/internal/warehouse/infra"
+	"ecommerce/pkg/utils"
 	"errors"
+
+	"github.com/lib/pq"
 )
 
 type OrderPGRepository struct {
@@ -30,22 +40,35 @@ func (r *OrderPGRepository) LockProductForUpdate(ctx context.Context, tx *sql.Tx
 	return nil
 }
 
-func (r *OrderPGRepository) BuyProduct(ctx context.Context, tx *sql.Tx, productID, buyQty int) error {
-	var stock int
-	err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1`, productID).Scan(&stock)
+// BuyProduct takes buyQty items of the product out of stock as a sale of
+// orderID. The items are allocated to warehouses by warehouseEntity.Allocate
+// and one sale movement is booked per warehouse.
+func (r *OrderPGRepository) BuyProduct(ctx context.Context, tx *sql.Tx, orderID, productID, buyQty int, shipTo *warehouseEntity.Location) ([]warehouseEntity.Allocation, error) {
+	levels, err := warehouseInfra.LockStockLevels(ctx, tx, productID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if stock < buyQty {
-		return errors.New("insufficient stock")
+	allocations, err := warehouseEntity.Allocate(levels, buyQty, shipTo)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = tx.Exec(`UPDATE products SET stock = stock - $1 WHERE id = $2`, buyQty, productID)
-	if err != nil {
-		return err
+	for _, allocation := range allocations {
+		warehouseID := allocation.WarehouseID
+		err = inventoryInfra.ApplyMovement(ctx, tx, &inventoryEntity.Movement{
+			ProductID:   productID,
+			WarehouseID: &warehouseID,
+			Quantity:    -allocation.Quantity,
+			Reason:      inventoryEntity.ReasonSale,
+			OrderID:     &orderID,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	return nil
+
+	return allocations, nil
 }
 
 func (r *OrderPGRepository) UpdateUserBalance(ctx context.Context, tx *sql.Tx, userID int, totalPrice float64) error {
@@ -60,7 +83,7 @@ func (r *OrderPGRepository) UpdateUserBalance(ctx context.Context, tx *sql.Tx, u
 		return errors.New("insufficient balance")
 	}
 
-	_, err = tx.ExecContext(ctx, `UPDATE users SET balance = $1 WHERE id = $2`, balance, userID)
+	_, err = tx.ExecContext(ctx, `UPDATE users SET balance = $1, version = version + 1 WHERE id = $2`, balance, userID)
 	if err != nil {
 		return err
 	}
@@ -68,13 +91,74 @@ func (r *OrderPGRepository) UpdateUserBalance(ctx context.Context, tx *sql.Tx, u
 	return nil
 }
 
-func (r *OrderPGRepository) CreateOrderLine(ctx context.Context, tx *sql.Tx, orderID int, line entity.OrderLine) error {
+// BuyBundle takes the components of buyQty bundles out of stock. The
+// bundle row is share-locked so its components cannot change until tx ends.
+func (r *OrderPGRepository) BuyBundle(ctx context.Context, tx *sql.Tx, orderID, bundleID, buyQty int, shipTo *warehouseEntity.Location) ([]entity.LineComponent, error) {
+	query := `SELECT bc.component_id, bc.quantity, c.deleted_at IS NOT NULL
+		FROM bundle_components bc
+		JOIN products b ON b.id = bc.bundle_id
+		JOIN products c ON c.id = bc.component_id
+		WHERE bc.bundle_id = $1
+		ORDER BY bc.component_id
+		FOR SHARE OF b`
+	rows, err := tx.QueryContext(ctx, query, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []entity.LineComponent
+	for rows.Next() {
+		var component entity.LineComponent
+		var perBundle int
+		var deleted bool
+		if err = rows.Scan(&component.ProductID, &perBundle, &deleted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if deleted {
+			rows.Close()
+			return nil, warehouseEntity.ErrInsufficientStock
+		}
+		component.Qty = perBundle * buyQty
+		components = append(components, component)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(components) == 0 {
+		return nil, warehouseEntity.ErrInsufficientStock
+	}
+
+	for i := range components {
+		component := &components[i]
+		component.Allocations, err = r.BuyProduct(ctx, tx, orderID, component.ProductID, component.Qty, shipTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return components, nil
+}
+
+// CreateOrderLine stores the line and, for a bundle, the components it was
+// sold with.
+func (r *OrderPGRepository) CreateOrderLine(ctx context.Context, tx *sql.Tx, orderID int, line *entity.OrderLine) error {
 	query := `INSERT INTO order_lines (order_id, product_id, qty, total) VALUES ($1, $2, $3, $4) RETURNING id`
-	_, err := tx.ExecContext(ctx, query, orderID, line.ProductID, line.Qty, line.Total)
+	err := tx.QueryRowContext(ctx, query, orderID, line.ProductID, line.Qty, line.Total).Scan(&line.ID)
 	if err != nil {
 		return err
 	}
 
+	for _, component := range line.Components {
+		_, err = tx.ExecContext(ctx, `INSERT INTO order_line_components (order_line_id, product_id, qty) VALUES ($1, $2, $3)`,
+			line.ID, component.ProductID, component.Qty)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -91,14 +175,16 @@ func (r *OrderPGRepository) Create(ctx context.Context, order *entity.Order) err
 		}
 	}()
 
-	err = tx.QueryRowContext(ctx, `INSERT INTO orders (user_id) VALUES ($1) RETURNING id, created_at`, order.UserID).Scan(&order.ID, &order.OrderDate)
+	err = tx.QueryRowContext(ctx, `INSERT INTO orders (user_id) VALUES ($1) RETURNING id, created_at, status`, order.UserID).Scan(&order.ID, &order.OrderDate, &order.Status)
 	if err != nil {
 		return err
 	}
 
 	totalPrice := 0.0
-	for _, line := range order.Lines {
-		err = tx.QueryRowContext(ctx, `SELECT price FROM products WHERE id = $1`, line.ProductID).Scan(&line.Product.Price)
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		query := `SELECT price, is_bundle, COALESCE(digital, '') FROM products WHERE id = $1 AND deleted_at IS NULL`
+		err = tx.QueryRowContext(ctx, query, line.ProductID).Scan(&line.Product.Price, &line.Product.IsBundle, &line.Product.Digital)
 		if err != nil {
 			return err
 		}
@@ -106,7 +192,12 @@ func (r *OrderPGRepository) Create(ctx context.Context, order *entity.Order) err
 		line.Total = line.Product.Price * float64(line.Qty)
 		totalPrice += line.Total
 
-		err = r.BuyProduct(ctx, tx, line.ProductID, line.Qty)
+		switch {
+		case line.Product.IsBundle:
+			line.Components, err = r.BuyBundle(ctx, tx, order.ID, line.ProductID, line.Qty, order.ShipTo)
+		case line.Product.Digital == "":
+			line.Allocations, err = r.BuyProduct(ctx, tx, order.ID, line.ProductID, line.Qty, order.ShipTo)
+		}
 		if err != nil {
 			return err
 		}
@@ -115,6 +206,17 @@ func (r *OrderPGRepository) Create(ctx context.Context, order *entity.Order) err
 		if err != nil {
 			return err
 		}
+
+		// Digital products are delivered once the line exists
+		switch line.Product.Digital {
+		case productEntity.DigitalLicenceKey:
+			line.LicenceKeys, err = digitalInfra.AssignLicenceKeys(ctx, tx, line.ID, line.ProductID, line.Qty)
+		case productEntity.DigitalDownload:
+			err = digitalInfra.GrantDownload(ctx, tx, line.ID, line.ProductID, line.Qty)
+		}
+		if err != nil {
+			return err
+		}
 	}
 
 	err = r.UpdateUserBalance(ctx, tx, order.UserID, totalPrice)
@@ -130,59 +232,162 @@ func (r *OrderPGRepository) Create(ctx context.Context, order *entity.Order) err
 	return nil
 }
 
-func (r *OrderPGRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
-	query := `SELECT id, user_id, created_at, total_price FROM orders`
+func (r *OrderPGRepository) GetAll(ctx context.Context, include entity.Include) ([]*entity.Order, error) {
+	query := `SELECT id, user_id, created_at, total_price, status, delivered_at, version FROM orders ORDER BY id`
 	rows, err := r.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.loadRelations(ctx, orders, include)
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func scanOrders(rows *sql.Rows) ([]*entity.Order, error) {
 	defer rows.Close()
 
 	var orders []*entity.Order
 	for rows.Next() {
 		order := &entity.Order{}
-		err := rows.Scan(&order.ID, &order.UserID, &order.OrderDate, &order.TotalPrice)
+		err := rows.Scan(&order.ID, &order.UserID, &order.OrderDate, &order.TotalPrice, &order.Status, &order.DeliveredAt, &order.Version)
 		if err != nil {
 			return nil, err
 		}
 
-		order.Lines, err = r.getOrderLines(ctx, order.ID)
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// loadRelations fills the requested relations of orders using one query per
+// relation, so the number of queries does not grow with the number of orders.
+func (r *OrderPGRepository) loadRelations(ctx context.Context, orders []*entity.Order, include entity.Include) error {
+	if len(orders) == 0 {
+		return nil
+	}
 
+	if include.Lines {
+		err := r.loadOrderLines(ctx, orders, include.Products)
 		if err != nil {
-			return nil, err
+			return err
 		}
+	}
 
-		orders = append(orders, order)
+	if include.User {
+		err := r.loadUsers(ctx, orders)
+		if err != nil {
+			return err
+		}
 	}
 
-	return orders, nil
+	return nil
 }
 
-func (r *OrderPGRepository) getOrderLines(ctx context.Context, orderID int) ([]entity.OrderLine, error) {
-	query := `SELECT id, order_id, product_id, qty, total FROM order_lines WHERE order_id = $1`
-	rows, err := r.DB.QueryContext(ctx, query, orderID)
+func (r *OrderPGRepository) loadOrderLines(ctx context.Context, orders []*entity.Order, withProducts bool) error {
+	orderIDs := make([]int64, 0, len(orders))
+	byID := make(map[int]*entity.Order, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, int64(order.ID))
+		byID[order.ID] = order
+	}
+
+	query := `SELECT id, order_id, product_id, qty, total FROM order_lines WHERE order_id = ANY($1) ORDER BY order_id, id`
+	if withProducts {
+		query = `SELECT ol.id, ol.order_id, ol.product_id, ol.qty, ol.total,
+				COALESCE(p.name, ''), COALESCE(p.description, ''), COALESCE(p.price, 0.0), COALESCE(p.stock, 0), COALESCE(p.image_path, '')
+			FROM order_lines ol
+			LEFT JOIN products p ON ol.product_id = p.id
+			WHERE ol.order_id = ANY($1)
+			ORDER BY ol.order_id, ol.id`
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(orderIDs))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var lines []entity.OrderLine
 	for rows.Next() {
 		line := entity.OrderLine{}
-		err := rows.Scan(&line.ID, &line.OrderID, &line.ProductID, &line.Qty, &line.Total)
+		dest := []interface{}{&line.ID, &line.OrderID, &line.ProductID, &line.Qty, &line.Total}
+		if withProducts {
+			dest = append(dest,
+				&line.Product.Name,
+				&line.Product.Description,
+				&line.Product.Price,
+				&line.Product.Stock,
+				&line.Product.ImagePath,
+			)
+		}
+
+		err := rows.Scan(dest...)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		lines = append(lines, line)
+
+		if withProducts {
+			line.Product.ID = line.ProductID
+		}
+
+		order := byID[line.OrderID]
+		order.Lines = append(order.Lines, line)
 	}
 
-	return lines, nil
+	return rows.Err()
+}
+
+func (r *OrderPGRepository) loadUsers(ctx context.Context, orders []*entity.Order) error {
+	userIDs := make([]int64, 0, len(orders))
+	seen := make(map[int]bool, len(orders))
+	for _, order := range orders {
+		if !seen[order.UserID] {
+			seen[order.UserID] = true
+			userIDs = append(userIDs, int64(order.UserID))
+		}
+	}
+
+	query := `SELECT id, COALESCE(name, ''), COALESCE(username, ''), COALESCE(email, ''), COALESCE(balance, 0) FROM users WHERE id = ANY($1)`
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	users := make(map[int]userEntity.User, len(userIDs))
+	for rows.Next() {
+		user := userEntity.User{}
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Balance)
+		if err != nil {
+			return err
+		}
+		users[user.ID] = user
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		order.User = users[order.UserID]
+	}
+
+	return nil
 }
 
 func (r *OrderPGRepository) GetByID(ctx context.Context, id int) (*entity.Order, error) {
-	query := `SELECT id, user_id, created_at, total_price FROM orders WHERE id = $1`
+	query := `SELECT id, user_id, created_at, total_price, status, delivered_at, version FROM orders WHERE id = $1`
 	order := &entity.Order{}
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(&order.ID, &order.UserID, &order.OrderDate, &order.TotalPrice)
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&order.ID, &order.UserID, &order.OrderDate, &order.TotalPrice, &order.Status, &order.DeliveredAt, &order.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -190,7 +395,7 @@ func (r *OrderPGRepository) GetByID(ctx context.Context, id int) (*entity.Order,
 		return nil, err
 	}
 
-	order.Lines, err = r.getOrderLines(ctx, order.ID)
+	err = r.loadRelations(ctx, []*entity.Order{order}, entity.DefaultInclude)
 	if err != nil {
 		return nil, err
 	}
@@ -198,69 +403,300 @@ func (r *OrderPGRepository) GetByID(ctx context.Context, id int) (*entity.Order,
 	return order, nil
 }
 
-func (r *OrderPGRepository) GetUserOrders(ctx context.Context, username string) ([]*entity.Order, error) {
-	query := `SELECT o.id, o.user_id, o.created_at, o.total_price FROM orders o JOIN users u ON o.user_id = u.id WHERE u.username = $1`
+func (r *OrderPGRepository) GetUserOrders(ctx context.Context, username string, include entity.Include) ([]*entity.Order, error) {
+	query := `SELECT o.id, o.user_id, o.created_at, o.total_price, o.status, o.delivered_at, o.version FROM orders o JOIN users u ON o.user_id = u.id WHERE u.username = $1 ORDER BY o.id`
 	rows, err := r.DB.QueryContext(ctx, query, username)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var orders []*entity.Order
-	for rows.Next() {
-		order := &entity.Order{}
-		err := rows.Scan(&order.ID, &order.UserID, &order.OrderDate, &order.TotalPrice)
-		if err != nil {
-			return nil, err
-		}
-
-		order.Lines, err = r.getOrderLines(ctx, order.ID)
-		if err != nil {
-			return nil, err
-		}
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
 
-		orders = append(orders, order)
+	err = r.loadRelations(ctx, orders, include)
+	if err != nil {
+		return nil, err
 	}
 
 	return orders, nil
 }
 
+// GetOwner returns the username of the user who placed order id, or
+// sql.ErrNoRows when there is no such order.
+func (r *OrderPGRepository) GetOwner(ctx context.Context, id int) (string, error) {
+	var username string
+	query := `SELECT COALESCE(u.username, '') FROM orders o JOIN users u ON u.id = o.user_id WHERE o.id = $1`
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&username)
+	return username, err
+}
+
+// GetUserID returns the id of the user named username, or sql.ErrNoRows
+// when there is no such user or it was deleted.
+func (r *OrderPGRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1 AND deleted_at IS NULL`, username).Scan(&id)
+	return id, err
+}
+
+// Update stores the lines of the order. Line totals are recomputed, at the
+// unit price the line was sold at while its product stays the same, and the
+// buyer is charged or refunded the difference so the order total remains
+// what they paid. Stock follows the lines as described on updateOrderLine. With an order.Version it only applies while the stored
+// version is the same and returns utils.ErrVersionConflict otherwise.
+// order.Version is set to the new version.
 func (r *OrderPGRepository) Update(ctx context.Context, order *entity.Order) error {
-	query := `UPDATE orders SET user_id = $1, total_price = $2 WHERE id = $3`
-	_, err := r.DB.ExecContext(ctx, query, order.UserID, order.TotalPrice, order.ID)
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET version = version + 1
+		WHERE id = $1 AND ($2 = 0 OR version = $2)
+		RETURNING version, user_id, total_price`
+	var paid float64
+	err = tx.QueryRowContext(ctx, query, order.ID, order.Version).Scan(&order.Version, &order.UserID, &paid)
+	if errors.Is(err, sql.ErrNoRows) && order.Version > 0 {
+		err = r.versionConflict(ctx, tx, order.ID)
+	}
 	if err != nil {
 		return err
 	}
 
-	for _, line := range order.Lines {
-		err = r.updateOrderLine(ctx, line)
+	for i := range order.Lines {
+		err = r.updateOrderLine(ctx, tx, order.ID, &order.Lines[i], order.ShipTo)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(total), 0) FROM order_lines WHERE order_id = $1`, order.ID).Scan(&order.TotalPrice)
+	if err != nil {
+		return err
+	}
+
+	if err = r.UpdateUserBalance(ctx, tx, order.UserID, order.TotalPrice-paid); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET total_price = $1 WHERE id = $2`, order.TotalPrice, order.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *OrderPGRepository) updateOrderLine(ctx context.Context, line entity.OrderLine) error {
-	query := `UPDATE order_lines SET product_id = $1, qty = $2, total = $3 WHERE id = $4`
-	_, err := r.DB.ExecContext(ctx, query, line.ProductID, line.Qty, line.Total, line.ID)
-	return err
+// versionConflict tells a stale version apart from a missing order after a
+// versioned update matched no row.
+func (r *OrderPGRepository) versionConflict(ctx context.Context, tx *sql.Tx, id int) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return utils.ErrVersionConflict
 }
 
+// UpdateStatus moves the order to status if entity.Status.CanBecome allows it.
+// Delivered orders get their delivery time recorded.
+func (r *OrderPGRepository) UpdateStatus(ctx context.Context, id int, status entity.Status) (*entity.Order, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current entity.Status
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if err != nil {
+		return nil, err
+	}
+
+	if !current.CanBecome(status) {
+		return nil, entity.ErrInvalidStatusChange
+	}
+
+	query := `UPDATE orders
+		SET status = $1, delivered_at = CASE WHEN $2 THEN CURRENT_TIMESTAMP ELSE delivered_at END, version = version + 1
+		WHERE id = $3`
+	_, err = tx.ExecContext(ctx, query, status, status == entity.StatusDelivered, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// updateOrderLine changes the product and quantity of a line of order
+// orderID and sets line.Total from them. Items added to the line are sold
+// through BuyProduct and items taken off it go back to the warehouses they
+// were sold from. Bundle and digital lines were fixed on creation and give
+// entity.ErrLineNotUpdatable, either way round. A line of another order or
+// a deleted product gives sql.ErrNoRows.
+func (r *OrderPGRepository) updateOrderLine(ctx context.Context, tx *sql.Tx, orderID int, line *entity.OrderLine, shipTo *warehouseEntity.Location) error {
+	query := `SELECT ol.product_id, ol.qty, NOT p.is_bundle AND p.digital IS NULL
+		FROM order_lines ol
+		JOIN products p ON p.id = ol.product_id
+		WHERE ol.id = $1 AND ol.order_id = $2
+		FOR UPDATE OF ol`
+	var soldProductID, soldQty int
+	var soldPlain, plain bool
+	err := tx.QueryRowContext(ctx, query, line.ID, orderID).Scan(&soldProductID, &soldQty, &soldPlain)
+	if err != nil {
+		return err
+	}
+
+	query = `SELECT NOT is_bundle AND digital IS NULL FROM products WHERE id = $1 AND deleted_at IS NULL`
+	if err = tx.QueryRowContext(ctx, query, line.ProductID).Scan(&plain); err != nil {
+		return err
+	}
+	if !soldPlain || !plain {
+		return entity.ErrLineNotUpdatable
+	}
+
+	returned, bought := soldQty, line.Qty
+	if line.ProductID == soldProductID {
+		returned, bought = max(soldQty-line.Qty, 0), max(line.Qty-soldQty, 0)
+	}
+
+	if returned > 0 {
+		if err = returnSoldStock(ctx, tx, orderID, soldProductID, returned); err != nil {
+			return err
+		}
+	}
+	if bought > 0 {
+		line.Allocations, err = r.BuyProduct(ctx, tx, orderID, line.ProductID, bought, shipTo)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE order_lines ol
+		SET product_id = $1, qty = $2,
+			total = CASE WHEN ol.product_id = $1 AND ol.qty > 0 THEN ol.total / ol.qty ELSE p.price END * $2
+		FROM products p
+		WHERE p.id = $1 AND ol.id = $3 AND ol.order_id = $4
+		RETURNING ol.total`
+	return tx.QueryRowContext(ctx, query, line.ProductID, line.Qty, line.ID, orderID).Scan(&line.Total)
+}
+
+// Delete cancels the order: its items go back into stock, the user is
+// refunded and the order is removed.
 func (r *OrderPGRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM orders WHERE id = $1`
-	_, err := r.DB.ExecContext(ctx, query, id)
-	return err
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var totalPrice float64
+	err = tx.QueryRowContext(ctx, `SELECT user_id, total_price FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&userID, &totalPrice)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Put items back into the warehouses their sale movements took them from
+	restock, err := soldStock(ctx, tx, id, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, movement := range restock {
+		if err = inventoryInfra.ApplyMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	// A negative price refunds the order total
+	if err = r.UpdateUserBalance(ctx, tx, userID, -totalPrice); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM order_lines WHERE order_id = $1`, id); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM orders WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// soldStock returns the cancellation movements that put the items order
+// orderID still has out of stock back into the warehouses its sale movements
+// took them from, for productID or, with 0, for every product.
+func soldStock(ctx context.Context, tx *sql.Tx, orderID, productID int) ([]*inventoryEntity.Movement, error) {
+	query := `SELECT product_id, warehouse_id, -SUM(quantity)
+		FROM inventory_movements
+		WHERE order_id = $1 AND ($2 = 0 OR product_id = $2) AND reason IN ('sale', 'cancellation')
+		GROUP BY product_id, warehouse_id
+		HAVING SUM(quantity) < 0
+		ORDER BY product_id, warehouse_id`
+	rows, err := tx.QueryContext(ctx, query, orderID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restock []*inventoryEntity.Movement
+	for rows.Next() {
+		movement := &inventoryEntity.Movement{Reason: inventoryEntity.ReasonCancellation, OrderID: &orderID}
+		if err = rows.Scan(&movement.ProductID, &movement.WarehouseID, &movement.Quantity); err != nil {
+			return nil, err
+		}
+		restock = append(restock, movement)
+	}
+
+	return restock, rows.Err()
+}
+
+// returnSoldStock puts qty items of the product order orderID bought back
+// into stock, taking them from the warehouses in the order soldStock lists.
+func returnSoldStock(ctx context.Context, tx *sql.Tx, orderID, productID, qty int) error {
+	restock, err := soldStock(ctx, tx, orderID, productID)
+	if err != nil {
+		return err
+	}
+
+	for _, movement := range restock {
+		if qty == 0 {
+			break
+		}
+		movement.Quantity = min(movement.Quantity, qty)
+		qty -= movement.Quantity
+
+		if err = inventoryInfra.ApplyMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (r *OrderPGRepository) GetInvoice(ctx context.Context, orderID int) ([]*entity.InvoiceData, error) {
-	query := `SELECT o.id, o.created_at, u.username, ol.product_id, ol.qty, ol.total, p.name, p.price
+	query := `SELECT o.id, o.created_at, u.username, ol.id, ol.qty, ol.total, p.name, p.price
 		FROM orders o
 		JOIN users u ON o.user_id = u.id
 		JOIN order_lines ol ON o.id = ol.order_id
 		JOIN products p ON ol.product_id = p.id
-		WHERE o.id = $1`
+		WHERE o.id = $1
+		ORDER BY ol.id`
 
 	rows, err := r.DB.QueryContext(ctx, query, orderID)
 	if err != nil {
@@ -269,18 +705,19 @@ func (r *OrderPGRepository) GetInvoice(ctx context.Context, orderID int) ([]*ent
 	defer rows.Close()
 
 	invoices := make(map[int]*entity.InvoiceData)
+	var lineIDs []int64
 	for rows.Next() {
 		var (
 			orderID      int
 			orderDate    string
 			customerName string
-			productID    int
+			lineID       int
 			qty          int
 			total        float64
 			productName  string
 			unitPrice    float64
 		)
-		err := rows.Scan(&orderID, &orderDate, &customerName, &productID, &qty, &total, &productName, &unitPrice)
+		err := rows.Scan(&orderID, &orderDate, &customerName, &lineID, &qty, &total, &productName, &unitPrice)
 		if err != nil {
 			return nil, err
 		}
@@ -303,6 +740,14 @@ func (r *OrderPGRepository) GetInvoice(ctx context.Context, orderID int) ([]*ent
 			TotalPrice:  total,
 		})
 		invoice.Total += total
+		lineIDs = append(lineIDs, int64(lineID))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = r.loadInvoiceComponents(ctx, invoices, lineIDs); err != nil {
+		return nil, err
 	}
 
 	var result []*entity.InvoiceData
@@ -311,4 +756,47 @@ func (r *OrderPGRepository) GetInvoice(ctx context.Context, orderID int) ([]*ent
 	}
 
 	return result, nil
-}
\ No newline at end of file
+}
+
+// loadInvoiceComponents lists the components of bundle lines under their
+// invoice items. lineIDs holds the order line of every item in item order.
+func (r *OrderPGRepository) loadInvoiceComponents(ctx context.Context, invoices map[int]*entity.InvoiceData, lineIDs []int64) error {
+	if len(lineIDs) == 0 {
+		return nil
+	}
+
+	query := `SELECT olc.order_line_id, COALESCE(p.name, ''), olc.qty
+		FROM order_line_components olc
+		JOIN products p ON p.id = olc.product_id
+		WHERE olc.order_line_id = ANY($1)
+		ORDER BY olc.order_line_id, olc.product_id`
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(lineIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	components := make(map[int64][]entity.InvoiceComponent)
+	for rows.Next() {
+		var lineID int64
+		var component entity.InvoiceComponent
+		if err = rows.Scan(&lineID, &component.ProductName, &component.Quantity); err != nil {
+			return err
+		}
+		components[lineID] = append(components[lineID], component)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// Every invoice here belongs to the same order, so items line up with lineIDs
+	i := 0
+	for _, invoice := range invoices {
+		for j := range invoice.Items {
+			invoice.Items[j].Components = components[lineIDs[i]]
+			i++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"context"
+	"database/sql/driver"
+	"ecommerce/internal/order/entity"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// BenchmarkGetAllQueryCount checks that loading orders with every relation
+// costs the same number of queries no matter how many orders are returned.
+func BenchmarkGetAllQueryCount(b *testing.B) {
+	for _, n := range []int{1, 10, 100, 1000} {
+		b.Run(fmt.Sprintf("orders=%d", n), func(b *testing.B) {
+			queries := 0
+			matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+				queries++
+				return sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL)
+			})
+
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db.Close()
+
+			repo := NewOrderPGRepository(db)
+			include := entity.Include{Lines: true, Products: true, User: true}
+			now := time.Now()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				orderRows := sqlmock.NewRows([]string{"id", "user_id", "created_at", "total_price", "status", "delivered_at"})
+				lineRows := sqlmock.NewRows([]string{"id", "order_id", "product_id", "qty", "total", "name", "description", "price", "stock", "image_path"})
+				userRows := sqlmock.NewRows([]string{"id", "name", "username", "email", "balance"})
+				for id := 1; id <= n; id++ {
+					orderRows.AddRow(id, id, now, 20.0, "placed", nil)
+					lineRows.AddRow(2*id-1, id, 1, 1, 10.0, "Product 1", "", 10.0, 5, "")
+					lineRows.AddRow(2*id, id, 2, 1, 10.0, "Product 2", "", 10.0, 5, "")
+					userRows.AddRow(id, "name", fmt.Sprintf("user%d", id), "", 100.0)
+				}
+				mock.ExpectQuery(`FROM orders`).WillReturnRows(orderRows)
+				mock.ExpectQuery(`FROM order_lines`).WithArgs(anyArg{}).WillReturnRows(lineRows)
+				mock.ExpectQuery(`FROM users`).WithArgs(anyArg{}).WillReturnRows(userRows)
+				queries = 0
+				b.StartTimer()
+
+				orders, err := repo.GetAll(context.Background(), include)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(orders) != n || len(orders[n-1].Lines) != 2 || orders[n-1].Lines[0].Product.Name == "" {
+					b.Fatalf("relations were not loaded for %d orders", n)
+				}
+			}
+			b.StopTimer()
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				b.Fatal(err)
+			}
+			if queries != 3 {
+				b.Fatalf("expected 3 queries per call, got %d", queries)
+			}
+			b.ReportMetric(float64(queries), "queries/op")
+		})
+	}
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(driver.Value) bool {
+	return true
+}
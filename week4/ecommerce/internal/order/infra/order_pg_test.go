@@ -0,0 +1,125 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/order/entity"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectCancellation expects inventoryInfra.ApplyMovement to put qty items
+// of the product back into the warehouse.
+func expectCancellation(mock sqlmock.Sqlmock, productID, warehouseID, qty int) {
+	mock.ExpectQuery(`SELECT stock, is_bundle, digital IS NOT NULL FROM products`).
+		WithArgs(productID).
+		WillReturnRows(sqlmock.NewRows([]string{"stock", "is_bundle", "digital"}).AddRow(10, false, false))
+	mock.ExpectQuery(`SELECT quantity FROM warehouse_stock`).
+		WithArgs(warehouseID, productID).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(5))
+	mock.ExpectExec(`INSERT INTO warehouse_stock`).
+		WithArgs(warehouseID, productID, qty).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE products SET stock = stock \+ \$1`).
+		WithArgs(qty, productID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO inventory_movements`).
+		WithArgs(productID, warehouseID, qty, "cancellation", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, nil))
+}
+
+// Lowering the quantity of a line puts the items taken off back into the
+// warehouses they were sold from, no more than were sold from each.
+func TestUpdateOrderLineReturnsRemovedItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT ol.product_id, ol.qty, NOT p.is_bundle AND p.digital IS NULL\s+FROM order_lines ol`).
+		WithArgs(11, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "qty", "plain"}).AddRow(5, 5, true))
+	mock.ExpectQuery(`SELECT NOT is_bundle AND digital IS NULL FROM products WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"plain"}).AddRow(true))
+	mock.ExpectQuery(`SELECT product_id, warehouse_id, -SUM\(quantity\)\s+FROM inventory_movements`).
+		WithArgs(7, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "warehouse_id", "quantity"}).
+			AddRow(5, 1, 2).
+			AddRow(5, 2, 3))
+	expectCancellation(mock, 5, 1, 2)
+	expectCancellation(mock, 5, 2, 1)
+	mock.ExpectQuery(`UPDATE order_lines ol`).
+		WithArgs(5, 2, 11, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(20.0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	line := &entity.OrderLine{ID: 11, ProductID: 5, Qty: 2}
+	if err = NewOrderPGRepository(db).updateOrderLine(context.Background(), tx, 7, line, nil); err != nil {
+		t.Fatal(err)
+	}
+	if line.Total != 20 {
+		t.Errorf("expected total 20, got %v", line.Total)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateOrderLineRejectsProducts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		soldPlain   bool
+		product     *sqlmock.Rows
+		expectedErr error
+	}{
+		{"Line of a bundle or digital product", false, sqlmock.NewRows([]string{"plain"}).AddRow(true), entity.ErrLineNotUpdatable},
+		{"Changed to a bundle or digital product", true, sqlmock.NewRows([]string{"plain"}).AddRow(false), entity.ErrLineNotUpdatable},
+		{"Changed to a deleted product", true, sqlmock.NewRows([]string{"plain"}), sql.ErrNoRows},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM order_lines ol`).
+				WithArgs(11, 7).
+				WillReturnRows(sqlmock.NewRows([]string{"product_id", "qty", "plain"}).AddRow(5, 1, tc.soldPlain))
+			mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL`).
+				WithArgs(6).
+				WillReturnRows(tc.product)
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			// No stock is moved and the line is left as it was
+			line := &entity.OrderLine{ID: 11, ProductID: 6, Qty: 1}
+			err = NewOrderPGRepository(db).updateOrderLine(context.Background(), tx, 7, line, nil)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
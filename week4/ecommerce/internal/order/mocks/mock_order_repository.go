@@ -69,18 +69,18 @@ func (mr *MockIOrderRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 }
 
 // GetAll mocks base method.
-func (m *MockIOrderRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
+func (m *MockIOrderRepository) GetAll(ctx context.Context, include entity.Include) ([]*entity.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetAll", ctx, include)
 	ret0, _ := ret[0].([]*entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockIOrderRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+func (mr *MockIOrderRepositoryMockRecorder) GetAll(ctx, include any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIOrderRepository)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIOrderRepository)(nil).GetAll), ctx, include)
 }
 
 // GetByID mocks base method.
@@ -113,19 +113,49 @@ func (mr *MockIOrderRepositoryMockRecorder) GetInvoice(ctx, orderID any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockIOrderRepository)(nil).GetInvoice), ctx, orderID)
 }
 
+// GetOwner mocks base method.
+func (m *MockIOrderRepository) GetOwner(ctx context.Context, id int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwner", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwner indicates an expected call of GetOwner.
+func (mr *MockIOrderRepositoryMockRecorder) GetOwner(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwner", reflect.TypeOf((*MockIOrderRepository)(nil).GetOwner), ctx, id)
+}
+
+// GetUserID mocks base method.
+func (m *MockIOrderRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserID", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserID indicates an expected call of GetUserID.
+func (mr *MockIOrderRepositoryMockRecorder) GetUserID(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserID", reflect.TypeOf((*MockIOrderRepository)(nil).GetUserID), ctx, username)
+}
+
 // GetUserOrders mocks base method.
-func (m *MockIOrderRepository) GetUserOrders(ctx context.Context, username string) ([]*entity.Order, error) {
+func (m *MockIOrderRepository) GetUserOrders(ctx context.Context, username string, include entity.Include) ([]*entity.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserOrders", ctx, username)
+	ret := m.ctrl.Call(m, "GetUserOrders", ctx, username, include)
 	ret0, _ := ret[0].([]*entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserOrders indicates an expected call of GetUserOrders.
-func (mr *MockIOrderRepositoryMockRecorder) GetUserOrders(ctx, username any) *gomock.Call {
+func (mr *MockIOrderRepositoryMockRecorder) GetUserOrders(ctx, username, include any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockIOrderRepository)(nil).GetUserOrders), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockIOrderRepository)(nil).GetUserOrders), ctx, username, include)
 }
 
 // Update mocks base method.
@@ -141,3 +171,18 @@ func (mr *MockIOrderRepositoryMockRecorder) Update(ctx, order any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIOrderRepository)(nil).Update), ctx, order)
 }
+
+// UpdateStatus mocks base method.
+func (m *MockIOrderRepository) UpdateStatus(ctx context.Context, id int, status entity.Status) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockIOrderRepositoryMockRecorder) UpdateStatus(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockIOrderRepository)(nil).UpdateStatus), ctx, id, status)
+}
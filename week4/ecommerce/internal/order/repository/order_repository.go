@@ -7,10 +7,13 @@ import (
 
 type IOrderRepository interface {
 	Create(ctx context.Context, order *entity.Order) error
-	GetAll(ctx context.Context) ([]*entity.Order, error)
+	GetAll(ctx context.Context, include entity.Include) ([]*entity.Order, error)
 	GetByID(ctx context.Context, id int) (*entity.Order, error)
-	GetUserOrders(ctx context.Context, username string) ([]*entity.Order, error)
+	GetUserOrders(ctx context.Context, username string, include entity.Include) ([]*entity.Order, error)
+	GetOwner(ctx context.Context, id int) (string, error)
+	GetUserID(ctx context.Context, username string) (int, error)
 	Update(ctx context.Context, order *entity.Order) error
+	UpdateStatus(ctx context.Context, id int, status entity.Status) (*entity.Order, error)
 	Delete(ctx context.Context, id int) error
 	GetInvoice(ctx context.Context, orderID int) ([]*entity.InvoiceData, error)
 }
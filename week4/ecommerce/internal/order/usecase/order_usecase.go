@@ -5,6 +5,9 @@ import (
 	"context"
 	"ecommerce/internal/order/entity"
 	"ecommerce/internal/order/repository"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
 	"fmt"
 	"time"
 
@@ -21,34 +24,110 @@ func NewOrderUsecase(orderRepo repository.IOrderRepository) *OrderUsecase {
 	}
 }
 
-func (ou *OrderUsecase) CreateOrder(ctx context.Context, order *entity.Order) error {
+// CreateOrder places the order for the caller and charges their balance.
+// Only callers allowed to change any order may place one for another user
+// through order.UserID; for anyone else a user other than their own gives
+// policy.ErrNotFound.
+func (ou *OrderUsecase) CreateOrder(ctx context.Context, caller *utils.Claims, order *entity.Order) error {
+	if caller == nil {
+		return policy.ErrNotFound
+	}
+	if order.UserID != 0 && caller.HasPermission(rbac.OrderWriteAny) {
+		return ou.orderRepo.Create(ctx, order)
+	}
+
+	// API keys own nothing, so they have no balance to order with
+	if caller.APIKeyID != 0 {
+		return policy.ErrNotFound
+	}
+
+	userID, err := ou.orderRepo.GetUserID(ctx, caller.Username)
+	if err != nil {
+		return err
+	}
+	if order.UserID != 0 && order.UserID != userID {
+		return policy.ErrNotFound
+	}
+	order.UserID = userID
+
 	return ou.orderRepo.Create(ctx, order)
 }
 
-func (ou *OrderUsecase) GetAllOrders(ctx context.Context) ([]*entity.Order, error) {
-	return ou.orderRepo.GetAll(ctx)
+func (ou *OrderUsecase) GetAllOrders(ctx context.Context, include entity.Include) ([]*entity.Order, error) {
+	return ou.orderRepo.GetAll(ctx, include)
 }
 
-func (ou *OrderUsecase) GetOrderByID(ctx context.Context, id int) (*entity.Order, error) {
+// GetOrderByID returns the order to its buyer and to callers allowed to read
+// any order; anyone else gets policy.ErrNotFound.
+func (ou *OrderUsecase) GetOrderByID(ctx context.Context, caller *utils.Claims, id int) (*entity.Order, error) {
+	if err := ou.authorize(ctx, caller, id, rbac.OrderReadAny); err != nil {
+		return nil, err
+	}
+
 	return ou.orderRepo.GetByID(ctx, id)
 }
 
-func (ou *OrderUsecase) GetUserOrders(ctx context.Context, username string) ([]*entity.Order, error) {
-	return ou.orderRepo.GetUserOrders(ctx, username)
+// GetUserOrders lists the orders of username, which only that user and
+// callers allowed to read any order may do.
+func (ou *OrderUsecase) GetUserOrders(ctx context.Context, caller *utils.Claims, username string, include entity.Include) ([]*entity.Order, error) {
+	if err := policy.Authorize(caller, username, rbac.OrderReadAny); err != nil {
+		return nil, err
+	}
+
+	return ou.orderRepo.GetUserOrders(ctx, username, include)
 }
 
-func (ou *OrderUsecase) UpdateOrder(ctx context.Context, order *entity.Order) error {
+// UpdateOrder changes the lines of an order. Buyers cannot change their own
+// orders, as what they are charged and refunded follows the order; only
+// callers allowed to change any order may.
+func (ou *OrderUsecase) UpdateOrder(ctx context.Context, caller *utils.Claims, order *entity.Order) error {
+	if err := policy.Authorize(caller, "", rbac.OrderWriteAny); err != nil {
+		return err
+	}
+
+	for _, line := range order.Lines {
+		if line.Qty < 1 {
+			return entity.ErrInvalidQuantity
+		}
+	}
+
 	return ou.orderRepo.Update(ctx, order)
 }
 
+// UpdateOrderStatus moves an order forward to shipped or delivered.
+func (ou *OrderUsecase) UpdateOrderStatus(ctx context.Context, id int, status entity.Status) (*entity.Order, error) {
+	if status != entity.StatusShipped && status != entity.StatusDelivered {
+		return nil, entity.ErrInvalidStatusChange
+	}
+
+	return ou.orderRepo.UpdateStatus(ctx, id, status)
+}
+
 func (ou *OrderUsecase) DeleteOrder(ctx context.Context, id int) error {
 	return ou.orderRepo.Delete(ctx, id)
 }
 
-func (ou *OrderUsecase) GetInvoice(ctx context.Context, orderID int) ([]*entity.InvoiceData, error) {
+// GetInvoice returns the invoice of an order, under the same rules as
+// GetOrderByID.
+func (ou *OrderUsecase) GetInvoice(ctx context.Context, caller *utils.Claims, orderID int) ([]*entity.InvoiceData, error) {
+	if err := ou.authorize(ctx, caller, orderID, rbac.OrderReadAny); err != nil {
+		return nil, err
+	}
+
 	return ou.orderRepo.GetInvoice(ctx, orderID)
 }
 
+// authorize checks the caller against the buyer of order id. A missing order
+// gives sql.ErrNoRows, so callers cannot tell it from one they may not see.
+func (ou *OrderUsecase) authorize(ctx context.Context, caller *utils.Claims, id int, permission string) error {
+	owner, err := ou.orderRepo.GetOwner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return policy.Authorize(caller, owner, permission)
+}
+
 func (ou OrderUsecase) PrintInvoicePdffunc(invoiceData entity.InvoiceData) ([]byte, error) {
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
@@ -2,16 +2,25 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"ecommerce/internal/order/entity"
 	mock_repository "ecommerce/internal/order/mocks"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
 	"errors"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 )
 
+var (
+	customer = &utils.Claims{Username: "alice", Permissions: []string{rbac.OrderCreate}}
+	stranger = &utils.Claims{Username: "mallory", Permissions: []string{rbac.OrderCreate}}
+	admin    = &utils.Claims{Username: "admin", Permissions: []string{rbac.OrderReadAny, rbac.OrderWriteAny}}
+)
+
 type OrderUsecaseTestSuite struct {
 	suite.Suite
 	mockCtrl     *gomock.Controller
@@ -19,13 +28,13 @@ type OrderUsecaseTestSuite struct {
 	orderUsecase OrderUsecase
 }
 
-func (suite *OrderUsecaseTestSuite) SetupTesT() {
+func (suite *OrderUsecaseTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockRepo = mock_repository.NewMockIOrderRepository(suite.mockCtrl)
 	suite.orderUsecase = *NewOrderUsecase(suite.mockRepo)
 }
 
-func (suite *OrderUsecaseTestSuite) TestDownTest() {
+func (suite *OrderUsecaseTestSuite) TearDownTest() {
 	suite.mockCtrl.Finish()
 }
 
@@ -34,35 +43,86 @@ func TestOrderUsecaseSuite(t *testing.T) {
 }
 
 func (suite *OrderUsecaseTestSuite) TestCreateOrder() {
+	lines := []entity.OrderLine{
+		{ProductID: 1, Qty: 2},
+		{ProductID: 2, Qty: 1},
+	}
+
 	testCases := []struct {
-		name          string
-		input         *entity.Order
-		mockBehavior  func()
-		expectedError error
+		name           string
+		caller         *utils.Claims
+		input          *entity.Order
+		mockBehavior   func()
+		expectedUserID int
+		expectedError  error
 	}{
 		{
-			name: "Successful order creation",
-			input: &entity.Order{
-				UserID: 1,
-				Lines: []entity.OrderLine{
-					{ProductID: 1, Qty: 2},
-					{ProductID: 2, Qty: 1},
-				},
+			name:   "Customer orders for themselves",
+			caller: customer,
+			input:  &entity.Order{Lines: lines},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(1, nil)
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 			},
+			expectedUserID: 1,
+		},
+		{
+			name:   "Customer names their own user",
+			caller: customer,
+			input:  &entity.Order{UserID: 1, Lines: lines},
 			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(1, nil)
 				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 			},
-			expectedError: nil,
+			expectedUserID: 1,
 		},
 		{
-			name: "Failed order creation",
-			input: &entity.Order{
-				UserID: 2,
-				Lines: []entity.OrderLine{
-					{ProductID: 3, Qty: 1},
-				},
+			name:   "Admin orders for another user",
+			caller: admin,
+			input:  &entity.Order{UserID: 2, Lines: lines},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedUserID: 2,
+		},
+		{
+			name:   "Failed order creation - Customer posts another user's user_id",
+			caller: customer,
+			input:  &entity.Order{UserID: 2, Lines: lines},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(1, nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed order creation - API key without order:write:any",
+			caller:        &utils.Claims{Username: "alice", APIKeyID: 4, Permissions: []string{rbac.OrderCreate}},
+			input:         &entity.Order{UserID: 1, Lines: lines},
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed order creation - No caller",
+			caller:        nil,
+			input:         &entity.Order{Lines: lines},
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed order creation - Caller has no user",
+			caller: stranger,
+			input:  &entity.Order{Lines: lines},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "mallory").Return(0, sql.ErrNoRows)
 			},
+			expectedError: sql.ErrNoRows,
+		},
+		{
+			name:   "Failed order creation - Database error",
+			caller: customer,
+			input:  &entity.Order{Lines: lines},
 			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(1, nil)
 				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
 			},
 			expectedError: errors.New("database error"),
@@ -72,11 +132,12 @@ func (suite *OrderUsecaseTestSuite) TestCreateOrder() {
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			err := suite.orderUsecase.CreateOrder(context.Background(), tc.input)
+			err := suite.orderUsecase.CreateOrder(context.Background(), tc.caller, tc.input)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
 				suite.NoError(err)
+				suite.Equal(tc.expectedUserID, tc.input.UserID)
 			}
 		})
 	}
@@ -96,7 +157,7 @@ func (suite *OrderUsecaseTestSuite) TestGetAllOrders() {
 					{ID: 1, UserID: 1, TotalPrice: 100},
 					{ID: 2, UserID: 2, TotalPrice: 200},
 				}
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(orders, nil)
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), entity.DefaultInclude).Return(orders, nil)
 			},
 			expectedResult: []*entity.Order{
 				{ID: 1, UserID: 1, TotalPrice: 100},
@@ -107,7 +168,7 @@ func (suite *OrderUsecaseTestSuite) TestGetAllOrders() {
 		{
 			name: "Failed retrieval of all orders",
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(nil, errors.New("database error"))
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), entity.DefaultInclude).Return(nil, errors.New("database error"))
 			},
 			expectedResult: nil,
 			expectedError:  errors.New("database error"),
@@ -117,7 +178,7 @@ func (suite *OrderUsecaseTestSuite) TestGetAllOrders() {
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			orders, err := suite.orderUsecase.GetAllOrders(context.Background())
+			orders, err := suite.orderUsecase.GetAllOrders(context.Background(), entity.DefaultInclude)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -131,38 +192,68 @@ func (suite *OrderUsecaseTestSuite) TestGetAllOrders() {
 func (suite *OrderUsecaseTestSuite) TestGetOrderByID() {
 	testCases := []struct {
 		name           string
+		caller         *utils.Claims
 		input          int
 		mockBehavior   func()
 		expectedResult *entity.Order
 		expectedError  error
 	}{
 		{
-			name:  "Successful retrieval of order by ID",
-			input: 1,
+			name:   "Buyer gets the order",
+			caller: customer,
+			input:  1,
 			mockBehavior: func() {
-				order := &entity.Order{ID: 1, UserID: 1, TotalPrice: 100}
-				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(order, nil)
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Order{ID: 1, UserID: 1, TotalPrice: 100}, nil)
 			},
 			expectedResult: &entity.Order{ID: 1, UserID: 1, TotalPrice: 100},
-			expectedError:  nil,
 		},
 		{
-			name:  "Failed retrieval of order by ID",
-			input: 2,
+			name:   "Admin gets another user's order",
+			caller: admin,
+			input:  1,
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 2).Return(nil, errors.New("order not found"))
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Order{ID: 1, UserID: 1, TotalPrice: 100}, nil)
 			},
-			expectedResult: nil,
-			expectedError:  errors.New("order not found"),
+			expectedResult: &entity.Order{ID: 1, UserID: 1, TotalPrice: 100},
+		},
+		{
+			name:   "Failed retrieval - Another user's order",
+			caller: stranger,
+			input:  1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed retrieval - No caller",
+			caller: nil,
+			input:  1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed retrieval - Order does not exist",
+			caller: admin,
+			input:  2,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 2).Return("", sql.ErrNoRows)
+			},
+			expectedError: sql.ErrNoRows,
 		},
 	}
 
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			order, err := suite.orderUsecase.GetOrderByID(context.Background(), tc.input)
+			order, err := suite.orderUsecase.GetOrderByID(context.Background(), tc.caller, tc.input)
 			if tc.expectedError != nil {
-				suite.EqualError(err, tc.expectedError.Error())
+				suite.ErrorIs(err, tc.expectedError)
+				suite.Nil(order)
 			} else {
 				suite.NoError(err)
 				suite.Equal(tc.expectedResult, order)
@@ -172,49 +263,71 @@ func (suite *OrderUsecaseTestSuite) TestGetOrderByID() {
 }
 
 func (suite *OrderUsecaseTestSuite) TestGetUserOrders() {
+	orders := []*entity.Order{
+		{ID: 1, UserID: 1, TotalPrice: 100},
+		{ID: 2, UserID: 1, TotalPrice: 200},
+	}
+
 	testCases := []struct {
 		name           string
+		caller         *utils.Claims
 		input          string
 		mockBehavior   func()
 		expectedResult []*entity.Order
 		expectedError  error
 	}{
 		{
-			name:  "Successful retrieval of user orders",
-			input: "testuser",
+			name:   "User lists their own orders",
+			caller: customer,
+			input:  "alice",
 			mockBehavior: func() {
-				orders := []*entity.Order{
-					{ID: 1, UserID: 1, TotalPrice: 100},
-					{ID: 2, UserID: 1, TotalPrice: 200},
-				}
-				suite.mockRepo.EXPECT().GetUserOrders(gomock.Any(), "testuser").Return(orders, nil)
+				suite.mockRepo.EXPECT().GetUserOrders(gomock.Any(), "alice", entity.DefaultInclude).Return(orders, nil)
 			},
-			expectedResult: []*entity.Order{
-				{ID: 1, UserID: 1, TotalPrice: 100},
-				{ID: 2, UserID: 1, TotalPrice: 200},
+			expectedResult: orders,
+		},
+		{
+			name:   "Admin lists another user's orders",
+			caller: admin,
+			input:  "alice",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserOrders(gomock.Any(), "alice", entity.DefaultInclude).Return(orders, nil)
 			},
-			expectedError: nil,
+			expectedResult: orders,
+		},
+		{
+			name:          "Failed retrieval - Another user's orders",
+			caller:        stranger,
+			input:         "alice",
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
 		},
 		{
-			name:  "Failed retrieval of user orders",
-			input: "nonexistentuser",
+			name:          "Failed retrieval - No caller",
+			caller:        nil,
+			input:         "alice",
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed retrieval - Database error",
+			caller: customer,
+			input:  "alice",
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetUserOrders(gomock.Any(), "nonexistentuser").Return(nil, errors.New("user not found"))
+				suite.mockRepo.EXPECT().GetUserOrders(gomock.Any(), "alice", entity.DefaultInclude).Return(nil, errors.New("database error"))
 			},
-			expectedResult: nil,
-			expectedError:  errors.New("user not found"),
+			expectedError: errors.New("database error"),
 		},
 	}
 
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			orders, err := suite.orderUsecase.GetUserOrders(context.Background(), tc.input)
+			result, err := suite.orderUsecase.GetUserOrders(context.Background(), tc.caller, tc.input, entity.DefaultInclude)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
 				suite.NoError(err)
-				suite.Equal(tc.expectedResult, orders)
+				suite.Equal(tc.expectedResult, result)
 			}
 		})
 	}
@@ -223,42 +336,71 @@ func (suite *OrderUsecaseTestSuite) TestGetUserOrders() {
 func (suite *OrderUsecaseTestSuite) TestUpdateOrder() {
 	testCases := []struct {
 		name          string
+		caller        *utils.Claims
 		input         *entity.Order
 		mockBehavior  func()
 		expectedError error
 	}{
 		{
-			name: "Successful order update",
-			input: &entity.Order{
-				ID:         1,
-				UserID:     1,
-				TotalPrice: 150,
-				OrderDate:  &time.Time{},
-			},
+			name:   "Admin updates another user's order",
+			caller: admin,
+			input:  &entity.Order{ID: 1, Lines: []entity.OrderLine{{ID: 4, ProductID: 2, Qty: 3}}},
 			mockBehavior: func() {
 				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 			},
-			expectedError: nil,
 		},
 		{
-			name: "Failed order update",
-			input: &entity.Order{
-				ID:         2,
-				UserID:     2,
-				TotalPrice: 200,
-				OrderDate:  &time.Time{},
+			name:          "Failed order update - Buyer may not change their order",
+			caller:        customer,
+			input:         &entity.Order{ID: 1, UserID: 1, TotalPrice: 150},
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed order update - Another user's order",
+			caller:        stranger,
+			input:         &entity.Order{ID: 1, UserID: 1, TotalPrice: 150},
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed order update - Reader may not change other orders",
+			caller:        &utils.Claims{Username: "support", Permissions: []string{rbac.OrderReadAny}},
+			input:         &entity.Order{ID: 1, UserID: 1, TotalPrice: 150},
+			mockBehavior:  func() {},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:          "Failed order update - No quantity",
+			caller:        admin,
+			input:         &entity.Order{ID: 1, Lines: []entity.OrderLine{{ID: 4, ProductID: 2, Qty: 0}}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidQuantity,
+		},
+		{
+			name:   "Failed order update - Order does not exist",
+			caller: admin,
+			input:  &entity.Order{ID: 2},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(sql.ErrNoRows)
 			},
+			expectedError: sql.ErrNoRows,
+		},
+		{
+			name:   "Failed order update - Database error",
+			caller: admin,
+			input:  &entity.Order{ID: 1},
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("order not found"))
+				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
 			},
-			expectedError: errors.New("order not found"),
+			expectedError: errors.New("database error"),
 		},
 	}
 
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			err := suite.orderUsecase.UpdateOrder(context.Background(), tc.input)
+			err := suite.orderUsecase.UpdateOrder(context.Background(), tc.caller, tc.input)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -268,6 +410,48 @@ func (suite *OrderUsecaseTestSuite) TestUpdateOrder() {
 	}
 }
 
+func (suite *OrderUsecaseTestSuite) TestUpdateOrderStatus() {
+	testCases := []struct {
+		name          string
+		status        entity.Status
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:   "Successful delivery",
+			status: entity.StatusDelivered,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UpdateStatus(gomock.Any(), 1, entity.StatusDelivered).
+					Return(&entity.Order{ID: 1, Status: entity.StatusDelivered}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "Failed status change - Going backwards",
+			status: entity.StatusShipped,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().UpdateStatus(gomock.Any(), 1, entity.StatusShipped).
+					Return(nil, entity.ErrInvalidStatusChange)
+			},
+			expectedError: entity.ErrInvalidStatusChange,
+		},
+		{
+			name:          "Failed status change - Unknown status",
+			status:        entity.Status("lost"),
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidStatusChange,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.orderUsecase.UpdateOrderStatus(context.Background(), 1, tc.status)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
 func (suite *OrderUsecaseTestSuite) TestDeleteOrder() {
 	testCases := []struct {
 		name          string
@@ -307,58 +491,80 @@ func (suite *OrderUsecaseTestSuite) TestDeleteOrder() {
 }
 
 func (suite *OrderUsecaseTestSuite) TestGetInvoice() {
+	invoiceData := []*entity.InvoiceData{
+		{
+			OrderID:      1,
+			OrderDate:    "2023-04-14",
+			CustomerName: "alice",
+			Items: []entity.InvoiceItem{
+				{ProductName: "Product A", Quantity: 2, UnitPrice: 10, TotalPrice: 20},
+			},
+			Total: 20,
+		},
+	}
+
 	testCases := []struct {
 		name           string
+		caller         *utils.Claims
 		input          int
 		mockBehavior   func()
 		expectedResult []*entity.InvoiceData
 		expectedError  error
 	}{
 		{
-			name:  "Successful invoice retrieval",
-			input: 1,
+			name:   "Buyer gets the invoice",
+			caller: customer,
+			input:  1,
 			mockBehavior: func() {
-				invoiceData := []*entity.InvoiceData{
-					{
-						OrderID:      1,
-						OrderDate:    "2023-04-14",
-						CustomerName: "John Doe",
-						Items: []entity.InvoiceItem{
-							{ProductName: "Product A", Quantity: 2, UnitPrice: 10, TotalPrice: 20},
-						},
-						Total: 20,
-					},
-				}
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+				suite.mockRepo.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoiceData, nil)
+			},
+			expectedResult: invoiceData,
+		},
+		{
+			name:   "Admin gets another user's invoice",
+			caller: admin,
+			input:  1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
 				suite.mockRepo.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoiceData, nil)
 			},
-			expectedResult: []*entity.InvoiceData{
-				{
-					OrderID:      1,
-					OrderDate:    "2023-04-14",
-					CustomerName: "John Doe",
-					Items: []entity.InvoiceItem{
-						{ProductName: "Product A", Quantity: 2, UnitPrice: 10, TotalPrice: 20},
-					},
-					Total: 20,
-				},
+			expectedResult: invoiceData,
+		},
+		{
+			name:   "Failed invoice retrieval - Another user's order",
+			caller: stranger,
+			input:  1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
 			},
-			expectedError: nil,
+			expectedError: policy.ErrNotFound,
 		},
 		{
-			name:  "Failed invoice retrieval",
-			input: 2,
+			name:   "Failed invoice retrieval - Order does not exist",
+			caller: customer,
+			input:  2,
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetInvoice(gomock.Any(), 2).Return(nil, errors.New("invoice not found"))
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 2).Return("", sql.ErrNoRows)
 			},
-			expectedResult: nil,
-			expectedError:  errors.New("invoice not found"),
+			expectedError: sql.ErrNoRows,
+		},
+		{
+			name:   "Failed invoice retrieval - Database error",
+			caller: customer,
+			input:  1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetOwner(gomock.Any(), 1).Return("alice", nil)
+				suite.mockRepo.EXPECT().GetInvoice(gomock.Any(), 1).Return(nil, errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
 		},
 	}
 
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			invoice, err := suite.orderUsecase.GetInvoice(context.Background(), tc.input)
+			invoice, err := suite.orderUsecase.GetInvoice(context.Background(), tc.caller, tc.input)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -367,4 +573,4 @@ func (suite *OrderUsecaseTestSuite) TestGetInvoice() {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
@@ -45,6 +45,17 @@ func GenerateInvoicePDF(invoiceData []*entity.InvoiceData) ([]byte, error) {
 		pdf.CellFormat(30, 10, fmt.Sprintf("%d", item.Quantity), "1", 0, "C", false, 0, "")
 		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", item.TotalPrice), "1", 0, "C", false, 0, "")
 		pdf.Ln(-1)
+
+		// Bundle contents, without prices of their own
+		pdf.SetFont("Arial", "I", 10)
+		for _, component := range item.Components {
+			pdf.CellFormat(80, 7, "  - "+component.ProductName, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 7, "", "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 7, fmt.Sprintf("%d", component.Quantity), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(40, 7, "", "1", 0, "C", false, 0, "")
+			pdf.Ln(-1)
+		}
+		pdf.SetFont("Arial", "", 12)
 	}
 
 	pdf.SetX(-50) // Move the cursor to the right edge minus 50 units
@@ -0,0 +1,34 @@
+package entity
+
+import "errors"
+
+var (
+	ErrInvalidBundle    = errors.New("a bundle needs distinct components other than itself, each with a positive quantity")
+	ErrBundleHasStock   = errors.New("a product with stock of its own cannot become a bundle")
+	ErrInvalidComponent = errors.New("bundle components must be existing physical products that are not bundles themselves")
+	ErrDigitalBundle    = errors.New("a digital product cannot become a bundle")
+	ErrUsedAsComponent  = errors.New("the product is a component of another bundle and cannot become one")
+)
+
+// BundleComponent is one product inside a bundle. Quantity items of it go
+// into every bundle sold.
+type BundleComponent struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Stock     int    `json:"stock"`
+}
+
+// ValidateComponents checks a component list for the bundle bundleID before
+// it is stored. An empty list turns the bundle back into a regular product.
+func ValidateComponents(bundleID int, components []*BundleComponent) error {
+	seen := make(map[int]bool, len(components))
+	for _, component := range components {
+		if component.ProductID == bundleID || component.Quantity <= 0 || seen[component.ProductID] {
+			return ErrInvalidBundle
+		}
+		seen[component.ProductID] = true
+	}
+
+	return nil
+}
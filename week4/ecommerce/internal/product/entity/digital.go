@@ -0,0 +1,10 @@
+package entity
+
+// DigitalKind says how a digital product is delivered. Physical products
+// have none.
+type DigitalKind string
+
+const (
+	DigitalDownload   DigitalKind = "download"
+	DigitalLicenceKey DigitalKind = "licence_key"
+)
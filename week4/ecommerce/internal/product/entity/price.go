@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrScheduleOverlap = errors.New("price schedule overlaps another schedule of the product")
+
+type PriceSource string
+
+const (
+	PriceInitial       PriceSource = "initial"
+	PriceManual        PriceSource = "manual"
+	PriceImport        PriceSource = "import"
+	PriceScheduleStart PriceSource = "schedule_start"
+	PriceScheduleEnd   PriceSource = "schedule_end"
+)
+
+// PriceChange is one entry of a product's price history. OldPrice is nil
+// for the first price of a product.
+type PriceChange struct {
+	ID         int         `json:"id"`
+	ProductID  int         `json:"product_id"`
+	OldPrice   *float64    `json:"old_price"`
+	NewPrice   float64     `json:"new_price"`
+	Source     PriceSource `json:"source"`
+	ScheduleID *int        `json:"schedule_id,omitempty"`
+	Actor      string      `json:"actor,omitempty"`
+	CreatedAt  *time.Time  `json:"created_at,omitempty"`
+}
+
+type ScheduleStatus string
+
+const (
+	SchedulePending   ScheduleStatus = "pending"
+	ScheduleActive    ScheduleStatus = "active"
+	ScheduleDone      ScheduleStatus = "done"
+	ScheduleCancelled ScheduleStatus = "cancelled"
+)
+
+// PriceSchedule sets Price from StartsAt and, when EndsAt is set, puts the
+// previous price back at EndsAt.
+type PriceSchedule struct {
+	ID            int            `json:"id"`
+	ProductID     int            `json:"product_id"`
+	Price         float64        `json:"price"`
+	StartsAt      time.Time      `json:"starts_at"`
+	EndsAt        *time.Time     `json:"ends_at,omitempty"`
+	Status        ScheduleStatus `json:"status"`
+	PreviousPrice *float64       `json:"previous_price,omitempty"`
+	CreatedBy     string         `json:"created_by,omitempty"`
+	CreatedAt     *time.Time     `json:"created_at,omitempty"`
+}
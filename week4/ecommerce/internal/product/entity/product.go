@@ -1,13 +1,34 @@
 package entity
 
+import "time"
+
 // Product struct represents the product entity
 type Product struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
-	ImagePath   string  `json:"image_path"`
+	ID          int     `json:"id" form:"id"`
+	SKU         string  `json:"sku" form:"sku"`
+	Name        string  `json:"name" form:"name"`
+	Description string  `json:"description" form:"description"`
+	Price       float64 `json:"price" form:"price"`
+	Stock       *int    `json:"stock" form:"stock"`
+	IsBundle    bool    `json:"is_bundle" form:"-"`
+	ImagePath   string  `json:"image_path" form:"image_path"`
+
+	// Digital products are not stocked; Stock is the number of free licence
+	// keys, or nil for downloads which never run out
+	Digital DigitalKind `json:"digital,omitempty" form:"-"`
+
+	// Maintained from approved reviews
+	AverageRating float64 `json:"average_rating" form:"-"`
+	ReviewCount   int     `json:"review_count" form:"-"`
+
+	// Version is bumped on every edit; updates sent with a version only
+	// apply when it is still current
+	Version   int        `json:"version" form:"version"`
+	UpdatedAt time.Time  `json:"updated_at" form:"-"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" form:"-"`
+
+	Images     []*ProductImage    `json:"images,omitempty" form:"-"`
+	Components []*BundleComponent `json:"components,omitempty" form:"-"`
 }
 
 // NewProduct creates a new product entity
@@ -17,7 +38,6 @@ func NewProduct() *Product {
 		Name:        "",
 		Description: "",
 		Price:       0,
-		Stock:       0,
 		ImagePath:   "",
 	}
 }
@@ -38,7 +58,7 @@ func (p *Product) SetPrice(price float64) *Product {
 }
 
 func (p *Product) SetStock(stock int) *Product {
-	p.Stock = stock
+	p.Stock = &stock
 	return p
 }
 
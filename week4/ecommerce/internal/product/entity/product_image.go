@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// ProductImage is one picture in a product gallery together with its
+// resized renditions. Images are shown in ascending Position order.
+type ProductImage struct {
+	ID           int        `json:"id"`
+	ProductID    int        `json:"product_id"`
+	Position     int        `json:"position"`
+	OriginalURL  string     `json:"original_url"`
+	MediumURL    string     `json:"medium_url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+
+	OriginalKey  string `json:"-"`
+	MediumKey    string `json:"-"`
+	ThumbnailKey string `json:"-"`
+}
+
+// Keys returns the storage keys of the original file and every rendition.
+func (i *ProductImage) Keys() []string {
+	return []string{i.OriginalKey, i.MediumKey, i.ThumbnailKey}
+}
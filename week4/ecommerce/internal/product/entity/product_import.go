@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// ImportColumns is the header of product spreadsheets, used for export and
+// accepted in any order on import. Only name is required for new products.
+var ImportColumns = []string{"id", "sku", "name", "description", "price", "stock", "image_path"}
+
+// ImportRow is one parsed spreadsheet row. Row is the 1-based line in the
+// file so errors can point at it. Columns holds the columns that had a
+// value; the others are left untouched on existing products.
+type ImportRow struct {
+	Row     int
+	Product Product
+	Columns map[string]bool
+}
+
+func (r *ImportRow) Has(column string) bool {
+	return r.Columns[column]
+}
+
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult is what an import did, or would do for a dry run. A run
+// with errors never changes anything.
+type ImportResult struct {
+	DryRun    bool       `json:"dry_run"`
+	TotalRows int        `json:"total_rows"`
+	Created   int        `json:"created"`
+	Updated   int        `json:"updated"`
+	Errors    []RowError `json:"errors"`
+}
+
+type ImportStatus string
+
+const (
+	ImportPending ImportStatus = "pending"
+	ImportRunning ImportStatus = "running"
+	ImportDone    ImportStatus = "done"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportJob tracks an import that runs in the background.
+type ImportJob struct {
+	ID         int           `json:"id"`
+	Filename   string        `json:"filename"`
+	Format     string        `json:"format"`
+	DryRun     bool          `json:"dry_run"`
+	Status     ImportStatus  `json:"status"`
+	Result     *ImportResult `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	CreatedBy  string        `json:"created_by"`
+	CreatedAt  *time.Time    `json:"created_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"database/sql"
+	"ecommerce/internal/product/entity"
+	"ecommerce/internal/product/infra"
+	"ecommerce/internal/product/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PriceHandler struct {
+	uc *usecase.PriceUsecase
+}
+
+func NewPriceHandler(uc *usecase.PriceUsecase) *PriceHandler {
+	return &PriceHandler{
+		uc: uc,
+	}
+}
+
+func (h *PriceHandler) GetPriceHistory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	history, err := h.uc.GetPriceHistory(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(history)
+}
+
+func (h *PriceHandler) GetPriceSchedules(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	schedules, err := h.uc.GetPriceSchedules(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedules)
+}
+
+// SchedulePrice handles POST /products/:id/price-schedules with
+// {"price": 7.99, "starts_at": "2024-11-29T00:00:00Z", "ends_at": "2024-12-02T00:00:00Z"}
+func (h *PriceHandler) SchedulePrice(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		Price    *float64   `json:"price"`
+		StartsAt time.Time  `json:"starts_at"`
+		EndsAt   *time.Time `json:"ends_at"`
+	}
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if request.Price == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "price is required"})
+	}
+
+	schedule := &entity.PriceSchedule{
+		ProductID: id,
+		Price:     *request.Price,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+	}
+
+	err = h.uc.SchedulePrice(middleware.ActorContext(c), schedule)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidSchedule):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, entity.ErrScheduleOverlap):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schedule)
+}
+
+// CancelPriceSchedule drops a pending schedule or ends an active one early.
+func (h *PriceHandler) CancelPriceSchedule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	scheduleID, err := strconv.Atoi(c.Params("scheduleID"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid schedule ID"})
+	}
+
+	schedule, err := h.uc.CancelPriceSchedule(middleware.ActorContext(c), id, scheduleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Price schedule not found"})
+		case errors.Is(err, infra.ErrScheduleFinished):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
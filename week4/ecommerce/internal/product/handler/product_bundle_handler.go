@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"database/sql"
+	"ecommerce/internal/product/entity"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (ph *ProductHandler) GetBundleComponents(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	components, err := ph.uc.GetBundleComponents(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(components)
+}
+
+// SetBundleComponents handles PUT /products/:id/components with
+// {"components": [{"product_id": 2, "quantity": 1}, {"product_id": 3, "quantity": 2}]}.
+// An empty list turns the bundle back into a regular product.
+func (ph *ProductHandler) SetBundleComponents(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		Components []*entity.BundleComponent `json:"components"`
+	}
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	components, err := ph.uc.SetBundleComponents(c.Context(), id, request.Components)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidBundle),
+			errors.Is(err, entity.ErrInvalidComponent):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, entity.ErrBundleHasStock),
+			errors.Is(err, entity.ErrUsedAsComponent),
+			errors.Is(err, entity.ErrDigitalBundle):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(components)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/utils"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// productETag tags one product with its version, so the tag can be sent back
+// in If-Match, followed by a hash of what changes without an edit.
+func productETag(product *entity.Product) string {
+	return utils.VersionTag(product.Version, representationHash(product))
+}
+
+func listETag(products []*entity.Product) string {
+	return fmt.Sprintf(`W/"%s"`, representationHash(products...))
+}
+
+// representationHash is derived from each product's updated_at, which the
+// database bumps on every change to the product, its images or its
+// components. Stock is included because the availability of bundles and
+// licence key products is computed from other rows.
+func representationHash(products ...*entity.Product) string {
+	hash := fnv.New64a()
+	for _, product := range products {
+		stock := -1
+		if product.Stock != nil {
+			stock = *product.Stock
+		}
+		fmt.Fprintf(hash, "%d:%d:%d;", product.ID, product.UpdatedAt.UnixNano(), stock)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// notModified sets the ETag of a response and reports whether the client's
+// copy is still current, in which case the caller answers 304 without a body.
+// No Last-Modified is sent: updated_at misses the stock changes the tag
+// covers, so If-Modified-Since would keep stale stock around.
+func notModified(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderETag, etag)
+	// Clients may keep the response but must check it is still current
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	match := c.Get(fiber.HeaderIfNoneMatch)
+	return match != "" && etagMatches(match, etag)
+}
+
+// etagMatches compares a tag against an If-None-Match list, which uses weak
+// comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,9 +1,17 @@
 package handler
 
 import (
+	"database/sql"
 	"ecommerce/internal/product/entity"
 	"ecommerce/internal/product/usecase"
+	"ecommerce/pkg/imaging"
+	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"io"
+	"mime/multipart"
 	"strconv"
 )
 
@@ -28,24 +36,183 @@ func (ph *ProductHandler) AddProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	err = ph.uc.CreateProduct(c.Context(), &product)
+	// Images are optional and only present on multipart requests. They are
+	// all checked first, so a bad one does not leave a product behind.
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		files = form.File["images"]
+	}
+
+	images, err := readImages(files)
+	if err != nil {
+		return imageError(c, err)
+	}
+
+	err = ph.uc.CreateProduct(middleware.ActorContext(c), &product)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	for _, data := range images {
+		image, err := ph.uc.AddProductImage(c.Context(), product.ID, data)
+		if err != nil {
+			return imageError(c, err)
+		}
+		product.Images = append(product.Images, image)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Product created successfully", "product": product})
+}
+
+// AddProductImages appends the uploaded "images" files to the product gallery.
+func (ph *ProductHandler) AddProductImages(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "At least one image is required"})
+	}
+
+	if _, err = ph.uc.GetByProductID(c.Context(), id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	}
+
+	uploads, err := readImages(form.File["images"])
+	if err != nil {
+		return imageError(c, err)
+	}
+
+	var images []*entity.ProductImage
+	for _, data := range uploads {
+		image, err := ph.uc.AddProductImage(c.Context(), id, data)
+		if err != nil {
+			return imageError(c, err)
+		}
+		images = append(images, image)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(images)
+}
 
+func (ph *ProductHandler) GetProductImages(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	images, err := ph.uc.GetProductImages(c.Context(), id)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Product created successfully"})
+	return c.Status(fiber.StatusOK).JSON(images)
+}
+
+// ReorderProductImages sets the gallery order from a body like {"image_ids": [3, 1, 2]}.
+func (ph *ProductHandler) ReorderProductImages(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		ImageIDs []int `json:"image_ids"`
+	}
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err = ph.uc.ReorderProductImages(c.Context(), id, request.ImageIDs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Images reordered successfully"})
+}
+
+func (ph *ProductHandler) DeleteProductImage(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	imageID, err := strconv.Atoi(c.Params("imageID"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid image ID"})
+	}
+
+	err = ph.uc.DeleteProductImage(c.Context(), id, imageID)
+	if errors.Is(err, usecase.ErrImageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Image deleted successfully"})
+}
+
+// readImages reads every uploaded image and checks it before any is stored.
+func readImages(headers []*multipart.FileHeader) ([][]byte, error) {
+	images := make([][]byte, 0, len(headers))
+	for _, header := range headers {
+		data, err := readImage(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = imaging.Check(data); err != nil {
+			return nil, err
+		}
+		images = append(images, data)
+	}
+
+	return images, nil
+}
+
+func readImage(header *multipart.FileHeader) ([]byte, error) {
+	if header.Size > imaging.MaxUploadSize {
+		return nil, imaging.ErrTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, imaging.MaxUploadSize+1))
+}
+
+func imageError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, imaging.ErrTooLarge) || errors.Is(err, imaging.ErrTooManyPixels) || errors.Is(err, imaging.ErrUnsupportedType) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 }
 
 func (ph *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 	var products []*entity.Product
 	var err error
 
-	products, err = ph.uc.GetAllProducts(c.Context())
+	// Deleted products are only listed for admins asking for them
+	includeDeleted := c.QueryBool("include_deleted")
+	if includeDeleted && !middleware.HasPermission(c, rbac.ProductReadDeleted) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	products, err = ph.uc.GetAllProducts(c.Context(), includeDeleted)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	if notModified(c, listETag(products)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(products)
 }
 
@@ -64,34 +231,68 @@ func (ph *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	}
 
 	product, err = ph.uc.GetByProductID(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	if notModified(c, productETag(product)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(product)
 }
 
+// UpdateProduct applies the sent fields. An If-Match header, or a version in
+// the body, makes the update conditional: when the product changed since,
+// nothing is applied and the current product is returned with 409.
 func (ph *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
-	var product *entity.Product
+	product := &entity.Product{}
 	var err error
 
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
 	// Decode request body to product struct
 	if err = c.BodyParser(product); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	product.ID = id
 
-	err = ph.uc.UpdateProduct(c.Context(), product)
+	product.Version, err = middleware.ExpectedVersion(c, product.Version)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = ph.uc.UpdateProduct(middleware.ActorContext(c), product)
+	if errors.Is(err, utils.ErrVersionConflict) {
+		current, err := ph.uc.GetByProductID(c.Context(), id)
+		if err != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": utils.ErrVersionConflict.Error()})
+		}
+
+		c.Set(fiber.HeaderETag, productETag(current))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": utils.ErrVersionConflict.Error(), "current": current})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	c.Set(fiber.HeaderETag, utils.VersionTag(product.Version, ""))
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Product updated successfully", "product": product})
 }
 
 func (ph *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	var err error
 
-	idStr := c.Query("id")
+	idStr := c.Params("id")
 	if idStr == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID is required"})
 	}
@@ -102,6 +303,9 @@ func (ph *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	}
 
 	err = ph.uc.DeleteProduct(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	}
 	if err != nil {
 		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		return nil
@@ -109,3 +313,20 @@ func (ph *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product deleted successfully"})
 }
+
+func (ph *ProductHandler) RestoreProduct(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	err = ph.uc.RestoreProduct(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No deleted product with this ID"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product restored successfully"})
+}
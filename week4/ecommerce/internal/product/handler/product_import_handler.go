@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"ecommerce/internal/product/usecase"
+	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/tabular"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportProducts handles POST /products/import with a multipart "file" in CSV
+// or XLSX. Query parameters: format (defaults to the file extension),
+// dry_run=true to only validate, async=true to run it as a background job.
+func (ph *ProductHandler) ImportProducts(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "A file is required"})
+	}
+
+	format, err := tabular.FormatFromFilename(header.Filename)
+	if raw := c.Query("format"); raw != "" {
+		format, err = tabular.ParseFormat(raw)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	dryRun := c.QueryBool("dry_run")
+	ctx := middleware.ActorContext(c)
+
+	if c.QueryBool("async") {
+		job, err := ph.uc.StartImport(ctx, header.Filename, data, format, dryRun)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Location(fmt.Sprintf("/api/products/import/%d", job.ID))
+		return c.Status(fiber.StatusAccepted).JSON(job)
+	}
+
+	result, err := ph.uc.ImportProducts(ctx, bytes.NewReader(data), format, dryRun)
+	if err != nil {
+		if errors.Is(err, usecase.ErrEmptyImport) || errors.Is(err, usecase.ErrInvalidImportFile) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	case dryRun:
+		return c.Status(fiber.StatusOK).JSON(result)
+	default:
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+func (ph *ProductHandler) GetImportJob(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	job, err := ph.uc.GetImportJob(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Import not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(job)
+}
+
+// ExportProducts handles GET /products/export?format=csv|xlsx and returns the
+// catalog in the layout accepted by ImportProducts.
+func (ph *ProductHandler) ExportProducts(c *fiber.Ctx) error {
+	format, err := tabular.ParseFormat(c.Query("format", string(tabular.CSV)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var buf bytes.Buffer
+	if err = ph.uc.ExportProducts(c.Context(), &buf, format); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Attachment("products." + string(format))
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,109 @@
+package infra
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+
+	"github.com/lib/pq"
+)
+
+// GetComponents lists the products inside a bundle with their current
+// stock. Regular products have none.
+func (pr *ProductPGRepository) GetComponents(ctx context.Context, bundleID int) ([]*entity.BundleComponent, error) {
+	query := `SELECT bc.component_id, COALESCE(p.name, ''), bc.quantity,
+			CASE WHEN p.deleted_at IS NULL THEN COALESCE(p.stock, 0) ELSE 0 END
+		FROM bundle_components bc
+		JOIN products p ON p.id = bc.component_id
+		WHERE bc.bundle_id = $1
+		ORDER BY bc.component_id`
+	rows, err := pr.DB.QueryContext(ctx, query, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := []*entity.BundleComponent{}
+	for rows.Next() {
+		component := &entity.BundleComponent{}
+		if err = rows.Scan(&component.ProductID, &component.Name, &component.Quantity, &component.Stock); err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return components, rows.Err()
+}
+
+// SetComponents replaces the components of bundleID. A product becomes a
+// bundle with its first components and a regular product again when they
+// are all removed. Bundles cannot be nested or contain digital products.
+func (pr *ProductPGRepository) SetComponents(ctx context.Context, bundleID int, components []*entity.BundleComponent) error {
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stock int
+	var isBundle, isDigital bool
+	query := `SELECT stock, is_bundle, digital IS NOT NULL FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, query, bundleID).Scan(&stock, &isBundle, &isDigital); err != nil {
+		return err
+	}
+
+	if len(components) > 0 && !isBundle {
+		if isDigital {
+			return entity.ErrDigitalBundle
+		}
+		if stock != 0 {
+			return entity.ErrBundleHasStock
+		}
+
+		var used bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM bundle_components WHERE component_id = $1)`, bundleID).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used {
+			return entity.ErrUsedAsComponent
+		}
+	}
+
+	if len(components) > 0 {
+		ids := make([]int64, 0, len(components))
+		for _, component := range components {
+			ids = append(ids, int64(component.ProductID))
+		}
+
+		// Lock the components so none turns into a bundle concurrently
+		var valid int
+		query = `SELECT COUNT(*) FROM (
+				SELECT id FROM products WHERE id = ANY($1) AND deleted_at IS NULL AND NOT is_bundle AND digital IS NULL FOR UPDATE
+			) c`
+		if err = tx.QueryRowContext(ctx, query, pq.Array(ids)).Scan(&valid); err != nil {
+			return err
+		}
+		if valid != len(components) {
+			return entity.ErrInvalidComponent
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM bundle_components WHERE bundle_id = $1`, bundleID); err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		_, err = tx.ExecContext(ctx, `INSERT INTO bundle_components (bundle_id, component_id, quantity) VALUES ($1, $2, $3)`,
+			bundleID, component.ProductID, component.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET is_bundle = $1 WHERE id = $2`, len(components) > 0, bundleID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
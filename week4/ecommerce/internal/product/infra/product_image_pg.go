@@ -0,0 +1,173 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/product/entity"
+	"errors"
+)
+
+type ProductImagePGRepository struct {
+	DB *sql.DB
+}
+
+func NewProductImagePGRepository(db *sql.DB) *ProductImagePGRepository {
+	return &ProductImagePGRepository{
+		DB: db,
+	}
+}
+
+// syncCoverImage keeps products.image_path pointing at the first gallery image.
+func syncCoverImage(ctx context.Context, tx *sql.Tx, productID int) error {
+	query := `UPDATE products SET image_path = (
+			SELECT original_url FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1
+		) WHERE id = $1`
+	_, err := tx.ExecContext(ctx, query, productID)
+	return err
+}
+
+func (r *ProductImagePGRepository) Create(ctx context.Context, image *entity.ProductImage) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO product_images (product_id, position, original_key, original_url, medium_key, medium_url, thumbnail_key, thumbnail_url)
+		VALUES ($1, (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1), $2, $3, $4, $5, $6, $7)
+		RETURNING id, position, created_at`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		image.ProductID,
+		image.OriginalKey,
+		image.OriginalURL,
+		image.MediumKey,
+		image.MediumURL,
+		image.ThumbnailKey,
+		image.ThumbnailURL,
+	).Scan(&image.ID, &image.Position, &image.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err = syncCoverImage(ctx, tx, image.ProductID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const productImageColumns = `id, product_id, position, original_key, original_url, medium_key, medium_url, thumbnail_key, thumbnail_url, created_at`
+
+func scanProductImage(row interface{ Scan(...interface{}) error }) (*entity.ProductImage, error) {
+	image := &entity.ProductImage{}
+	err := row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.Position,
+		&image.OriginalKey,
+		&image.OriginalURL,
+		&image.MediumKey,
+		&image.MediumURL,
+		&image.ThumbnailKey,
+		&image.ThumbnailURL,
+		&image.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (r *ProductImagePGRepository) GetByID(ctx context.Context, id int) (*entity.ProductImage, error) {
+	query := `SELECT ` + productImageColumns + ` FROM product_images WHERE id = $1`
+	image, err := scanProductImage(r.DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return image, err
+}
+
+func (r *ProductImagePGRepository) GetByProductID(ctx context.Context, productID int) ([]*entity.ProductImage, error) {
+	query := `SELECT ` + productImageColumns + ` FROM product_images WHERE product_id = $1 ORDER BY position, id`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []*entity.ProductImage
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return images, rows.Err()
+}
+
+// Reorder assigns positions following the order of imageIDs. Every image of
+// the product must be listed exactly once.
+func (r *ProductImagePGRepository) Reorder(ctx context.Context, productID int, imageIDs []int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, productID).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count != len(imageIDs) {
+		return errors.New("image list does not match the product gallery")
+	}
+
+	for position, id := range imageIDs {
+		result, err := tx.ExecContext(ctx, `UPDATE product_images SET position = $1 WHERE id = $2 AND product_id = $3`, position, id, productID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return errors.New("image list does not match the product gallery")
+		}
+	}
+
+	if err = syncCoverImage(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ProductImagePGRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productID int
+	err = tx.QueryRowContext(ctx, `DELETE FROM product_images WHERE id = $1 RETURNING product_id`, id).Scan(&productID)
+	if err != nil {
+		return err
+	}
+
+	if err = syncCoverImage(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
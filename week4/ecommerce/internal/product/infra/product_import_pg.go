@@ -0,0 +1,251 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	inventoryEntity "ecommerce/internal/inventory/entity"
+	inventoryInfra "ecommerce/internal/inventory/infra"
+	"ecommerce/internal/product/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Import upserts every row inside one transaction. Each row runs in its own
+// savepoint so a failing row is reported and the rest are still checked.
+// Nothing is committed for a dry run or when any row failed.
+func (pr *ProductPGRepository) Import(ctx context.Context, rows []*entity.ImportRow, dryRun bool) (*entity.ImportResult, error) {
+	result := &entity.ImportResult{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []entity.RowError{},
+	}
+
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		if _, err = tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+
+		created, err := importRow(ctx, tx, row)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); rbErr != nil {
+				return nil, rbErr
+			}
+			result.Errors = append(result.Errors, entity.RowError{Row: row.Row, Message: importErrorMessage(err)})
+			continue
+		}
+
+		if _, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+			return nil, err
+		}
+
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	return result, tx.Commit()
+}
+
+// importRow matches the row by ID, then by SKU, and creates the product when
+// neither matches. It reports whether a product was created.
+func importRow(ctx context.Context, tx *sql.Tx, row *entity.ImportRow) (bool, error) {
+	product := row.Product
+
+	if product.ID == 0 && product.SKU != "" {
+		var deleted bool
+		err := tx.QueryRowContext(ctx, `SELECT id, deleted_at IS NOT NULL FROM products WHERE sku = $1`, product.SKU).Scan(&product.ID, &deleted)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		if deleted {
+			return false, fmt.Errorf("sku belongs to deleted product %d, restore it first", product.ID)
+		}
+	}
+
+	if product.ID == 0 {
+		if product.Name == "" {
+			return false, errors.New("name is required for new products")
+		}
+		return true, insertProduct(ctx, tx, &product, "import")
+	}
+
+	query := "UPDATE products SET"
+	var args []interface{}
+	columns := map[string]interface{}{
+		"sku":         product.SKU,
+		"name":        product.Name,
+		"description": product.Description,
+		"image_path":  product.ImagePath,
+	}
+	for _, column := range entity.ImportColumns {
+		value, ok := columns[column]
+		if ok && row.Has(column) {
+			query += " " + column + " = ?,"
+			args = append(args, value)
+		}
+	}
+
+	// Without columns to change the update still checks that the product exists
+	if len(args) == 0 {
+		query += " id = id"
+	} else {
+		query += " version = version + 1"
+	}
+	query += " WHERE id = ? AND deleted_at IS NULL"
+	args = append(args, product.ID)
+
+	res, err := tx.ExecContext(ctx, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, fmt.Errorf("product %d does not exist", product.ID)
+	}
+
+	if row.Has("price") {
+		err = ApplyPrice(ctx, tx, &entity.PriceChange{
+			ProductID: product.ID,
+			NewPrice:  product.Price,
+			Source:    entity.PriceImport,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if row.Has("stock") {
+		counted, err := stockIsCounted(ctx, tx, product.ID, *product.Stock)
+		if err != nil || !counted {
+			return false, err
+		}
+
+		err = inventoryInfra.ApplyCount(ctx, tx, &inventoryEntity.Movement{
+			ProductID: product.ID,
+			Reason:    inventoryEntity.ReasonAdjustment,
+			Note:      "import",
+		}, *product.Stock)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// stockIsCounted reports whether an imported stock is a count to apply. The
+// stock exported for bundles and digital products is derived, not counted,
+// and a stock that did not change would only add an empty movement, so
+// both are left alone and an export imports back cleanly.
+func stockIsCounted(ctx context.Context, tx *sql.Tx, productID, stock int) (bool, error) {
+	var current int
+	var derived bool
+	query := `SELECT stock, is_bundle OR digital IS NOT NULL FROM products WHERE id = $1`
+	err := tx.QueryRowContext(ctx, query, productID).Scan(&current, &derived)
+	if err != nil {
+		return false, err
+	}
+
+	return !derived && current != stock, nil
+}
+
+func importErrorMessage(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return "sku is already used by another product"
+	}
+
+	return err.Error()
+}
+
+type ProductImportPGRepository struct {
+	DB *sql.DB
+}
+
+func NewProductImportPGRepository(db *sql.DB) *ProductImportPGRepository {
+	return &ProductImportPGRepository{
+		DB: db,
+	}
+}
+
+func (r *ProductImportPGRepository) Create(ctx context.Context, job *entity.ImportJob) error {
+	query := `INSERT INTO product_imports (filename, format, dry_run, status, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, job.Filename, job.Format, job.DryRun, job.Status, job.CreatedBy).
+		Scan(&job.ID, &job.CreatedAt)
+}
+
+func (r *ProductImportPGRepository) GetByID(ctx context.Context, id int) (*entity.ImportJob, error) {
+	query := `SELECT id, filename, format, dry_run, status, result, error, created_by, created_at, finished_at
+		FROM product_imports WHERE id = $1`
+
+	job := &entity.ImportJob{}
+	var result []byte
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.Filename,
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&result,
+		&job.Error,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		job.Result = &entity.ImportResult{}
+		if err = json.Unmarshal(result, job.Result); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+// Update stores the status and outcome of the job. Finished jobs get their
+// finished_at set.
+func (r *ProductImportPGRepository) Update(ctx context.Context, job *entity.ImportJob) error {
+	var result []byte
+	if job.Result != nil {
+		var err error
+		if result, err = json.Marshal(job.Result); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE product_imports
+		SET status = $1, result = $2, error = $3,
+		    finished_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP END
+		WHERE id = $5
+		RETURNING finished_at`
+
+	finished := job.Status == entity.ImportDone || job.Status == entity.ImportFailed
+
+	return r.DB.QueryRowContext(ctx, query, job.Status, result, job.Error, finished, job.ID).Scan(&job.FinishedAt)
+}
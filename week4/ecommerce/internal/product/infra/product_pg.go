@@ -3,9 +3,12 @@ package infra
 import (
 	"context"
 	"database/sql"
+	inventoryEntity "ecommerce/internal/inventory/entity"
+	inventoryInfra "ecommerce/internal/inventory/infra"
 	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/utils"
 	"errors"
-	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -25,34 +28,80 @@ func (pr *ProductPGRepository) Create(ctx context.Context, product *entity.Produ
 		return errors.New("invalid price")
 	}
 
-	if product.Stock < 0 {
+	if product.Stock != nil && *product.Stock < 0 {
 		return errors.New("invalid stock")
 	}
-	
-	query := `INSERT INTO products (name, description, price, stock, image_path) VALUES (?, ?, ?, ?, ?)`
+
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = insertProduct(ctx, tx, product, "initial stock"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertProduct creates the product with zero stock and books its initial
+// quantity as a receipt, so the ledger accounts for every unit.
+func insertProduct(ctx context.Context, tx *sql.Tx, product *entity.Product, note string) error {
+	query := `INSERT INTO products (sku, name, description, price, stock, image_path) VALUES (NULLIF(?, ''), ?, ?, ?, 0, ?) RETURNING id`
 
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
-	
-	_, err := pr.DB.ExecContext(
+
+	err := tx.QueryRowContext(
 		ctx,
 		query,
+		product.SKU,
 		product.Name,
 		product.Description,
 		product.Price,
-		product.Stock,
 		product.ImagePath,
-	)
+	).Scan(&product.ID)
+
+	if err != nil {
+		return err
+	}
 
+	err = insertPriceChange(ctx, tx, &entity.PriceChange{
+		ProductID: product.ID,
+		NewPrice:  product.Price,
+		Source:    entity.PriceInitial,
+	})
 	if err != nil {
 		return err
 	}
 
+	if product.Stock != nil && *product.Stock > 0 {
+		return inventoryInfra.ApplyMovement(ctx, tx, &inventoryEntity.Movement{
+			ProductID: product.ID,
+			Quantity:  *product.Stock,
+			Reason:    inventoryEntity.ReasonReceipt,
+			Note:      note,
+		})
+	}
+
 	return nil
 }
 
-func (pr *ProductPGRepository) GetAll(ctx context.Context) ([]*entity.Product, error) {
-	query := `SELECT id, COALESCE(name, ''), COALESCE(price, 0.0), COALESCE(stock, 0), COALESCE(description, ''), COALESCE(image_path, '') FROM products`
-	rows, err := pr.DB.QueryContext(ctx, query)
+// ratingColumns selects the average rating and review count kept up to date
+// by the review module.
+const ratingColumns = `COALESCE(ROUND(rating_sum::numeric / NULLIF(rating_count, 0), 2), 0)::float8, rating_count`
+
+// stockColumn selects the sellable quantity, which for bundles and licence
+// key products is derived from their components and key pool. Downloads
+// never run out and stay NULL.
+const stockColumn = `CASE WHEN digital = 'download' THEN NULL ELSE COALESCE((SELECT available FROM product_availability a WHERE a.product_id = products.id), 0) END`
+
+// GetAll returns the catalog. Soft deleted products are only included when
+// includeDeleted is set.
+func (pr *ProductPGRepository) GetAll(ctx context.Context, includeDeleted bool) ([]*entity.Product, error) {
+	query := `SELECT id, COALESCE(sku, ''), COALESCE(name, ''), COALESCE(price, 0.0), ` + stockColumn + `, is_bundle, COALESCE(digital, ''), COALESCE(description, ''), COALESCE(image_path, ''), version, updated_at, deleted_at, ` + ratingColumns + `
+		FROM products WHERE $1 OR deleted_at IS NULL ORDER BY id`
+	rows, err := pr.DB.QueryContext(ctx, query, includeDeleted)
 
 	if err != nil {
 		return nil, err
@@ -69,7 +118,7 @@ func (pr *ProductPGRepository) GetAll(ctx context.Context) ([]*entity.Product, e
 
 	for rows.Next() {
 		product := &entity.Product{}
-		err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Stock, &product.Description, &product.ImagePath)
+		err := rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Price, &product.Stock, &product.IsBundle, &product.Digital, &product.Description, &product.ImagePath, &product.Version, &product.UpdatedAt, &product.DeletedAt, &product.AverageRating, &product.ReviewCount)
 
 		if err != nil {
 			return nil, err
@@ -85,18 +134,26 @@ func (pr *ProductPGRepository) GetByID(ctx context.Context, id int) (*entity.Pro
 
 	product := &entity.Product{}
 
-	query := `SELECT id, COALESCE(name, ''), COALESCE(description, ''), COALESCE(price, 0.0), COALESCE(stock, 0), COALESCE(image_path, '') FROM products WHERE id = $1`
+	query := `SELECT id, COALESCE(sku, ''), COALESCE(name, ''), COALESCE(description, ''), COALESCE(price, 0.0), ` + stockColumn + `, is_bundle, COALESCE(digital, ''), COALESCE(image_path, ''), version, updated_at, ` + ratingColumns + `
+		FROM products WHERE id = $1 AND deleted_at IS NULL`
 
 	err := pr.DB.QueryRowContext(
 		ctx,
 		query,
 		id,
-	).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Stock, &product.ImagePath)
+	).Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Stock, &product.IsBundle, &product.Digital, &product.ImagePath, &product.Version, &product.UpdatedAt, &product.AverageRating, &product.ReviewCount)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if product.IsBundle {
+		product.Components, err = pr.GetComponents(ctx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return product, nil
 }
 
@@ -107,7 +164,7 @@ func (pr *ProductPGRepository) GetByName(ctx context.Context, name string) ([]*e
 		rows     *sql.Rows
 	)
 
-	query := `SELECT * FROM products WHERE products.document @@ to_tsquery('?')`
+	query := `SELECT * FROM products WHERE products.document @@ to_tsquery('?') AND deleted_at IS NULL`
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
 	rows, err = pr.DB.QueryContext(
@@ -134,12 +191,26 @@ func (pr *ProductPGRepository) GetByName(ctx context.Context, name string) ([]*e
 	return products, nil
 }
 
+// Update applies the non-empty fields of product. With a product.Version it
+// only applies while the stored version is the same and returns
+// utils.ErrVersionConflict otherwise. product.Version is set to the new
+// version.
 func (pr *ProductPGRepository) Update(ctx context.Context, product *entity.Product) error {
+	tx, err := pr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Initialize the query and arguments
 	query := "UPDATE products SET"
 	var args []interface{}
 
 	// Check and append non-empty fields
+	if product.SKU != "" {
+		query += " sku = ?,"
+		args = append(args, product.SKU)
+	}
 	if product.Name != "" {
 		query += " name = ?,"
 		args = append(args, product.Name)
@@ -148,41 +219,150 @@ func (pr *ProductPGRepository) Update(ctx context.Context, product *entity.Produ
 		query += " description = ?,"
 		args = append(args, product.Description)
 	}
-	if product.Price != 0 {
-		query += " price = ?,"
-		args = append(args, product.Price)
-	}
-	if product.Stock != 0 {
-		query += " stock = ?,"
-		args = append(args, product.Stock)
-	}
 	if product.ImagePath != "" {
 		query += " image_path = ?,"
 		args = append(args, product.ImagePath)
 	}
 
-	// Remove the trailing comma and add the WHERE clause
-	query = strings.TrimSuffix(query, ",")
-	query += " WHERE id = ?"
+	// The version is checked and bumped even when only price or stock change,
+	// which also locks the row for the rest of the transaction
+	query += " version = version + 1 WHERE id = ? AND deleted_at IS NULL"
 	args = append(args, product.ID)
+	if product.Version > 0 {
+		query += " AND version = ?"
+		args = append(args, product.Version)
+	}
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
 	// Execute the query
-	_, err := pr.DB.ExecContext(ctx, query, args...)
+	err = expectOneRow(tx.ExecContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) && product.Version > 0 {
+		err = versionConflict(ctx, tx, product.ID)
+	}
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Price changes are kept in the price history
+	if product.Price != 0 {
+		err = ApplyPrice(ctx, tx, &entity.PriceChange{
+			ProductID: product.ID,
+			NewPrice:  product.Price,
+			Source:    entity.PriceManual,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// A new stock value is recorded in the ledger as a manual adjustment
+	if product.Stock != nil {
+		err = inventoryInfra.ApplyCount(ctx, tx, &inventoryEntity.Movement{
+			ProductID: product.ID,
+			Reason:    inventoryEntity.ReasonAdjustment,
+			Note:      "product update",
+		}, *product.Stock)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT version FROM products WHERE id = $1`, product.ID).Scan(&product.Version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// versionConflict tells a stale version apart from a missing product after a
+// versioned update matched no row.
+func versionConflict(ctx context.Context, tx *sql.Tx, id int) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return utils.ErrVersionConflict
 }
 
+// Delete soft deletes the product. Orders keep pointing at the row, and
+// Restore brings it back until purge-deleted removes it for good.
 func (pr *ProductPGRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM products WHERE id = $1`
+	query := `UPDATE products SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+
+	return expectOneRow(pr.DB.ExecContext(ctx, query, id))
+}
+
+func (pr *ProductPGRepository) Restore(ctx context.Context, id int) error {
+	query := `UPDATE products SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	return expectOneRow(pr.DB.ExecContext(ctx, query, id))
+}
+
+// GetPurgeable returns products deleted before the cutoff that no order
+// line, bundle or bundle sold in an order references.
+func (pr *ProductPGRepository) GetPurgeable(ctx context.Context, deletedBefore time.Time) ([]int, error) {
+	query := `SELECT p.id FROM products p
+		WHERE p.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM order_lines ol WHERE ol.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.component_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM order_line_components olc WHERE olc.product_id = p.id)
+		ORDER BY p.id`
+	rows, err := pr.DB.QueryContext(ctx, query, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
 
-	_, err := pr.DB.ExecContext(ctx, query, id)
+	return ids, rows.Err()
+}
+
+// Purge hard deletes one product returned by GetPurgeable. The conditions
+// are checked again so an order placed in the meantime keeps its product.
+// Images, movements and stock levels go with it through ON DELETE CASCADE.
+func (pr *ProductPGRepository) Purge(ctx context.Context, id int, deletedBefore time.Time) (bool, error) {
+	query := `DELETE FROM products p
+		WHERE p.id = $1
+		  AND p.deleted_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM order_lines ol WHERE ol.product_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.component_id = p.id)
+		  AND NOT EXISTS (SELECT 1 FROM order_line_components olc WHERE olc.product_id = p.id)`
+
+	err := expectOneRow(pr.DB.ExecContext(ctx, query, id, deletedBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
 
+// expectOneRow turns an update that matched nothing into sql.ErrNoRows.
+func expectOneRow(result sql.Result, err error) error {
 	if err != nil {
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
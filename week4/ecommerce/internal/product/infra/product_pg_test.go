@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// A component sold inside a bundle stays referenced by order_line_components
+// after it was taken out of the bundle, so purging it would break the
+// foreign key. Both queries have to leave it alone.
+const soldInBundle = `NOT EXISTS \(SELECT 1 FROM order_line_components olc WHERE olc\.product_id = p\.id\)`
+
+func TestPurgeKeepsComponentsSoldInBundles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewProductPGRepository(db)
+	cutoff := time.Now()
+
+	mock.ExpectQuery(`FROM products p[\s\S]*` + soldInBundle).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`DELETE FROM products p[\s\S]*`+soldInBundle).
+		WithArgs(7, cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ids, err := repo.GetPurgeable(context.Background(), cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("expected nothing to purge, got %v", ids)
+	}
+
+	purged, err := repo.Purge(context.Background(), 7, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged {
+		t.Error("expected the component to be kept")
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
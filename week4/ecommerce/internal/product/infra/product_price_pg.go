@@ -0,0 +1,321 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/utils"
+	"errors"
+	"time"
+)
+
+var ErrScheduleFinished = errors.New("price schedule has already finished")
+
+// ApplyPrice sets the price of change.ProductID to change.NewPrice and
+// records the change in the price history. change.OldPrice is filled in
+// with the price before the call; nothing is recorded when it is unchanged.
+// Every price update in the code base should go through here.
+func ApplyPrice(ctx context.Context, tx *sql.Tx, change *entity.PriceChange) error {
+	var old float64
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(price, 0) FROM products WHERE id = $1 FOR UPDATE`, change.ProductID).Scan(&old)
+	if err != nil {
+		return err
+	}
+	change.OldPrice = &old
+
+	if old == change.NewPrice {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET price = $1, version = version + 1 WHERE id = $2`, change.NewPrice, change.ProductID)
+	if err != nil {
+		return err
+	}
+
+	return insertPriceChange(ctx, tx, change)
+}
+
+func insertPriceChange(ctx context.Context, tx *sql.Tx, change *entity.PriceChange) error {
+	if change.Actor == "" {
+		change.Actor = utils.ActorFromContext(ctx)
+	}
+
+	query := `INSERT INTO product_prices (product_id, old_price, new_price, source, schedule_id, actor)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING id, created_at`
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		change.ProductID,
+		change.OldPrice,
+		change.NewPrice,
+		change.Source,
+		change.ScheduleID,
+		change.Actor,
+	).Scan(&change.ID, &change.CreatedAt)
+}
+
+type PricePGRepository struct {
+	DB *sql.DB
+}
+
+func NewPricePGRepository(db *sql.DB) *PricePGRepository {
+	return &PricePGRepository{
+		DB: db,
+	}
+}
+
+func (r *PricePGRepository) GetHistory(ctx context.Context, productID int) ([]*entity.PriceChange, error) {
+	query := `SELECT id, product_id, old_price, new_price, source, schedule_id, COALESCE(actor, ''), created_at
+		FROM product_prices WHERE product_id = $1 ORDER BY id`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*entity.PriceChange{}
+	for rows.Next() {
+		change := &entity.PriceChange{}
+		err = rows.Scan(
+			&change.ID,
+			&change.ProductID,
+			&change.OldPrice,
+			&change.NewPrice,
+			&change.Source,
+			&change.ScheduleID,
+			&change.Actor,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// CreateSchedule stores a pending schedule unless it overlaps a pending or
+// active schedule of the same product. A schedule without an end only
+// occupies its start time.
+func (r *PricePGRepository) CreateSchedule(ctx context.Context, schedule *entity.PriceSchedule) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the product serialises concurrent schedule requests
+	var id int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, schedule.ProductID).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	var overlaps bool
+	query := `SELECT EXISTS (
+			SELECT 1 FROM price_schedules
+			WHERE product_id = $1
+			  AND status IN ('pending', 'active')
+			  AND starts_at < COALESCE($3::timestamp, $2::timestamp + INTERVAL '1 second')
+			  AND COALESCE(ends_at, starts_at + INTERVAL '1 second') > $2::timestamp
+		)`
+	err = tx.QueryRowContext(ctx, query, schedule.ProductID, schedule.StartsAt, schedule.EndsAt).Scan(&overlaps)
+	if err != nil {
+		return err
+	}
+	if overlaps {
+		return entity.ErrScheduleOverlap
+	}
+
+	schedule.CreatedBy = utils.ActorFromContext(ctx)
+	query = `INSERT INTO price_schedules (product_id, price, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, status, created_at`
+	err = tx.QueryRowContext(ctx, query, schedule.ProductID, schedule.Price, schedule.StartsAt, schedule.EndsAt, schedule.CreatedBy).
+		Scan(&schedule.ID, &schedule.Status, &schedule.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const scheduleColumns = `id, product_id, price, starts_at, ends_at, status, previous_price, COALESCE(created_by, ''), created_at`
+
+func scanSchedule(row interface{ Scan(...interface{}) error }) (*entity.PriceSchedule, error) {
+	schedule := &entity.PriceSchedule{}
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.ProductID,
+		&schedule.Price,
+		&schedule.StartsAt,
+		&schedule.EndsAt,
+		&schedule.Status,
+		&schedule.PreviousPrice,
+		&schedule.CreatedBy,
+		&schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (r *PricePGRepository) GetSchedules(ctx context.Context, productID int) ([]*entity.PriceSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM price_schedules WHERE product_id = $1 ORDER BY starts_at, id`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*entity.PriceSchedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// CancelSchedule drops a pending schedule. An active one is ended right away,
+// putting the previous price back.
+func (r *PricePGRepository) CancelSchedule(ctx context.Context, productID, scheduleID int) (*entity.PriceSchedule, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + scheduleColumns + ` FROM price_schedules WHERE id = $1 AND product_id = $2 FOR UPDATE`
+	schedule, err := scanSchedule(tx.QueryRowContext(ctx, query, scheduleID, productID))
+	if err != nil {
+		return nil, err
+	}
+
+	switch schedule.Status {
+	case entity.SchedulePending:
+	case entity.ScheduleActive:
+		if err = revertSchedule(ctx, tx, schedule); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrScheduleFinished
+	}
+
+	schedule.Status = entity.ScheduleCancelled
+	_, err = tx.ExecContext(ctx, `UPDATE price_schedules SET status = $1 WHERE id = $2`, schedule.Status, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, tx.Commit()
+}
+
+// StartDueSchedules applies the price of every pending schedule that has
+// started. Schedules without an end are done once applied.
+func (r *PricePGRepository) StartDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	return r.processDue(ctx, `SELECT `+scheduleColumns+` FROM price_schedules
+		WHERE status = 'pending' AND starts_at <= $1
+		ORDER BY starts_at, id
+		FOR UPDATE SKIP LOCKED`, now, func(tx *sql.Tx, schedule *entity.PriceSchedule) error {
+		change := &entity.PriceChange{
+			ProductID:  schedule.ProductID,
+			NewPrice:   schedule.Price,
+			Source:     entity.PriceScheduleStart,
+			ScheduleID: &schedule.ID,
+		}
+		if err := ApplyPrice(ctx, tx, change); err != nil {
+			return err
+		}
+
+		status := entity.ScheduleActive
+		if schedule.EndsAt == nil {
+			status = entity.ScheduleDone
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE price_schedules SET status = $1, previous_price = $2 WHERE id = $3`, status, *change.OldPrice, schedule.ID)
+		return err
+	})
+}
+
+// EndDueSchedules puts the previous price back for every active schedule
+// that has ended.
+func (r *PricePGRepository) EndDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	return r.processDue(ctx, `SELECT `+scheduleColumns+` FROM price_schedules
+		WHERE status = 'active' AND ends_at <= $1
+		ORDER BY ends_at, id
+		FOR UPDATE SKIP LOCKED`, now, func(tx *sql.Tx, schedule *entity.PriceSchedule) error {
+		if err := revertSchedule(ctx, tx, schedule); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE price_schedules SET status = 'done' WHERE id = $1`, schedule.ID)
+		return err
+	})
+}
+
+// processDue runs fn for every schedule selected by query in one
+// transaction and returns how many were processed.
+func (r *PricePGRepository) processDue(ctx context.Context, query string, now time.Time, fn func(tx *sql.Tx, schedule *entity.PriceSchedule) error) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var schedules []*entity.PriceSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, schedule := range schedules {
+		if err = fn(tx, schedule); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(schedules), tx.Commit()
+}
+
+// revertSchedule restores the price from before the schedule started. A
+// price changed by hand while the schedule was active is left alone.
+func revertSchedule(ctx context.Context, tx *sql.Tx, schedule *entity.PriceSchedule) error {
+	if schedule.PreviousPrice == nil {
+		return nil
+	}
+
+	var current float64
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(price, 0) FROM products WHERE id = $1 FOR UPDATE`, schedule.ProductID).Scan(&current)
+	if err != nil || current != schedule.Price {
+		return err
+	}
+
+	return ApplyPrice(ctx, tx, &entity.PriceChange{
+		ProductID:  schedule.ProductID,
+		NewPrice:   *schedule.PreviousPrice,
+		Source:     entity.PriceScheduleEnd,
+		ScheduleID: &schedule.ID,
+	})
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/product/repository/price_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/product/repository/price_repository.go -destination=internal/product/mocks/mock_price_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/product/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIPriceRepository is a mock of IPriceRepository interface.
+type MockIPriceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPriceRepositoryMockRecorder
+}
+
+// MockIPriceRepositoryMockRecorder is the mock recorder for MockIPriceRepository.
+type MockIPriceRepositoryMockRecorder struct {
+	mock *MockIPriceRepository
+}
+
+// NewMockIPriceRepository creates a new mock instance.
+func NewMockIPriceRepository(ctrl *gomock.Controller) *MockIPriceRepository {
+	mock := &MockIPriceRepository{ctrl: ctrl}
+	mock.recorder = &MockIPriceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPriceRepository) EXPECT() *MockIPriceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelSchedule mocks base method.
+func (m *MockIPriceRepository) CancelSchedule(ctx context.Context, productID, scheduleID int) (*entity.PriceSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, productID, scheduleID)
+	ret0, _ := ret[0].(*entity.PriceSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockIPriceRepositoryMockRecorder) CancelSchedule(ctx, productID, scheduleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockIPriceRepository)(nil).CancelSchedule), ctx, productID, scheduleID)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockIPriceRepository) CreateSchedule(ctx context.Context, schedule *entity.PriceSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockIPriceRepositoryMockRecorder) CreateSchedule(ctx, schedule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockIPriceRepository)(nil).CreateSchedule), ctx, schedule)
+}
+
+// EndDueSchedules mocks base method.
+func (m *MockIPriceRepository) EndDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndDueSchedules", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndDueSchedules indicates an expected call of EndDueSchedules.
+func (mr *MockIPriceRepositoryMockRecorder) EndDueSchedules(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndDueSchedules", reflect.TypeOf((*MockIPriceRepository)(nil).EndDueSchedules), ctx, now)
+}
+
+// GetHistory mocks base method.
+func (m *MockIPriceRepository) GetHistory(ctx context.Context, productID int) ([]*entity.PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, productID)
+	ret0, _ := ret[0].([]*entity.PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockIPriceRepositoryMockRecorder) GetHistory(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockIPriceRepository)(nil).GetHistory), ctx, productID)
+}
+
+// GetSchedules mocks base method.
+func (m *MockIPriceRepository) GetSchedules(ctx context.Context, productID int) ([]*entity.PriceSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedules", ctx, productID)
+	ret0, _ := ret[0].([]*entity.PriceSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedules indicates an expected call of GetSchedules.
+func (mr *MockIPriceRepositoryMockRecorder) GetSchedules(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedules", reflect.TypeOf((*MockIPriceRepository)(nil).GetSchedules), ctx, productID)
+}
+
+// StartDueSchedules mocks base method.
+func (m *MockIPriceRepository) StartDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartDueSchedules", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartDueSchedules indicates an expected call of StartDueSchedules.
+func (mr *MockIPriceRepositoryMockRecorder) StartDueSchedules(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDueSchedules", reflect.TypeOf((*MockIPriceRepository)(nil).StartDueSchedules), ctx, now)
+}
@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/product/repository/product_image_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/product/repository/product_image_repository.go -destination=internal/product/mocks/mock_product_image_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/product/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIProductImageRepository is a mock of IProductImageRepository interface.
+type MockIProductImageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIProductImageRepositoryMockRecorder
+}
+
+// MockIProductImageRepositoryMockRecorder is the mock recorder for MockIProductImageRepository.
+type MockIProductImageRepositoryMockRecorder struct {
+	mock *MockIProductImageRepository
+}
+
+// NewMockIProductImageRepository creates a new mock instance.
+func NewMockIProductImageRepository(ctrl *gomock.Controller) *MockIProductImageRepository {
+	mock := &MockIProductImageRepository{ctrl: ctrl}
+	mock.recorder = &MockIProductImageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIProductImageRepository) EXPECT() *MockIProductImageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIProductImageRepository) Create(ctx context.Context, image *entity.ProductImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIProductImageRepositoryMockRecorder) Create(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIProductImageRepository)(nil).Create), ctx, image)
+}
+
+// Delete mocks base method.
+func (m *MockIProductImageRepository) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIProductImageRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIProductImageRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockIProductImageRepository) GetByID(ctx context.Context, id int) (*entity.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIProductImageRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIProductImageRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByProductID mocks base method.
+func (m *MockIProductImageRepository) GetByProductID(ctx context.Context, productID int) ([]*entity.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProductID", ctx, productID)
+	ret0, _ := ret[0].([]*entity.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProductID indicates an expected call of GetByProductID.
+func (mr *MockIProductImageRepositoryMockRecorder) GetByProductID(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProductID", reflect.TypeOf((*MockIProductImageRepository)(nil).GetByProductID), ctx, productID)
+}
+
+// Reorder mocks base method.
+func (m *MockIProductImageRepository) Reorder(ctx context.Context, productID int, imageIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ctx, productID, imageIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockIProductImageRepositoryMockRecorder) Reorder(ctx, productID, imageIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockIProductImageRepository)(nil).Reorder), ctx, productID, imageIDs)
+}
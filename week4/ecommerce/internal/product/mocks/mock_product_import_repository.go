@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/product/repository/product_import_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/product/repository/product_import_repository.go -destination=internal/product/mocks/mock_product_import_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/product/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIProductImportRepository is a mock of IProductImportRepository interface.
+type MockIProductImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIProductImportRepositoryMockRecorder
+}
+
+// MockIProductImportRepositoryMockRecorder is the mock recorder for MockIProductImportRepository.
+type MockIProductImportRepositoryMockRecorder struct {
+	mock *MockIProductImportRepository
+}
+
+// NewMockIProductImportRepository creates a new mock instance.
+func NewMockIProductImportRepository(ctrl *gomock.Controller) *MockIProductImportRepository {
+	mock := &MockIProductImportRepository{ctrl: ctrl}
+	mock.recorder = &MockIProductImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIProductImportRepository) EXPECT() *MockIProductImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIProductImportRepository) Create(ctx context.Context, job *entity.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIProductImportRepositoryMockRecorder) Create(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIProductImportRepository)(nil).Create), ctx, job)
+}
+
+// GetByID mocks base method.
+func (m *MockIProductImportRepository) GetByID(ctx context.Context, id int) (*entity.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIProductImportRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIProductImportRepository)(nil).GetByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockIProductImportRepository) Update(ctx context.Context, job *entity.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIProductImportRepositoryMockRecorder) Update(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIProductImportRepository)(nil).Update), ctx, job)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	entity "ecommerce/internal/product/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -69,18 +70,18 @@ func (mr *MockIProductRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 }
 
 // GetAll mocks base method.
-func (m *MockIProductRepository) GetAll(ctx context.Context) ([]*entity.Product, error) {
+func (m *MockIProductRepository) GetAll(ctx context.Context, includeDeleted bool) ([]*entity.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetAll", ctx, includeDeleted)
 	ret0, _ := ret[0].([]*entity.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockIProductRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+func (mr *MockIProductRepositoryMockRecorder) GetAll(ctx, includeDeleted any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIProductRepository)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIProductRepository)(nil).GetAll), ctx, includeDeleted)
 }
 
 // GetByID mocks base method.
@@ -113,6 +114,94 @@ func (mr *MockIProductRepositoryMockRecorder) GetByName(ctx, name any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockIProductRepository)(nil).GetByName), ctx, name)
 }
 
+// GetComponents mocks base method.
+func (m *MockIProductRepository) GetComponents(ctx context.Context, bundleID int) ([]*entity.BundleComponent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComponents", ctx, bundleID)
+	ret0, _ := ret[0].([]*entity.BundleComponent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComponents indicates an expected call of GetComponents.
+func (mr *MockIProductRepositoryMockRecorder) GetComponents(ctx, bundleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComponents", reflect.TypeOf((*MockIProductRepository)(nil).GetComponents), ctx, bundleID)
+}
+
+// GetPurgeable mocks base method.
+func (m *MockIProductRepository) GetPurgeable(ctx context.Context, deletedBefore time.Time) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurgeable", ctx, deletedBefore)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurgeable indicates an expected call of GetPurgeable.
+func (mr *MockIProductRepositoryMockRecorder) GetPurgeable(ctx, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurgeable", reflect.TypeOf((*MockIProductRepository)(nil).GetPurgeable), ctx, deletedBefore)
+}
+
+// Import mocks base method.
+func (m *MockIProductRepository) Import(ctx context.Context, rows []*entity.ImportRow, dryRun bool) (*entity.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, rows, dryRun)
+	ret0, _ := ret[0].(*entity.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockIProductRepositoryMockRecorder) Import(ctx, rows, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockIProductRepository)(nil).Import), ctx, rows, dryRun)
+}
+
+// Purge mocks base method.
+func (m *MockIProductRepository) Purge(ctx context.Context, id int, deletedBefore time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id, deletedBefore)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockIProductRepositoryMockRecorder) Purge(ctx, id, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockIProductRepository)(nil).Purge), ctx, id, deletedBefore)
+}
+
+// Restore mocks base method.
+func (m *MockIProductRepository) Restore(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockIProductRepositoryMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockIProductRepository)(nil).Restore), ctx, id)
+}
+
+// SetComponents mocks base method.
+func (m *MockIProductRepository) SetComponents(ctx context.Context, bundleID int, components []*entity.BundleComponent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetComponents", ctx, bundleID, components)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetComponents indicates an expected call of SetComponents.
+func (mr *MockIProductRepositoryMockRecorder) SetComponents(ctx, bundleID, components any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComponents", reflect.TypeOf((*MockIProductRepository)(nil).SetComponents), ctx, bundleID, components)
+}
+
 // Update mocks base method.
 func (m *MockIProductRepository) Update(ctx context.Context, product *entity.Product) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+	"time"
+)
+
+type IPriceRepository interface {
+	GetHistory(ctx context.Context, productID int) ([]*entity.PriceChange, error)
+	CreateSchedule(ctx context.Context, schedule *entity.PriceSchedule) error
+	GetSchedules(ctx context.Context, productID int) ([]*entity.PriceSchedule, error)
+	CancelSchedule(ctx context.Context, productID, scheduleID int) (*entity.PriceSchedule, error)
+	StartDueSchedules(ctx context.Context, now time.Time) (int, error)
+	EndDueSchedules(ctx context.Context, now time.Time) (int, error)
+}
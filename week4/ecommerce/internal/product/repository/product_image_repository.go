@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+)
+
+type IProductImageRepository interface {
+	Create(ctx context.Context, image *entity.ProductImage) error
+	GetByID(ctx context.Context, id int) (*entity.ProductImage, error)
+	GetByProductID(ctx context.Context, productID int) ([]*entity.ProductImage, error)
+	Reorder(ctx context.Context, productID int, imageIDs []int) error
+	Delete(ctx context.Context, id int) error
+}
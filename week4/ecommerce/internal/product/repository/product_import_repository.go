@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+)
+
+type IProductImportRepository interface {
+	Create(ctx context.Context, job *entity.ImportJob) error
+	GetByID(ctx context.Context, id int) (*entity.ImportJob, error)
+	Update(ctx context.Context, job *entity.ImportJob) error
+}
@@ -3,13 +3,20 @@ package repository
 import (
 	"context"
 	"ecommerce/internal/product/entity"
+	"time"
 )
 
 type IProductRepository interface {
 	Create(ctx context.Context, product *entity.Product) error
-	GetAll(ctx context.Context) ([]*entity.Product, error)
+	GetAll(ctx context.Context, includeDeleted bool) ([]*entity.Product, error)
 	GetByID(ctx context.Context, id int) (*entity.Product, error)
 	GetByName(ctx context.Context, name string) ([]*entity.Product, error)
 	Update(ctx context.Context, product *entity.Product) error
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	GetPurgeable(ctx context.Context, deletedBefore time.Time) ([]int, error)
+	Purge(ctx context.Context, id int, deletedBefore time.Time) (bool, error)
+	GetComponents(ctx context.Context, bundleID int) ([]*entity.BundleComponent, error)
+	SetComponents(ctx context.Context, bundleID int, components []*entity.BundleComponent) error
+	Import(ctx context.Context, rows []*entity.ImportRow, dryRun bool) (*entity.ImportResult, error)
 }
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+	"ecommerce/internal/product/repository"
+	"ecommerce/pkg/utils"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid price schedule, price must not be negative and ends_at must be after starts_at and in the future")
+
+type PriceUsecase struct {
+	priceRepo repository.IPriceRepository
+	now       func() time.Time
+}
+
+func NewPriceUsecase(priceRepo repository.IPriceRepository) *PriceUsecase {
+	return &PriceUsecase{
+		priceRepo: priceRepo,
+		now:       time.Now,
+	}
+}
+
+func (pu *PriceUsecase) GetPriceHistory(ctx context.Context, productID int) ([]*entity.PriceChange, error) {
+	return pu.priceRepo.GetHistory(ctx, productID)
+}
+
+// SchedulePrice plans a price change. A zero StartsAt starts it on the next
+// scheduler run; without EndsAt the new price stays.
+func (pu *PriceUsecase) SchedulePrice(ctx context.Context, schedule *entity.PriceSchedule) error {
+	now := pu.now()
+	if schedule.StartsAt.IsZero() {
+		schedule.StartsAt = now
+	}
+
+	if schedule.Price < 0 {
+		return ErrInvalidSchedule
+	}
+	if schedule.EndsAt != nil && (!schedule.EndsAt.After(schedule.StartsAt) || !schedule.EndsAt.After(now)) {
+		return ErrInvalidSchedule
+	}
+
+	return pu.priceRepo.CreateSchedule(ctx, schedule)
+}
+
+func (pu *PriceUsecase) GetPriceSchedules(ctx context.Context, productID int) ([]*entity.PriceSchedule, error) {
+	return pu.priceRepo.GetSchedules(ctx, productID)
+}
+
+func (pu *PriceUsecase) CancelPriceSchedule(ctx context.Context, productID, scheduleID int) (*entity.PriceSchedule, error) {
+	return pu.priceRepo.CancelSchedule(ctx, productID, scheduleID)
+}
+
+// ApplyPriceSchedules starts and ends every schedule that is due. It is run
+// by the scheduler; schedules that start and end in the same interval are
+// reverted in the same run.
+func (pu *PriceUsecase) ApplyPriceSchedules(ctx context.Context) error {
+	ctx = utils.WithActor(ctx, "scheduler")
+	now := pu.now()
+
+	started, err := pu.priceRepo.StartDueSchedules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	ended, err := pu.priceRepo.EndDueSchedules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if started > 0 || ended > 0 {
+		log.Printf("price schedules: %d started, %d ended", started, ended)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+	mock_repository "ecommerce/internal/product/mocks"
+	"ecommerce/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type PriceUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockRepo     *mock_repository.MockIPriceRepository
+	now          time.Time
+	priceUsecase *PriceUsecase
+}
+
+func (suite *PriceUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIPriceRepository(suite.mockCtrl)
+	suite.now = time.Date(2024, 11, 29, 9, 0, 0, 0, time.UTC)
+	suite.priceUsecase = NewPriceUsecase(suite.mockRepo)
+	suite.priceUsecase.now = func() time.Time { return suite.now }
+}
+
+func (suite *PriceUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestPriceUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PriceUsecaseTestSuite))
+}
+
+func (suite *PriceUsecaseTestSuite) TestSchedulePrice() {
+	end := suite.now.Add(72 * time.Hour)
+	past := suite.now.Add(-time.Hour)
+
+	testCases := []struct {
+		name          string
+		input         *entity.PriceSchedule
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Successful sale schedule",
+			input: &entity.PriceSchedule{ProductID: 1, Price: 8, StartsAt: suite.now.Add(time.Hour), EndsAt: &end},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Missing start means now",
+			input: &entity.PriceSchedule{ProductID: 1, Price: 8},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().CreateSchedule(gomock.Any(), &entity.PriceSchedule{ProductID: 1, Price: 8, StartsAt: suite.now}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed schedule - Ends in the past",
+			input:         &entity.PriceSchedule{ProductID: 1, Price: 8, StartsAt: past.Add(-time.Hour), EndsAt: &past},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidSchedule,
+		},
+		{
+			name:          "Failed schedule - Negative price",
+			input:         &entity.PriceSchedule{ProductID: 1, Price: -1},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidSchedule,
+		},
+		{
+			name:  "Failed schedule - Overlap",
+			input: &entity.PriceSchedule{ProductID: 1, Price: 8, EndsAt: &end},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(entity.ErrScheduleOverlap)
+			},
+			expectedError: entity.ErrScheduleOverlap,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.priceUsecase.SchedulePrice(context.Background(), tc.input)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *PriceUsecaseTestSuite) TestApplyPriceSchedules() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Starts then ends due schedules as the scheduler",
+			mockBehavior: func() {
+				gomock.InOrder(
+					suite.mockRepo.EXPECT().StartDueSchedules(gomock.Any(), suite.now).DoAndReturn(func(ctx context.Context, _ time.Time) (int, error) {
+						suite.Equal("scheduler", utils.ActorFromContext(ctx))
+						return 2, nil
+					}),
+					suite.mockRepo.EXPECT().EndDueSchedules(gomock.Any(), suite.now).Return(1, nil),
+				)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Failed run - Start error skips ending",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().StartDueSchedules(gomock.Any(), suite.now).Return(0, errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.priceUsecase.ApplyPriceSchedules(context.Background())
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
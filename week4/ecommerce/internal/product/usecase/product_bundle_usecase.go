@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+)
+
+func (pu *ProductUsecase) GetBundleComponents(ctx context.Context, id int) ([]*entity.BundleComponent, error) {
+	return pu.productRepo.GetComponents(ctx, id)
+}
+
+// SetBundleComponents makes the product a bundle of components, or a regular
+// product again when components is empty. It returns the stored components.
+func (pu *ProductUsecase) SetBundleComponents(ctx context.Context, id int, components []*entity.BundleComponent) ([]*entity.BundleComponent, error) {
+	if err := entity.ValidateComponents(id, components); err != nil {
+		return nil, err
+	}
+
+	if err := pu.productRepo.SetComponents(ctx, id, components); err != nil {
+		return nil, err
+	}
+	pu.invalidate(ctx, id)
+
+	return pu.productRepo.GetComponents(ctx, id)
+}
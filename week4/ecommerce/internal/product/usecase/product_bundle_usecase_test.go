@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *ProductUsecaseTestSuite) TestSetBundleComponents() {
+	testCases := []struct {
+		name          string
+		components    []*entity.BundleComponent
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:       "Successful bundle",
+			components: []*entity.BundleComponent{{ProductID: 2, Quantity: 1}, {ProductID: 3, Quantity: 2}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().SetComponents(gomock.Any(), 1, gomock.Len(2)).Return(nil)
+				suite.mockRepo.EXPECT().GetComponents(gomock.Any(), 1).Return([]*entity.BundleComponent{}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Successful unbundle",
+			components: nil,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().SetComponents(gomock.Any(), 1, gomock.Len(0)).Return(nil)
+				suite.mockRepo.EXPECT().GetComponents(gomock.Any(), 1).Return([]*entity.BundleComponent{}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed bundle - Contains itself",
+			components:    []*entity.BundleComponent{{ProductID: 1, Quantity: 1}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidBundle,
+		},
+		{
+			name:          "Failed bundle - Duplicate component",
+			components:    []*entity.BundleComponent{{ProductID: 2, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidBundle,
+		},
+		{
+			name:          "Failed bundle - Zero quantity",
+			components:    []*entity.BundleComponent{{ProductID: 2, Quantity: 0}},
+			mockBehavior:  func() {},
+			expectedError: entity.ErrInvalidBundle,
+		},
+		{
+			name:       "Failed bundle - Component is a bundle",
+			components: []*entity.BundleComponent{{ProductID: 4, Quantity: 1}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().SetComponents(gomock.Any(), 1, gomock.Any()).Return(entity.ErrInvalidComponent)
+			},
+			expectedError: entity.ErrInvalidComponent,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.productUsecase.SetBundleComponents(context.Background(), 1, tc.components)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CatalogCacheTTL bounds how long a cached catalog read can lag behind
+// changes made outside this usecase, such as orders moving stock, price
+// schedules and reviews. Writes through the usecase invalidate right away.
+const CatalogCacheTTL = time.Minute
+
+const (
+	catalogPrefix  = "catalog:"
+	catalogListKey = catalogPrefix + "products"
+)
+
+func productKey(id int) string {
+	return fmt.Sprintf("%sproduct:%d", catalogPrefix, id)
+}
+
+// readThrough fills value from the cache, or with load and then stores it.
+// Values are kept as JSON, so fields hidden from the API are not cached. A
+// cache that cannot be reached only costs a database read.
+func (pu *ProductUsecase) readThrough(ctx context.Context, key string, value interface{}, load func() error) error {
+	data, ok, err := pu.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("catalog cache: get %s: %v", key, err)
+	}
+	if ok && json.Unmarshal(data, value) == nil {
+		return nil
+	}
+
+	if err = load(); err != nil {
+		return err
+	}
+
+	if data, err = json.Marshal(value); err == nil {
+		err = pu.cache.Set(ctx, key, data, CatalogCacheTTL)
+	}
+	if err != nil {
+		log.Printf("catalog cache: set %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// invalidate drops the catalog listing and the given products. It is called
+// after a successful write, so a failure leaves the entries to expire.
+func (pu *ProductUsecase) invalidate(ctx context.Context, ids ...int) {
+	keys := []string{catalogListKey}
+	for _, id := range ids {
+		keys = append(keys, productKey(id))
+	}
+
+	if err := pu.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("catalog cache: delete %v: %v", keys, err)
+	}
+}
+
+// invalidateAll drops every catalog entry, for writes such as imports that
+// do not say which products they touched.
+func (pu *ProductUsecase) invalidateAll(ctx context.Context) {
+	if err := pu.cache.DeletePrefix(ctx, catalogPrefix); err != nil {
+		log.Printf("catalog cache: delete %s*: %v", catalogPrefix, err)
+	}
+}
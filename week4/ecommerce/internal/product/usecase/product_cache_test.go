@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/utils"
+
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *ProductUsecaseTestSuite) TestCatalogCache() {
+	ctx := context.Background()
+
+	// The first read fills the cache, the second is served from it
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Product{ID: 1, Name: "Keyboard"}, nil)
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return(nil, nil)
+	suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return([]*entity.Product{{ID: 1, Name: "Keyboard"}}, nil)
+
+	for i := 0; i < 2; i++ {
+		product, err := suite.productUsecase.GetByProductID(ctx, 1)
+		suite.Require().NoError(err)
+		suite.Equal("Keyboard", product.Name)
+
+		products, err := suite.productUsecase.GetAllProducts(ctx, false)
+		suite.Require().NoError(err)
+		suite.Len(products, 1)
+	}
+
+	// An update drops both entries
+	suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.productUsecase.UpdateProduct(ctx, &entity.Product{ID: 1, Name: "Mechanical keyboard"}))
+
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Product{ID: 1, Name: "Mechanical keyboard"}, nil)
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return(nil, nil)
+	suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return([]*entity.Product{{ID: 1, Name: "Mechanical keyboard"}}, nil)
+
+	product, err := suite.productUsecase.GetByProductID(ctx, 1)
+	suite.Require().NoError(err)
+	suite.Equal("Mechanical keyboard", product.Name)
+
+	products, err := suite.productUsecase.GetAllProducts(ctx, false)
+	suite.Require().NoError(err)
+	suite.Equal("Mechanical keyboard", products[0].Name)
+
+	// Admin listings with deleted products always go to the database
+	suite.mockRepo.EXPECT().GetAll(gomock.Any(), true).Return(nil, nil).Times(2)
+	suite.productUsecase.GetAllProducts(ctx, true)
+	suite.productUsecase.GetAllProducts(ctx, true)
+}
+
+func (suite *ProductUsecaseTestSuite) TestUpdateProductConflict() {
+	ctx := context.Background()
+
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Product{ID: 1, Version: 2}, nil)
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return(nil, nil)
+	_, err := suite.productUsecase.GetByProductID(ctx, 1)
+	suite.Require().NoError(err)
+
+	// A stale version also drops the cached copy, so the caller can show
+	// the current product
+	suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(utils.ErrVersionConflict)
+	err = suite.productUsecase.UpdateProduct(ctx, &entity.Product{ID: 1, Name: "Keyboard", Version: 2})
+	suite.Equal(utils.ErrVersionConflict, err)
+
+	suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Product{ID: 1, Version: 3}, nil)
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return(nil, nil)
+	product, err := suite.productUsecase.GetByProductID(ctx, 1)
+	suite.Require().NoError(err)
+	suite.Equal(3, product.Version)
+}
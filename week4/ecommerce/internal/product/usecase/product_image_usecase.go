@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/imaging"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+var (
+	ErrImageNotFound = errors.New("image not found")
+)
+
+// AddProductImage validates data, stores the original together with its
+// medium and thumbnail renditions and appends it to the product gallery.
+func (pu *ProductUsecase) AddProductImage(ctx context.Context, productID int, data []byte) (*entity.ProductImage, error) {
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := imageKeyPrefix(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &entity.ProductImage{ProductID: productID}
+	var stored []string
+	fail := func(err error) (*entity.ProductImage, error) {
+		pu.deleteFiles(ctx, stored)
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(data)
+	image.OriginalKey = prefix + "original" + imaging.Extension(contentType)
+	image.OriginalURL, err = pu.storage.Put(ctx, image.OriginalKey, data, contentType)
+	if err != nil {
+		return fail(err)
+	}
+	stored = append(stored, image.OriginalKey)
+
+	renditions := []struct {
+		rendition imaging.Rendition
+		key       *string
+		url       *string
+	}{
+		{imaging.Medium, &image.MediumKey, &image.MediumURL},
+		{imaging.Thumbnail, &image.ThumbnailKey, &image.ThumbnailURL},
+	}
+
+	for _, r := range renditions {
+		resized, renditionType, err := imaging.Encode(imaging.Resize(img, r.rendition), format)
+		if err != nil {
+			return fail(err)
+		}
+
+		*r.key = prefix + r.rendition.Name + imaging.Extension(renditionType)
+		*r.url, err = pu.storage.Put(ctx, *r.key, resized, renditionType)
+		if err != nil {
+			return fail(err)
+		}
+		stored = append(stored, *r.key)
+	}
+
+	if err = pu.imageRepo.Create(ctx, image); err != nil {
+		return fail(err)
+	}
+	pu.invalidate(ctx, productID)
+
+	return image, nil
+}
+
+func (pu *ProductUsecase) GetProductImages(ctx context.Context, productID int) ([]*entity.ProductImage, error) {
+	return pu.imageRepo.GetByProductID(ctx, productID)
+}
+
+func (pu *ProductUsecase) ReorderProductImages(ctx context.Context, productID int, imageIDs []int) error {
+	if err := pu.imageRepo.Reorder(ctx, productID, imageIDs); err != nil {
+		return err
+	}
+
+	pu.invalidate(ctx, productID)
+	return nil
+}
+
+// DeleteProductImage removes the image from the gallery and then deletes its
+// stored files.
+func (pu *ProductUsecase) DeleteProductImage(ctx context.Context, productID, imageID int) error {
+	image, err := pu.imageRepo.GetByID(ctx, imageID)
+	if err != nil {
+		return err
+	}
+
+	if image == nil || image.ProductID != productID {
+		return ErrImageNotFound
+	}
+
+	if err = pu.imageRepo.Delete(ctx, imageID); err != nil {
+		return err
+	}
+	pu.invalidate(ctx, productID)
+
+	pu.deleteFiles(ctx, image.Keys())
+
+	return nil
+}
+
+// deleteFiles removes stored files on a best-effort basis; a file left behind
+// is only wasted space, so failures are logged rather than returned.
+func (pu *ProductUsecase) deleteFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := pu.storage.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete stored file %s: %v", key, err)
+		}
+	}
+}
+
+func imageKeyPrefix(productID int) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("products/%d/%s/", productID, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/imaging"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/mock/gomock"
+)
+
+func testPNG(width, height int) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+func (suite *ProductUsecaseTestSuite) storedFile(key string) string {
+	return filepath.Join(suite.storageDir, filepath.FromSlash(key))
+}
+
+func (suite *ProductUsecaseTestSuite) TestAddProductImage() {
+	testCases := []struct {
+		name          string
+		input         []byte
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:          "Failed image upload - Unsupported type",
+			input:         []byte("%PDF-1.4 not an image"),
+			mockBehavior:  func() {},
+			expectedError: imaging.ErrUnsupportedType,
+		},
+		{
+			name:          "Failed image upload - Too large",
+			input:         append(testPNG(1, 1), make([]byte, imaging.MaxUploadSize)...),
+			mockBehavior:  func() {},
+			expectedError: imaging.ErrTooLarge,
+		},
+		{
+			name:  "Failed image upload - Database error",
+			input: testPNG(10, 10),
+			mockBehavior: func() {
+				suite.mockImageRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+		{
+			name:  "Successful image upload",
+			input: testPNG(1200, 800),
+			mockBehavior: func() {
+				suite.mockImageRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedError: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			img, err := suite.productUsecase.AddProductImage(context.Background(), 1, tc.input)
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+				entries, _ := os.ReadDir(filepath.Join(suite.storageDir, "products", "1"))
+				for _, entry := range entries {
+					files, _ := os.ReadDir(filepath.Join(suite.storageDir, "products", "1", entry.Name()))
+					suite.Empty(files, "stored files should be cleaned up on failure")
+				}
+			} else {
+				suite.NoError(err)
+
+				medium, err := os.ReadFile(suite.storedFile(img.MediumKey))
+				suite.Require().NoError(err)
+				cfg, err := png.DecodeConfig(bytes.NewReader(medium))
+				suite.Require().NoError(err)
+				suite.Equal(600, cfg.Width)
+				suite.Equal(400, cfg.Height)
+
+				thumbnail, err := os.ReadFile(suite.storedFile(img.ThumbnailKey))
+				suite.Require().NoError(err)
+				cfg, err = png.DecodeConfig(bytes.NewReader(thumbnail))
+				suite.Require().NoError(err)
+				suite.Equal(150, cfg.Width)
+				suite.Equal(100, cfg.Height)
+
+				suite.FileExists(suite.storedFile(img.OriginalKey))
+			}
+		})
+	}
+}
+
+func (suite *ProductUsecaseTestSuite) TestDeleteProductImage() {
+	stored := &entity.ProductImage{
+		ID:           5,
+		ProductID:    1,
+		OriginalKey:  "products/1/abc/original.png",
+		MediumKey:    "products/1/abc/medium.png",
+		ThumbnailKey: "products/1/abc/thumbnail.png",
+	}
+
+	testCases := []struct {
+		name          string
+		productID     int
+		imageID       int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:      "Successful image deletion",
+			productID: 1,
+			imageID:   5,
+			mockBehavior: func() {
+				suite.mockImageRepo.EXPECT().GetByID(gomock.Any(), 5).Return(stored, nil)
+				suite.mockImageRepo.EXPECT().Delete(gomock.Any(), 5).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:      "Failed image deletion - Image of another product",
+			productID: 2,
+			imageID:   5,
+			mockBehavior: func() {
+				suite.mockImageRepo.EXPECT().GetByID(gomock.Any(), 5).Return(stored, nil)
+			},
+			expectedError: ErrImageNotFound,
+		},
+		{
+			name:      "Failed image deletion - Image not found",
+			productID: 1,
+			imageID:   6,
+			mockBehavior: func() {
+				suite.mockImageRepo.EXPECT().GetByID(gomock.Any(), 6).Return(nil, nil)
+			},
+			expectedError: ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			for _, key := range stored.Keys() {
+				suite.Require().NoError(os.MkdirAll(filepath.Dir(suite.storedFile(key)), os.ModePerm))
+				suite.Require().NoError(os.WriteFile(suite.storedFile(key), []byte("x"), 0o644))
+			}
+
+			tc.mockBehavior()
+			err := suite.productUsecase.DeleteProductImage(context.Background(), tc.productID, tc.imageID)
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+				suite.FileExists(suite.storedFile(stored.OriginalKey))
+			} else {
+				suite.NoError(err)
+				for _, key := range stored.Keys() {
+					suite.NoFileExists(suite.storedFile(key))
+				}
+			}
+		})
+	}
+}
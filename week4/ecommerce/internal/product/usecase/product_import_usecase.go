@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"ecommerce/internal/product/entity"
+	"ecommerce/pkg/tabular"
+	"ecommerce/pkg/utils"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmptyImport       = errors.New("file has no product rows")
+	ErrInvalidImportFile = errors.New("file could not be read")
+)
+
+// ImportProducts validates every row of the file and upserts the products in
+// one transaction. Any row error rolls back the whole import; a dry run only
+// reports what would happen.
+func (pu *ProductUsecase) ImportProducts(ctx context.Context, r io.Reader, format tabular.Format, dryRun bool) (*entity.ImportResult, error) {
+	table, err := tabular.Read(r, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	rows, rowErrors := ParseImportRows(table)
+	if len(rows) == 0 && len(rowErrors) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	// Valid rows are still checked against the database so a single run
+	// reports every problem in the file
+	result := &entity.ImportResult{Errors: []entity.RowError{}}
+	if len(rows) > 0 {
+		result, err = pu.productRepo.Import(ctx, rows, dryRun || len(rowErrors) > 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result.DryRun = dryRun
+	result.TotalRows = len(rows) + countRows(rowErrors)
+	result.Errors = append(result.Errors, rowErrors...)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+
+	if !dryRun && len(result.Errors) > 0 {
+		result.Created, result.Updated = 0, 0
+	}
+	if result.Created > 0 || result.Updated > 0 {
+		pu.invalidateAll(ctx)
+	}
+
+	return result, nil
+}
+
+// StartImport records an import job and runs it in the background. The file
+// is passed as bytes because the request that uploaded it is gone by the
+// time the job runs.
+func (pu *ProductUsecase) StartImport(ctx context.Context, filename string, data []byte, format tabular.Format, dryRun bool) (*entity.ImportJob, error) {
+	job := &entity.ImportJob{
+		Filename:  filename,
+		Format:    string(format),
+		DryRun:    dryRun,
+		Status:    entity.ImportPending,
+		CreatedBy: utils.ActorFromContext(ctx),
+	}
+
+	if err := pu.importRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	jobCtx := utils.WithActor(context.Background(), job.CreatedBy)
+	go pu.runImport(jobCtx, *job, data, format)
+
+	return job, nil
+}
+
+func (pu *ProductUsecase) runImport(ctx context.Context, job entity.ImportJob, data []byte, format tabular.Format) {
+	job.Status = entity.ImportRunning
+	if err := pu.importRepo.Update(ctx, &job); err != nil {
+		log.Printf("import %d: %v", job.ID, err)
+	}
+
+	result, err := pu.ImportProducts(ctx, bytes.NewReader(data), format, job.DryRun)
+	if err != nil {
+		job.Status = entity.ImportFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = entity.ImportDone
+		job.Result = result
+	}
+
+	if err = pu.importRepo.Update(ctx, &job); err != nil {
+		log.Printf("import %d: %v", job.ID, err)
+	}
+}
+
+func (pu *ProductUsecase) GetImportJob(ctx context.Context, id int) (*entity.ImportJob, error) {
+	return pu.importRepo.GetByID(ctx, id)
+}
+
+// ExportProducts writes every product with the columns accepted by
+// ImportProducts, so an export can be edited and imported back.
+func (pu *ProductUsecase) ExportProducts(ctx context.Context, w io.Writer, format tabular.Format) error {
+	products, err := pu.productRepo.GetAll(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	table := [][]string{entity.ImportColumns}
+	for _, product := range products {
+		table = append(table, []string{
+			strconv.Itoa(product.ID),
+			product.SKU,
+			product.Name,
+			product.Description,
+			strconv.FormatFloat(product.Price, 'f', -1, 64),
+			formatStock(product.Stock),
+			product.ImagePath,
+		})
+	}
+
+	return tabular.Write(w, format, "Products", table)
+}
+
+// formatStock leaves the stock of downloads empty, which an import takes as
+// not given.
+func formatStock(stock *int) string {
+	if stock == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*stock)
+}
+
+// ParseImportRows maps a table with a header row to import rows. Rows that
+// fail validation are returned as errors instead; blank rows are skipped.
+func ParseImportRows(table [][]string) ([]*entity.ImportRow, []entity.RowError) {
+	if len(table) == 0 {
+		return nil, nil
+	}
+
+	header := make([]string, len(table[0]))
+	for i, column := range table[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+
+	if headerErrors := validateHeader(header); len(headerErrors) > 0 {
+		return nil, headerErrors
+	}
+
+	var (
+		rows      []*entity.ImportRow
+		rowErrors []entity.RowError
+		seenIDs   = map[int]int{}
+		seenSKUs  = map[string]int{}
+	)
+
+	for i, cells := range table[1:] {
+		line := i + 2
+		row := &entity.ImportRow{Row: line, Columns: map[string]bool{}}
+		var errs []entity.RowError
+
+		for j, column := range header {
+			if j >= len(cells) || strings.TrimSpace(cells[j]) == "" {
+				continue
+			}
+
+			value := strings.TrimSpace(cells[j])
+			if err := setImportField(&row.Product, column, value); err != nil {
+				errs = append(errs, entity.RowError{Row: line, Column: column, Message: err.Error()})
+				continue
+			}
+			row.Columns[column] = true
+		}
+
+		if len(row.Columns) == 0 && len(errs) == 0 {
+			continue
+		}
+
+		if id := row.Product.ID; id != 0 {
+			if first, ok := seenIDs[id]; ok {
+				errs = append(errs, entity.RowError{Row: line, Column: "id", Message: fmt.Sprintf("duplicate id, first used on row %d", first)})
+			}
+			seenIDs[id] = line
+		}
+
+		if sku := row.Product.SKU; sku != "" {
+			if first, ok := seenSKUs[sku]; ok {
+				errs = append(errs, entity.RowError{Row: line, Column: "sku", Message: fmt.Sprintf("duplicate sku, first used on row %d", first)})
+			}
+			seenSKUs[sku] = line
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors
+}
+
+func validateHeader(header []string) []entity.RowError {
+	known := map[string]bool{}
+	for _, column := range entity.ImportColumns {
+		known[column] = true
+	}
+
+	var errs []entity.RowError
+	seen := map[string]bool{}
+	for _, column := range header {
+		switch {
+		case column == "":
+		case !known[column]:
+			errs = append(errs, entity.RowError{Row: 1, Column: column, Message: "unknown column"})
+		case seen[column]:
+			errs = append(errs, entity.RowError{Row: 1, Column: column, Message: "duplicate column"})
+		}
+		seen[column] = true
+	}
+
+	if !seen["name"] && !seen["id"] && !seen["sku"] {
+		errs = append(errs, entity.RowError{Row: 1, Message: "header must contain name, id or sku"})
+	}
+
+	return errs
+}
+
+func setImportField(product *entity.Product, column, value string) error {
+	switch column {
+	case "id":
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return errors.New("must be a positive whole number")
+		}
+		product.ID = id
+	case "sku":
+		if len(value) > 64 {
+			return errors.New("must be at most 64 characters")
+		}
+		product.SKU = value
+	case "name":
+		product.Name = value
+	case "description":
+		product.Description = value
+	case "price":
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return errors.New("must be a number of at least 0")
+		}
+		product.Price = price
+	case "stock":
+		stock, err := strconv.Atoi(value)
+		if err != nil || stock < 0 {
+			return errors.New("must be a whole number of at least 0")
+		}
+		product.Stock = &stock
+	case "image_path":
+		product.ImagePath = value
+	}
+
+	return nil
+}
+
+// countRows returns the number of distinct data rows among errs.
+func countRows(errs []entity.RowError) int {
+	rows := map[int]bool{}
+	for _, err := range errs {
+		if err.Row > 1 {
+			rows[err.Row] = true
+		}
+	}
+
+	return len(rows)
+}
@@ -4,16 +4,28 @@ import (
 	"context"
 	"ecommerce/internal/product/entity"
 	"ecommerce/internal/product/repository"
+	"ecommerce/pkg/cache"
+	"ecommerce/pkg/storage"
+	"ecommerce/pkg/utils"
 	"errors"
+	"time"
 )
 
 type ProductUsecase struct {
 	productRepo repository.IProductRepository
+	imageRepo   repository.IProductImageRepository
+	importRepo  repository.IProductImportRepository
+	storage     storage.Storage
+	cache       cache.Cache
 }
 
-func NewProductUsecase(productRepo repository.IProductRepository) *ProductUsecase {
+func NewProductUsecase(productRepo repository.IProductRepository, imageRepo repository.IProductImageRepository, importRepo repository.IProductImportRepository, storage storage.Storage, cache cache.Cache) *ProductUsecase {
 	return &ProductUsecase{
 		productRepo: productRepo,
+		imageRepo:   imageRepo,
+		importRepo:  importRepo,
+		storage:     storage,
+		cache:       cache,
 	}
 }
 
@@ -21,18 +33,54 @@ func (pu *ProductUsecase) CreateProduct(ctx context.Context, product *entity.Pro
     if product.Price < 0 {
 		return errors.New("invalid price")
 	}
-	if product.Stock < 0 {
+	if product.Stock != nil && *product.Stock < 0 {
 		return errors.New("invalid stock")
 	}
-	return pu.productRepo.Create(ctx, product)
+	if err := pu.productRepo.Create(ctx, product); err != nil {
+		return err
+	}
+
+	pu.invalidate(ctx)
+	return nil
 }
 
-func (pu *ProductUsecase) GetAllProducts(ctx context.Context) ([]*entity.Product, error) {
-	return pu.productRepo.GetAll(ctx)
+// GetAllProducts returns the catalog. The public listing is read through
+// the catalog cache; the admin listing with deleted products is not.
+func (pu *ProductUsecase) GetAllProducts(ctx context.Context, includeDeleted bool) ([]*entity.Product, error) {
+	if includeDeleted {
+		return pu.productRepo.GetAll(ctx, true)
+	}
+
+	var products []*entity.Product
+	err := pu.readThrough(ctx, catalogListKey, &products, func() (err error) {
+		products, err = pu.productRepo.GetAll(ctx, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
 }
 
+// GetByProductID returns the product with its gallery, read through the
+// catalog cache.
 func (pu *ProductUsecase) GetByProductID(ctx context.Context, id int) (*entity.Product, error) {
-	return pu.productRepo.GetByID(ctx, id)
+	var product *entity.Product
+	err := pu.readThrough(ctx, productKey(id), &product, func() (err error) {
+		product, err = pu.productRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		product.Images, err = pu.imageRepo.GetByProductID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
 }
 
 func (pu *ProductUsecase) GetByProductName(ctx context.Context, name string) ([]*entity.Product, error) {
@@ -40,9 +88,70 @@ func (pu *ProductUsecase) GetByProductName(ctx context.Context, name string) ([]
 }
 
 func (pu *ProductUsecase) UpdateProduct(ctx context.Context, product *entity.Product) error {
-	return pu.productRepo.Update(ctx, product)
+	err := pu.productRepo.Update(ctx, product)
+	if errors.Is(err, utils.ErrVersionConflict) {
+		// Someone else's edit may not have reached this cache yet
+		pu.invalidate(ctx, product.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	pu.invalidate(ctx, product.ID)
+	return nil
 }
 
+// DeleteProduct soft deletes the product. Its gallery files are kept so a
+// restore brings the product back complete; PurgeDeletedProducts removes them.
 func (pu *ProductUsecase) DeleteProduct(ctx context.Context, id int) error {
-	return pu.productRepo.Delete(ctx, id)
+	if err := pu.productRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	pu.invalidate(ctx, id)
+	return nil
+}
+
+func (pu *ProductUsecase) RestoreProduct(ctx context.Context, id int) error {
+	if err := pu.productRepo.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	pu.invalidate(ctx, id)
+	return nil
+}
+
+// PurgeDeletedProducts hard deletes products that were deleted more than
+// retention ago and are not part of any order, together with their stored
+// image files. It returns the number of products purged.
+func (pu *ProductUsecase) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+
+	ids, err := pu.productRepo.GetPurgeable(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		images, err := pu.imageRepo.GetByProductID(ctx, id)
+		if err != nil {
+			return purged, err
+		}
+
+		ok, err := pu.productRepo.Purge(ctx, id, cutoff)
+		if err != nil {
+			return purged, err
+		}
+		if !ok {
+			continue
+		}
+
+		purged++
+		for _, image := range images {
+			pu.deleteFiles(ctx, image.Keys())
+		}
+	}
+
+	return purged, nil
 }
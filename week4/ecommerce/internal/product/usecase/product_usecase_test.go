@@ -2,10 +2,16 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"ecommerce/internal/product/entity"
 	mock_repository "ecommerce/internal/product/mocks"
+	"ecommerce/pkg/cache"
+	"ecommerce/pkg/storage"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -15,13 +21,25 @@ type ProductUsecaseTestSuite struct {
 	suite.Suite
 	mockCtrl       *gomock.Controller
 	mockRepo       *mock_repository.MockIProductRepository
+	mockImageRepo  *mock_repository.MockIProductImageRepository
+	mockImportRepo *mock_repository.MockIProductImportRepository
+	storageDir     string
 	productUsecase ProductUsecase
 }
 
 func (suite *ProductUsecaseTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockRepo = mock_repository.NewMockIProductRepository(suite.mockCtrl)
-	suite.productUsecase = *NewProductUsecase(suite.mockRepo)
+	suite.mockImageRepo = mock_repository.NewMockIProductImageRepository(suite.mockCtrl)
+	suite.mockImportRepo = mock_repository.NewMockIProductImportRepository(suite.mockCtrl)
+	suite.storageDir = suite.T().TempDir()
+	suite.productUsecase = *NewProductUsecase(suite.mockRepo, suite.mockImageRepo, suite.mockImportRepo, storage.NewLocalStorage(suite.storageDir, "/uploads"), cache.NewLRU(100))
+}
+
+// SetupSubTest empties the catalog cache so table cases do not see each
+// other's reads.
+func (suite *ProductUsecaseTestSuite) SetupSubTest() {
+	suite.productUsecase.cache = cache.NewLRU(100)
 }
 
 func (suite *ProductUsecaseTestSuite) TearDownTest() {
@@ -101,21 +119,21 @@ func (suite *ProductUsecaseTestSuite) TestGetAllProducts() {
 			name: "Successful retrieval of all products",
 			mockBehavior: func() {
 				products := []*entity.Product{
-					{ID: 1, Name: "Product A", Price: 10.0, Stock: 100},
-					{ID: 2, Name: "Product B", Price: 20.0, Stock: 50},
+					{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100)},
+					{ID: 2, Name: "Product B", Price: 20.0, Stock: intPtr(50)},
 				}
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(products, nil)
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return(products, nil)
 			},
 			expectedResult: []*entity.Product{
-				{ID: 1, Name: "Product A", Price: 10.0, Stock: 100},
-				{ID: 2, Name: "Product B", Price: 20.0, Stock: 50},
+				{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100)},
+				{ID: 2, Name: "Product B", Price: 20.0, Stock: intPtr(50)},
 			},
 			expectedError: nil,
 		},
 		{
 			name: "Failed retrieval of all products",
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(nil, errors.New("database error"))
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return(nil, errors.New("database error"))
 			},
 			expectedResult: nil,
 			expectedError:  errors.New("database error"),
@@ -125,7 +143,7 @@ func (suite *ProductUsecaseTestSuite) TestGetAllProducts() {
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			products, err := suite.productUsecase.GetAllProducts(context.Background())
+			products, err := suite.productUsecase.GetAllProducts(context.Background(), false)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -148,10 +166,11 @@ func (suite *ProductUsecaseTestSuite) TestGetByProductID() {
 			name:  "Successful retrieval of product by ID",
 			input: 1,
 			mockBehavior: func() {
-				product := &entity.Product{ID: 1, Name: "Product A", Price: 10.0, Stock: 100}
+				product := &entity.Product{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100)}
 				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil)
+				suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return([]*entity.ProductImage{{ID: 3, ProductID: 1}}, nil)
 			},
-			expectedResult: &entity.Product{ID: 1, Name: "Product A", Price: 10.0, Stock: 100},
+			expectedResult: &entity.Product{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100), Images: []*entity.ProductImage{{ID: 3, ProductID: 1}}},
 			expectedError:  nil,
 		},
 		{
@@ -191,10 +210,10 @@ func (suite *ProductUsecaseTestSuite) TestGetByProductName() {
 			name:  "Successful retrieval of products by name",
 			input: "Product A",
 			mockBehavior: func() {
-				products := []*entity.Product{{ID: 1, Name: "Product A", Price: 10.0, Stock: 100}}
+				products := []*entity.Product{{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100)}}
 				suite.mockRepo.EXPECT().GetByName(gomock.Any(), "Product A").Return(products, nil)
 			},
-			expectedResult: []*entity.Product{{ID: 1, Name: "Product A", Price: 10.0, Stock: 100}},
+			expectedResult: []*entity.Product{{ID: 1, Name: "Product A", Price: 10.0, Stock: intPtr(100)}},
 			expectedError:  nil,
 		},
 		{
@@ -235,7 +254,7 @@ func (suite *ProductUsecaseTestSuite) TestUpdateProduct() {
 				ID:    1,
 				Name:  "Updated Product A",
 				Price: 15.0,
-				Stock: 150,
+				Stock: intPtr(150),
 			},
 			mockBehavior: func() {
 				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
@@ -248,7 +267,7 @@ func (suite *ProductUsecaseTestSuite) TestUpdateProduct() {
 				ID:    2,
 				Name:  "Updated Product B",
 				Price: 25.0,
-				Stock: 75,
+				Stock: intPtr(75),
 			},
 			mockBehavior: func() {
 				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("product not found"))
@@ -307,3 +326,69 @@ func (suite *ProductUsecaseTestSuite) TestDeleteProduct() {
 		})
 	}
 }
+
+func (suite *ProductUsecaseTestSuite) TestRestoreProduct() {
+	testCases := []struct {
+		name          string
+		input         int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Successful product restore",
+			input: 1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Restore(gomock.Any(), 1).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Failed product restore - Not deleted",
+			input: 2,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Restore(gomock.Any(), 2).Return(sql.ErrNoRows)
+			},
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.productUsecase.RestoreProduct(context.Background(), tc.input)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *ProductUsecaseTestSuite) TestPurgeDeletedProducts() {
+	image := &entity.ProductImage{
+		ProductID:    1,
+		OriginalKey:  "products/1/abc/original.png",
+		MediumKey:    "products/1/abc/medium.png",
+		ThumbnailKey: "products/1/abc/thumbnail.png",
+	}
+	for _, key := range image.Keys() {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(suite.storedFile(key)), 0o755))
+		suite.Require().NoError(os.WriteFile(suite.storedFile(key), []byte("x"), 0o644))
+	}
+
+	suite.mockRepo.EXPECT().GetPurgeable(gomock.Any(), gomock.Any()).Return([]int{1, 2}, nil)
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 1).Return([]*entity.ProductImage{image}, nil)
+	suite.mockRepo.EXPECT().Purge(gomock.Any(), 1, gomock.Any()).Return(true, nil)
+	// Product 2 was ordered after it was listed, so it is kept
+	suite.mockImageRepo.EXPECT().GetByProductID(gomock.Any(), 2).Return(nil, nil)
+	suite.mockRepo.EXPECT().Purge(gomock.Any(), 2, gomock.Any()).Return(false, nil)
+
+	purged, err := suite.productUsecase.PurgeDeletedProducts(context.Background(), 90*24*time.Hour)
+
+	suite.NoError(err)
+	suite.Equal(1, purged)
+	for _, key := range image.Keys() {
+		suite.NoFileExists(suite.storedFile(key))
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Recommendation is a product suggested because it is often ordered together
+// with another product. Score is the cosine similarity of the two products'
+// order sets, summed over the user's purchases for personal recommendations.
+type Recommendation struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	ImagePath string  `json:"image_path"`
+	Score     float64 `json:"score"`
+	CoOrders  int     `json:"co_orders"`
+}
+
+// RebuildStats summarises a rebuild of the co-occurrence scores.
+type RebuildStats struct {
+	Pairs      int       `json:"pairs"`
+	ComputedAt time.Time `json:"computed_at"`
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"ecommerce/internal/recommendation/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RecommendationHandler struct {
+	uc *usecase.RecommendationUsecase
+}
+
+func NewRecommendationHandler(uc *usecase.RecommendationUsecase) *RecommendationHandler {
+	return &RecommendationHandler{
+		uc: uc,
+	}
+}
+
+func recommendationError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, usecase.ErrInvalidLimit) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+// GetProductRecommendations handles GET /products/:id/recommendations?limit=5
+func (h *RecommendationHandler) GetProductRecommendations(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	recommendations, err := h.uc.GetProductRecommendations(c.Context(), id, c.QueryInt("limit"))
+	if err != nil {
+		return recommendationError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(recommendations)
+}
+
+// GetMyRecommendations handles GET /me/recommendations?limit=5
+func (h *RecommendationHandler) GetMyRecommendations(c *fiber.Ctx) error {
+	recommendations, err := h.uc.GetUserRecommendations(c.Context(), middleware.Username(c), c.QueryInt("limit"))
+	if err != nil {
+		return recommendationError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(recommendations)
+}
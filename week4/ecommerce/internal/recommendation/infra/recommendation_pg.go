@@ -0,0 +1,127 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/recommendation/entity"
+	"time"
+)
+
+type RecommendationPGRepository struct {
+	DB *sql.DB
+}
+
+func NewRecommendationPGRepository(db *sql.DB) *RecommendationPGRepository {
+	return &RecommendationPGRepository{
+		DB: db,
+	}
+}
+
+// Rebuild replaces all co-occurrence scores with ones computed from the
+// current order history, keeping the perProduct best related products of
+// every product. Readers see the old scores until the transaction commits.
+func (r *RecommendationPGRepository) Rebuild(ctx context.Context, perProduct int) (*entity.RebuildStats, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM product_affinities`); err != nil {
+		return nil, err
+	}
+
+	stats := &entity.RebuildStats{ComputedAt: time.Now()}
+	query := `WITH product_orders AS (
+			SELECT DISTINCT order_id, product_id FROM order_lines
+		), order_counts AS (
+			SELECT product_id, COUNT(*) AS orders FROM product_orders GROUP BY product_id
+		), pairs AS (
+			SELECT a.product_id, b.product_id AS related_id, COUNT(*) AS co_orders
+			FROM product_orders a
+			JOIN product_orders b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+			GROUP BY a.product_id, b.product_id
+		), scored AS (
+			SELECT p.product_id, p.related_id, p.co_orders,
+				p.co_orders / SQRT(ca.orders::float8 * cb.orders) AS score
+			FROM pairs p
+			JOIN order_counts ca ON ca.product_id = p.product_id
+			JOIN order_counts cb ON cb.product_id = p.related_id
+		), ranked AS (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY score DESC, co_orders DESC, related_id) AS row_rank
+			FROM scored
+		)
+		INSERT INTO product_affinities (product_id, related_id, co_orders, score, computed_at)
+		SELECT product_id, related_id, co_orders, score, $2
+		FROM ranked
+		WHERE row_rank <= $1`
+	result, err := tx.ExecContext(ctx, query, perProduct, stats.ComputedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	stats.Pairs = int(pairs)
+
+	return stats, tx.Commit()
+}
+
+// GetForProduct returns the products most often bought with productID that
+// are still for sale and in stock.
+func (r *RecommendationPGRepository) GetForProduct(ctx context.Context, productID, limit int) ([]*entity.Recommendation, error) {
+	query := `SELECT p.id, COALESCE(p.name, ''), COALESCE(p.price, 0.0), COALESCE(p.image_path, ''), a.score, a.co_orders
+		FROM product_affinities a
+		JOIN products p ON p.id = a.related_id
+		JOIN product_availability pa ON pa.product_id = p.id
+		WHERE a.product_id = $1 AND p.deleted_at IS NULL AND (pa.available IS NULL OR pa.available > 0)
+		ORDER BY a.score DESC, p.id
+		LIMIT $2`
+
+	return r.query(ctx, query, productID, limit)
+}
+
+// GetForUser ranks products by their combined affinity with everything the
+// user has ordered before. Products the user already bought are left out.
+func (r *RecommendationPGRepository) GetForUser(ctx context.Context, username string, limit int) ([]*entity.Recommendation, error) {
+	query := `WITH bought AS (
+			SELECT DISTINCT ol.product_id
+			FROM order_lines ol
+			JOIN orders o ON o.id = ol.order_id
+			JOIN users u ON u.id = o.user_id
+			WHERE u.username = $1 AND u.deleted_at IS NULL
+		)
+		SELECT p.id, COALESCE(p.name, ''), COALESCE(p.price, 0.0), COALESCE(p.image_path, ''), SUM(a.score) AS score, SUM(a.co_orders)::int
+		FROM product_affinities a
+		JOIN bought b ON b.product_id = a.product_id
+		JOIN products p ON p.id = a.related_id
+		JOIN product_availability pa ON pa.product_id = p.id
+		WHERE p.deleted_at IS NULL AND (pa.available IS NULL OR pa.available > 0)
+		  AND a.related_id NOT IN (SELECT product_id FROM bought)
+		GROUP BY p.id
+		ORDER BY score DESC, p.id
+		LIMIT $2`
+
+	return r.query(ctx, query, username, limit)
+}
+
+func (r *RecommendationPGRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Recommendation, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recommendations := []*entity.Recommendation{}
+	for rows.Next() {
+		rec := &entity.Recommendation{}
+		if err = rows.Scan(&rec.ProductID, &rec.Name, &rec.Price, &rec.ImagePath, &rec.Score, &rec.CoOrders); err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	return recommendations, rows.Err()
+}
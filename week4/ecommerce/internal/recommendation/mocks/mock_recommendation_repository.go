@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/recommendation/repository/recommendation_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/recommendation/repository/recommendation_repository.go -destination=internal/recommendation/mocks/mock_recommendation_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/recommendation/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIRecommendationRepository is a mock of IRecommendationRepository interface.
+type MockIRecommendationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRecommendationRepositoryMockRecorder
+}
+
+// MockIRecommendationRepositoryMockRecorder is the mock recorder for MockIRecommendationRepository.
+type MockIRecommendationRepositoryMockRecorder struct {
+	mock *MockIRecommendationRepository
+}
+
+// NewMockIRecommendationRepository creates a new mock instance.
+func NewMockIRecommendationRepository(ctrl *gomock.Controller) *MockIRecommendationRepository {
+	mock := &MockIRecommendationRepository{ctrl: ctrl}
+	mock.recorder = &MockIRecommendationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRecommendationRepository) EXPECT() *MockIRecommendationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetForProduct mocks base method.
+func (m *MockIRecommendationRepository) GetForProduct(ctx context.Context, productID, limit int) ([]*entity.Recommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForProduct", ctx, productID, limit)
+	ret0, _ := ret[0].([]*entity.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForProduct indicates an expected call of GetForProduct.
+func (mr *MockIRecommendationRepositoryMockRecorder) GetForProduct(ctx, productID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForProduct", reflect.TypeOf((*MockIRecommendationRepository)(nil).GetForProduct), ctx, productID, limit)
+}
+
+// GetForUser mocks base method.
+func (m *MockIRecommendationRepository) GetForUser(ctx context.Context, username string, limit int) ([]*entity.Recommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForUser", ctx, username, limit)
+	ret0, _ := ret[0].([]*entity.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForUser indicates an expected call of GetForUser.
+func (mr *MockIRecommendationRepositoryMockRecorder) GetForUser(ctx, username, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForUser", reflect.TypeOf((*MockIRecommendationRepository)(nil).GetForUser), ctx, username, limit)
+}
+
+// Rebuild mocks base method.
+func (m *MockIRecommendationRepository) Rebuild(ctx context.Context, perProduct int) (*entity.RebuildStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebuild", ctx, perProduct)
+	ret0, _ := ret[0].(*entity.RebuildStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rebuild indicates an expected call of Rebuild.
+func (mr *MockIRecommendationRepositoryMockRecorder) Rebuild(ctx, perProduct any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebuild", reflect.TypeOf((*MockIRecommendationRepository)(nil).Rebuild), ctx, perProduct)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/recommendation/entity"
+)
+
+type IRecommendationRepository interface {
+	Rebuild(ctx context.Context, perProduct int) (*entity.RebuildStats, error)
+	GetForProduct(ctx context.Context, productID, limit int) ([]*entity.Recommendation, error)
+	GetForUser(ctx context.Context, username string, limit int) ([]*entity.Recommendation, error)
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/recommendation/entity"
+	"ecommerce/internal/recommendation/repository"
+	"errors"
+)
+
+const (
+	// RelatedPerProduct is how many related products a rebuild keeps for
+	// each product.
+	RelatedPerProduct = 20
+	DefaultLimit      = 10
+	MaxLimit          = RelatedPerProduct
+)
+
+var ErrInvalidLimit = errors.New("limit must be between 1 and 20")
+
+type RecommendationUsecase struct {
+	recommendationRepo repository.IRecommendationRepository
+}
+
+func NewRecommendationUsecase(recommendationRepo repository.IRecommendationRepository) *RecommendationUsecase {
+	return &RecommendationUsecase{
+		recommendationRepo: recommendationRepo,
+	}
+}
+
+func normalizeLimit(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultLimit, nil
+	}
+	if limit < 0 || limit > MaxLimit {
+		return 0, ErrInvalidLimit
+	}
+
+	return limit, nil
+}
+
+// Rebuild recomputes the co-occurrence scores from the order history.
+func (ru *RecommendationUsecase) Rebuild(ctx context.Context) (*entity.RebuildStats, error) {
+	return ru.recommendationRepo.Rebuild(ctx, RelatedPerProduct)
+}
+
+// RebuildJob is Rebuild in the shape the scheduler expects.
+func (ru *RecommendationUsecase) RebuildJob(ctx context.Context) error {
+	_, err := ru.Rebuild(ctx)
+	return err
+}
+
+// GetProductRecommendations returns in-stock products frequently bought
+// together with the product. A limit of 0 means DefaultLimit.
+func (ru *RecommendationUsecase) GetProductRecommendations(ctx context.Context, productID, limit int) ([]*entity.Recommendation, error) {
+	limit, err := normalizeLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.recommendationRepo.GetForProduct(ctx, productID, limit)
+}
+
+// GetUserRecommendations returns in-stock products the user has not bought
+// yet, ranked by how often they go with the user's earlier purchases.
+func (ru *RecommendationUsecase) GetUserRecommendations(ctx context.Context, username string, limit int) ([]*entity.Recommendation, error) {
+	limit, err := normalizeLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.recommendationRepo.GetForUser(ctx, username, limit)
+}
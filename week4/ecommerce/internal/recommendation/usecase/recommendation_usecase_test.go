@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/recommendation/entity"
+	mock_repository "ecommerce/internal/recommendation/mocks"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RecommendationUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl              *gomock.Controller
+	mockRepo              *mock_repository.MockIRecommendationRepository
+	recommendationUsecase *RecommendationUsecase
+}
+
+func (suite *RecommendationUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIRecommendationRepository(suite.mockCtrl)
+	suite.recommendationUsecase = NewRecommendationUsecase(suite.mockRepo)
+}
+
+func (suite *RecommendationUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestRecommendationUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(RecommendationUsecaseTestSuite))
+}
+
+func (suite *RecommendationUsecaseTestSuite) TestGetProductRecommendations() {
+	testCases := []struct {
+		name          string
+		limit         int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Successful lookup with default limit",
+			limit: 0,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetForProduct(gomock.Any(), 1, DefaultLimit).
+					Return([]*entity.Recommendation{{ProductID: 2, Score: 0.5}}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Successful lookup with explicit limit",
+			limit: 3,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetForProduct(gomock.Any(), 1, 3).Return([]*entity.Recommendation{}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed lookup - Limit too large",
+			limit:         MaxLimit + 1,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidLimit,
+		},
+		{
+			name:          "Failed lookup - Negative limit",
+			limit:         -1,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidLimit,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.recommendationUsecase.GetProductRecommendations(context.Background(), 1, tc.limit)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *RecommendationUsecaseTestSuite) TestGetUserRecommendations() {
+	suite.mockRepo.EXPECT().GetForUser(gomock.Any(), "alice", DefaultLimit).
+		Return([]*entity.Recommendation{{ProductID: 4, Score: 1.2}}, nil)
+
+	recommendations, err := suite.recommendationUsecase.GetUserRecommendations(context.Background(), "alice", 0)
+	suite.NoError(err)
+	suite.Len(recommendations, 1)
+}
+
+func (suite *RecommendationUsecaseTestSuite) TestRebuildJob() {
+	suite.mockRepo.EXPECT().Rebuild(gomock.Any(), RelatedPerProduct).Return(nil, errors.New("database error"))
+
+	err := suite.recommendationUsecase.RebuildJob(context.Background())
+	suite.EqualError(err, "database error")
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAlreadyReviewed  = errors.New("product already reviewed, edit the existing review instead")
+	ErrNotVerifiedBuyer = errors.New("only customers with a delivered order of this product can review it")
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Review is a customer's rating of a product. Only approved reviews are
+// shown publicly and count towards the product rating.
+type Review struct {
+	ID             int        `json:"id"`
+	ProductID      int        `json:"product_id"`
+	UserID         int        `json:"user_id"`
+	Username       string     `json:"username"`
+	Rating         int        `json:"rating"`
+	Body           string     `json:"body"`
+	Status         Status     `json:"status"`
+	ModerationNote string     `json:"moderation_note,omitempty"`
+	ModeratedBy    string     `json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"database/sql"
+	"ecommerce/internal/review/entity"
+	"ecommerce/internal/review/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler struct {
+	uc *usecase.ReviewUsecase
+}
+
+func NewReviewHandler(uc *usecase.ReviewUsecase) *ReviewHandler {
+	return &ReviewHandler{
+		uc: uc,
+	}
+}
+
+type reviewRequest struct {
+	Rating int    `json:"rating"`
+	Body   string `json:"body"`
+}
+
+func reviewError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRating),
+		errors.Is(err, usecase.ErrBodyTooLong),
+		errors.Is(err, usecase.ErrInvalidStatus),
+		errors.Is(err, usecase.ErrInvalidModeration):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrNotVerifiedBuyer):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrAlreadyReviewed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Review not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	reviews, err := h.uc.GetProductReviews(c.Context(), id)
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(reviews)
+}
+
+// CreateReview handles POST /products/:id/reviews with {"rating": 5, "body": "..."}
+func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request reviewRequest
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	review, err := h.uc.CreateReview(c.Context(), middleware.Username(c), id, request.Rating, request.Body)
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(review)
+}
+
+func (h *ReviewHandler) GetMyReview(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	review, err := h.uc.GetMyReview(c.Context(), middleware.Username(c), id)
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(review)
+}
+
+// UpdateMyReview handles PUT /products/:id/reviews/mine with {"rating": 4, "body": "..."}
+func (h *ReviewHandler) UpdateMyReview(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request reviewRequest
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	review, err := h.uc.UpdateReview(c.Context(), middleware.Username(c), id, request.Rating, request.Body)
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(review)
+}
+
+// GetReviews handles GET /reviews?status=pending for moderators.
+func (h *ReviewHandler) GetReviews(c *fiber.Ctx) error {
+	reviews, err := h.uc.GetReviewsByStatus(c.Context(), entity.Status(c.Query("status")))
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(reviews)
+}
+
+// ModerateReview handles PUT /reviews/:id/moderation with
+// {"status": "approved", "note": "..."}
+func (h *ReviewHandler) ModerateReview(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var request struct {
+		Status entity.Status `json:"status"`
+		Note   string        `json:"note"`
+	}
+	if err = c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	review, err := h.uc.ModerateReview(middleware.ActorContext(c), id, request.Status, request.Note)
+	if err != nil {
+		return reviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(review)
+}
@@ -0,0 +1,218 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/review/entity"
+	"ecommerce/pkg/utils"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type ReviewPGRepository struct {
+	DB *sql.DB
+}
+
+func NewReviewPGRepository(db *sql.DB) *ReviewPGRepository {
+	return &ReviewPGRepository{
+		DB: db,
+	}
+}
+
+func (r *ReviewPGRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1 AND deleted_at IS NULL`, username).Scan(&id)
+	return id, err
+}
+
+// HasDeliveredPurchase reports whether the user has a delivered order with
+// the product on one of its lines.
+func (r *ReviewPGRepository) HasDeliveredPurchase(ctx context.Context, userID, productID int) (bool, error) {
+	query := `SELECT EXISTS (
+			SELECT 1 FROM order_lines ol
+			JOIN orders o ON o.id = ol.order_id
+			WHERE o.user_id = $1 AND ol.product_id = $2 AND o.status = 'delivered'
+		)`
+
+	var ok bool
+	err := r.DB.QueryRowContext(ctx, query, userID, productID).Scan(&ok)
+	return ok, err
+}
+
+func (r *ReviewPGRepository) Create(ctx context.Context, review *entity.Review) error {
+	query := `INSERT INTO reviews (product_id, user_id, rating, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, created_at, updated_at`
+
+	err := r.DB.QueryRowContext(ctx, query, review.ProductID, review.UserID, review.Rating, review.Body).
+		Scan(&review.ID, &review.Status, &review.CreatedAt, &review.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return entity.ErrAlreadyReviewed
+	}
+
+	return err
+}
+
+const reviewQuery = `SELECT r.id, r.product_id, r.user_id, COALESCE(u.username, ''), r.rating, r.body, r.status,
+		r.moderation_note, COALESCE(r.moderated_by, ''), r.moderated_at, r.created_at, r.updated_at
+	FROM reviews r
+	JOIN users u ON u.id = r.user_id`
+
+func scanReview(row interface{ Scan(...interface{}) error }) (*entity.Review, error) {
+	review := &entity.Review{}
+	err := row.Scan(
+		&review.ID,
+		&review.ProductID,
+		&review.UserID,
+		&review.Username,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.ModerationNote,
+		&review.ModeratedBy,
+		&review.ModeratedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (r *ReviewPGRepository) queryReviews(ctx context.Context, query string, args ...interface{}) ([]*entity.Review, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*entity.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (r *ReviewPGRepository) GetByID(ctx context.Context, id int) (*entity.Review, error) {
+	return scanReview(r.DB.QueryRowContext(ctx, reviewQuery+` WHERE r.id = $1`, id))
+}
+
+func (r *ReviewPGRepository) GetByUserAndProduct(ctx context.Context, userID, productID int) (*entity.Review, error) {
+	return scanReview(r.DB.QueryRowContext(ctx, reviewQuery+` WHERE r.user_id = $1 AND r.product_id = $2`, userID, productID))
+}
+
+func (r *ReviewPGRepository) GetByProduct(ctx context.Context, productID int, status entity.Status) ([]*entity.Review, error) {
+	return r.queryReviews(ctx, reviewQuery+` WHERE r.product_id = $1 AND r.status = $2 ORDER BY r.created_at DESC, r.id DESC`, productID, status)
+}
+
+// GetByStatus lists reviews oldest first, which is the order moderators
+// work through the queue in.
+func (r *ReviewPGRepository) GetByStatus(ctx context.Context, status entity.Status) ([]*entity.Review, error) {
+	return r.queryReviews(ctx, reviewQuery+` WHERE r.status = $1 ORDER BY r.created_at, r.id`, status)
+}
+
+// Update stores the new rating and text. The edited review goes back to
+// moderation, so an approved review leaves the product rating until it is
+// approved again.
+func (r *ReviewPGRepository) Update(ctx context.Context, review *entity.Review) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		old, err := lockReview(ctx, tx, review.ID)
+		if err != nil {
+			return err
+		}
+
+		query := `UPDATE reviews
+			SET rating = $1, body = $2, status = 'pending', moderation_note = '', moderated_by = NULL, moderated_at = NULL,
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3
+			RETURNING status, updated_at`
+		err = tx.QueryRowContext(ctx, query, review.Rating, review.Body, review.ID).Scan(&review.Status, &review.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return adjustRating(ctx, tx, old, review)
+	})
+}
+
+// Moderate records the moderator's decision in review.Status and keeps the
+// product's rating totals in step with it.
+func (r *ReviewPGRepository) Moderate(ctx context.Context, review *entity.Review) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		old, err := lockReview(ctx, tx, review.ID)
+		if err != nil {
+			return err
+		}
+		review.ProductID = old.ProductID
+		review.Rating = old.Rating
+
+		query := `UPDATE reviews
+			SET status = $1, moderation_note = $2, moderated_by = NULLIF($3, ''), moderated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+			RETURNING moderated_at`
+		err = tx.QueryRowContext(ctx, query, review.Status, review.ModerationNote, utils.ActorFromContext(ctx), review.ID).
+			Scan(&review.ModeratedAt)
+		if err != nil {
+			return err
+		}
+
+		return adjustRating(ctx, tx, old, review)
+	})
+}
+
+func lockReview(ctx context.Context, tx *sql.Tx, id int) (*entity.Review, error) {
+	review := &entity.Review{ID: id}
+	err := tx.QueryRowContext(ctx, `SELECT product_id, rating, status FROM reviews WHERE id = $1 FOR UPDATE`, id).
+		Scan(&review.ProductID, &review.Rating, &review.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// adjustRating applies the difference between the old and new version of a
+// review to the product totals. Only approved reviews are counted.
+func adjustRating(ctx context.Context, tx *sql.Tx, old, updated *entity.Review) error {
+	sum, count := 0, 0
+	if old.Status == entity.StatusApproved {
+		sum -= old.Rating
+		count--
+	}
+	if updated.Status == entity.StatusApproved {
+		sum += updated.Rating
+		count++
+	}
+
+	if sum == 0 && count == 0 {
+		return nil
+	}
+
+	query := `UPDATE products SET rating_sum = rating_sum + $1, rating_count = rating_count + $2 WHERE id = $3`
+	_, err := tx.ExecContext(ctx, query, sum, count, old.ProductID)
+	return err
+}
+
+func (r *ReviewPGRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,173 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/review/repository/review_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/review/repository/review_repository.go -destination=internal/review/mocks/mock_review_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/review/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIReviewRepository is a mock of IReviewRepository interface.
+type MockIReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIReviewRepositoryMockRecorder
+}
+
+// MockIReviewRepositoryMockRecorder is the mock recorder for MockIReviewRepository.
+type MockIReviewRepositoryMockRecorder struct {
+	mock *MockIReviewRepository
+}
+
+// NewMockIReviewRepository creates a new mock instance.
+func NewMockIReviewRepository(ctrl *gomock.Controller) *MockIReviewRepository {
+	mock := &MockIReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockIReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIReviewRepository) EXPECT() *MockIReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIReviewRepository) Create(ctx context.Context, review *entity.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIReviewRepositoryMockRecorder) Create(ctx, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIReviewRepository)(nil).Create), ctx, review)
+}
+
+// GetByID mocks base method.
+func (m *MockIReviewRepository) GetByID(ctx context.Context, id int) (*entity.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIReviewRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIReviewRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByProduct mocks base method.
+func (m *MockIReviewRepository) GetByProduct(ctx context.Context, productID int, status entity.Status) ([]*entity.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProduct", ctx, productID, status)
+	ret0, _ := ret[0].([]*entity.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProduct indicates an expected call of GetByProduct.
+func (mr *MockIReviewRepositoryMockRecorder) GetByProduct(ctx, productID, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProduct", reflect.TypeOf((*MockIReviewRepository)(nil).GetByProduct), ctx, productID, status)
+}
+
+// GetByStatus mocks base method.
+func (m *MockIReviewRepository) GetByStatus(ctx context.Context, status entity.Status) ([]*entity.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByStatus", ctx, status)
+	ret0, _ := ret[0].([]*entity.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByStatus indicates an expected call of GetByStatus.
+func (mr *MockIReviewRepositoryMockRecorder) GetByStatus(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByStatus", reflect.TypeOf((*MockIReviewRepository)(nil).GetByStatus), ctx, status)
+}
+
+// GetByUserAndProduct mocks base method.
+func (m *MockIReviewRepository) GetByUserAndProduct(ctx context.Context, userID, productID int) (*entity.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserAndProduct", ctx, userID, productID)
+	ret0, _ := ret[0].(*entity.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserAndProduct indicates an expected call of GetByUserAndProduct.
+func (mr *MockIReviewRepositoryMockRecorder) GetByUserAndProduct(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserAndProduct", reflect.TypeOf((*MockIReviewRepository)(nil).GetByUserAndProduct), ctx, userID, productID)
+}
+
+// GetUserID mocks base method.
+func (m *MockIReviewRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserID", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserID indicates an expected call of GetUserID.
+func (mr *MockIReviewRepositoryMockRecorder) GetUserID(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserID", reflect.TypeOf((*MockIReviewRepository)(nil).GetUserID), ctx, username)
+}
+
+// HasDeliveredPurchase mocks base method.
+func (m *MockIReviewRepository) HasDeliveredPurchase(ctx context.Context, userID, productID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDeliveredPurchase", ctx, userID, productID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasDeliveredPurchase indicates an expected call of HasDeliveredPurchase.
+func (mr *MockIReviewRepositoryMockRecorder) HasDeliveredPurchase(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDeliveredPurchase", reflect.TypeOf((*MockIReviewRepository)(nil).HasDeliveredPurchase), ctx, userID, productID)
+}
+
+// Moderate mocks base method.
+func (m *MockIReviewRepository) Moderate(ctx context.Context, review *entity.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Moderate", ctx, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Moderate indicates an expected call of Moderate.
+func (mr *MockIReviewRepositoryMockRecorder) Moderate(ctx, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Moderate", reflect.TypeOf((*MockIReviewRepository)(nil).Moderate), ctx, review)
+}
+
+// Update mocks base method.
+func (m *MockIReviewRepository) Update(ctx context.Context, review *entity.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIReviewRepositoryMockRecorder) Update(ctx, review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIReviewRepository)(nil).Update), ctx, review)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/review/entity"
+)
+
+type IReviewRepository interface {
+	GetUserID(ctx context.Context, username string) (int, error)
+	HasDeliveredPurchase(ctx context.Context, userID, productID int) (bool, error)
+	Create(ctx context.Context, review *entity.Review) error
+	GetByID(ctx context.Context, id int) (*entity.Review, error)
+	GetByUserAndProduct(ctx context.Context, userID, productID int) (*entity.Review, error)
+	GetByProduct(ctx context.Context, productID int, status entity.Status) ([]*entity.Review, error)
+	GetByStatus(ctx context.Context, status entity.Status) ([]*entity.Review, error)
+	Update(ctx context.Context, review *entity.Review) error
+	Moderate(ctx context.Context, review *entity.Review) error
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/review/entity"
+	"ecommerce/internal/review/repository"
+	"errors"
+	"strings"
+)
+
+// MaxBodyLength limits the review text in characters.
+const MaxBodyLength = 5000
+
+var (
+	ErrInvalidRating     = errors.New("rating must be between 1 and 5")
+	ErrBodyTooLong       = errors.New("review text is too long")
+	ErrInvalidStatus     = errors.New("invalid review status, expected pending, approved or rejected")
+	ErrInvalidModeration = errors.New("a review can only be approved or rejected")
+)
+
+type ReviewUsecase struct {
+	reviewRepo repository.IReviewRepository
+}
+
+func NewReviewUsecase(reviewRepo repository.IReviewRepository) *ReviewUsecase {
+	return &ReviewUsecase{
+		reviewRepo: reviewRepo,
+	}
+}
+
+func validateReview(rating int, body string) error {
+	if rating < 1 || rating > 5 {
+		return ErrInvalidRating
+	}
+	if len([]rune(body)) > MaxBodyLength {
+		return ErrBodyTooLong
+	}
+
+	return nil
+}
+
+// CreateReview adds the user's review of a product they received. It waits
+// for moderation before it is shown.
+func (ru *ReviewUsecase) CreateReview(ctx context.Context, username string, productID, rating int, body string) (*entity.Review, error) {
+	body = strings.TrimSpace(body)
+	if err := validateReview(rating, body); err != nil {
+		return nil, err
+	}
+
+	userID, err := ru.reviewRepo.GetUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := ru.reviewRepo.HasDeliveredPurchase(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, entity.ErrNotVerifiedBuyer
+	}
+
+	review := &entity.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Username:  username,
+		Rating:    rating,
+		Body:      body,
+	}
+
+	return review, ru.reviewRepo.Create(ctx, review)
+}
+
+// UpdateReview edits the user's own review of the product and sends it back
+// to moderation.
+func (ru *ReviewUsecase) UpdateReview(ctx context.Context, username string, productID, rating int, body string) (*entity.Review, error) {
+	body = strings.TrimSpace(body)
+	if err := validateReview(rating, body); err != nil {
+		return nil, err
+	}
+
+	review, err := ru.GetMyReview(ctx, username, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Rating = rating
+	review.Body = body
+	review.ModerationNote = ""
+	review.ModeratedBy = ""
+	review.ModeratedAt = nil
+
+	return review, ru.reviewRepo.Update(ctx, review)
+}
+
+func (ru *ReviewUsecase) GetMyReview(ctx context.Context, username string, productID int) (*entity.Review, error) {
+	userID, err := ru.reviewRepo.GetUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return ru.reviewRepo.GetByUserAndProduct(ctx, userID, productID)
+}
+
+// GetProductReviews returns the approved reviews of a product, newest first.
+func (ru *ReviewUsecase) GetProductReviews(ctx context.Context, productID int) ([]*entity.Review, error) {
+	return ru.reviewRepo.GetByProduct(ctx, productID, entity.StatusApproved)
+}
+
+// GetReviewsByStatus returns the moderation queue; an empty status means
+// pending.
+func (ru *ReviewUsecase) GetReviewsByStatus(ctx context.Context, status entity.Status) ([]*entity.Review, error) {
+	switch status {
+	case "":
+		status = entity.StatusPending
+	case entity.StatusPending, entity.StatusApproved, entity.StatusRejected:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	return ru.reviewRepo.GetByStatus(ctx, status)
+}
+
+func (ru *ReviewUsecase) ModerateReview(ctx context.Context, id int, status entity.Status, note string) (*entity.Review, error) {
+	if status != entity.StatusApproved && status != entity.StatusRejected {
+		return nil, ErrInvalidModeration
+	}
+
+	review := &entity.Review{
+		ID:             id,
+		Status:         status,
+		ModerationNote: strings.TrimSpace(note),
+	}
+	if err := ru.reviewRepo.Moderate(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return ru.reviewRepo.GetByID(ctx, id)
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/review/entity"
+	mock_repository "ecommerce/internal/review/mocks"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ReviewUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl      *gomock.Controller
+	mockRepo      *mock_repository.MockIReviewRepository
+	reviewUsecase *ReviewUsecase
+}
+
+func (suite *ReviewUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIReviewRepository(suite.mockCtrl)
+	suite.reviewUsecase = NewReviewUsecase(suite.mockRepo)
+}
+
+func (suite *ReviewUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestReviewUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewUsecaseTestSuite))
+}
+
+func (suite *ReviewUsecaseTestSuite) TestCreateReview() {
+	testCases := []struct {
+		name          string
+		rating        int
+		body          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:   "Successful review by a verified buyer",
+			rating: 5,
+			body:   "  Great keyboard  ",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().HasDeliveredPurchase(gomock.Any(), 7, 1).Return(true, nil)
+				suite.mockRepo.EXPECT().Create(gomock.Any(), &entity.Review{
+					ProductID: 1,
+					UserID:    7,
+					Username:  "alice",
+					Rating:    5,
+					Body:      "Great keyboard",
+				}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "Failed review - Not delivered",
+			rating: 4,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().HasDeliveredPurchase(gomock.Any(), 7, 1).Return(false, nil)
+			},
+			expectedError: entity.ErrNotVerifiedBuyer,
+		},
+		{
+			name:   "Failed review - Second review",
+			rating: 4,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().HasDeliveredPurchase(gomock.Any(), 7, 1).Return(true, nil)
+				suite.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(entity.ErrAlreadyReviewed)
+			},
+			expectedError: entity.ErrAlreadyReviewed,
+		},
+		{
+			name:          "Failed review - Rating out of range",
+			rating:        6,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidRating,
+		},
+		{
+			name:          "Failed review - Text too long",
+			rating:        3,
+			body:          strings.Repeat("a", MaxBodyLength+1),
+			mockBehavior:  func() {},
+			expectedError: ErrBodyTooLong,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.reviewUsecase.CreateReview(context.Background(), "alice", 1, tc.rating, tc.body)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *ReviewUsecaseTestSuite) TestUpdateReview() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Successful edit goes back to moderation",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().GetByUserAndProduct(gomock.Any(), 7, 1).Return(&entity.Review{
+					ID: 3, ProductID: 1, UserID: 7, Rating: 5, Status: entity.StatusApproved, ModeratedBy: "admin",
+				}, nil)
+				suite.mockRepo.EXPECT().Update(gomock.Any(), &entity.Review{
+					ID: 3, ProductID: 1, UserID: 7, Rating: 2, Body: "Broke after a week", Status: entity.StatusApproved,
+				}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Failed edit - No review yet",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().GetByUserAndProduct(gomock.Any(), 7, 1).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.reviewUsecase.UpdateReview(context.Background(), "alice", 1, 2, "Broke after a week")
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *ReviewUsecaseTestSuite) TestModerateReview() {
+	testCases := []struct {
+		name          string
+		status        entity.Status
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:   "Successful approval",
+			status: entity.StatusApproved,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Moderate(gomock.Any(), &entity.Review{ID: 3, Status: entity.StatusApproved, ModerationNote: "ok"}).Return(nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 3).Return(&entity.Review{ID: 3, Status: entity.StatusApproved}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed moderation - Back to pending",
+			status:        entity.StatusPending,
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidModeration,
+		},
+		{
+			name:   "Failed moderation - Repository error",
+			status: entity.StatusRejected,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Moderate(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			_, err := suite.reviewUsecase.ModerateReview(context.Background(), 3, tc.status, " ok ")
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *ReviewUsecaseTestSuite) TestGetReviewsByStatus() {
+	suite.mockRepo.EXPECT().GetByStatus(gomock.Any(), entity.StatusPending).Return([]*entity.Review{}, nil)
+
+	_, err := suite.reviewUsecase.GetReviewsByStatus(context.Background(), "")
+	suite.NoError(err)
+
+	_, err = suite.reviewUsecase.GetReviewsByStatus(context.Background(), "spam")
+	suite.Equal(ErrInvalidStatus, err)
+}
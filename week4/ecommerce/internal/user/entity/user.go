@@ -1,11 +1,15 @@
 package entity
 
+import "time"
+
 type User struct {
-	ID       int     `json:"id"`
-	Name     string  `json:"name"`
-	Username string  `json:"username"`
-	Email    string  `json:"email"`
-	Balance  float64 `json:"balance"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Balance   float64    `json:"balance"`
+	Version   int        `json:"version,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func (u *User) SetName(name string) *User {
@@ -4,7 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"ecommerce/internal/user/entity"
+	"ecommerce/pkg/utils"
+	"errors"
+	"time"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type UserPGRepository struct {
@@ -35,9 +40,11 @@ func (u *UserPGRepository) Create(ctx context.Context, user *entity.User) error
 	return nil
 }
 
-func (u *UserPGRepository) GetAll(ctx context.Context) ([]*entity.User, error) {
+// GetAll lists users. Soft deleted users are only included when
+// includeDeleted is set.
+func (u *UserPGRepository) GetAll(ctx context.Context, includeDeleted bool) ([]*entity.User, error) {
 	var users []*entity.User
-	rows, err := u.DB.QueryContext(ctx, "SELECT id, name, username, balance FROM users")
+	rows, err := u.DB.QueryContext(ctx, "SELECT id, name, username, balance, version, deleted_at FROM users WHERE $1 OR deleted_at IS NULL ORDER BY id", includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +58,7 @@ func (u *UserPGRepository) GetAll(ctx context.Context) ([]*entity.User, error) {
 	for rows.Next() {
 		user := &entity.User{}
 
-		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.Balance)
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.Balance, &user.Version, &user.DeletedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -61,12 +68,12 @@ func (u *UserPGRepository) GetAll(ctx context.Context) ([]*entity.User, error) {
 }
 
 func (u *UserPGRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
-	var user *entity.User
+	user := &entity.User{}
 	err := u.DB.QueryRowContext(
 		ctx,
-		"SELECT id, name, username, email, balance FROM users WHERE id = $1",
+		"SELECT id, name, username, email, balance, version FROM users WHERE id = $1 AND deleted_at IS NULL",
 		id,
-	).Scan(user.ID, user.Name, user.Username, user.Email, user.Balance)
+	).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Balance, &user.Version)
 
 	if err != nil {
 		return &entity.User{}, err
@@ -76,12 +83,12 @@ func (u *UserPGRepository) GetByID(ctx context.Context, id int) (*entity.User, e
 }
 
 func (u *UserPGRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
-	var user *entity.User
+	user := &entity.User{}
 	err := u.DB.QueryRowContext(
 		ctx,
-		"SELECT id, username, email, balance FROM users WHERE username = $1",
+		"SELECT id, username, email, balance FROM users WHERE username = $1 AND deleted_at IS NULL",
 		username,
-	).Scan(user.ID, user.Username, user.Email, user.Balance)
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Balance)
 	if err != nil {
 		return &entity.User{}, err
 	}
@@ -90,10 +97,10 @@ func (u *UserPGRepository) GetByUsername(ctx context.Context, username string) (
 }
 
 func (u *UserPGRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	var user *entity.User
+	user := &entity.User{}
 	err := u.DB.QueryRowContext(
 		ctx,
-		"SELECT id, username, email, balance FROM users WHERE email = $1", email).Scan(&user.ID, &user.Username, &user.Email, &user.Balance)
+		"SELECT id, username, email, balance FROM users WHERE email = $1 AND deleted_at IS NULL", email).Scan(&user.ID, &user.Username, &user.Email, &user.Balance)
 	if err != nil {
 		return &entity.User{}, err
 	}
@@ -101,6 +108,9 @@ func (u *UserPGRepository) GetByEmail(ctx context.Context, email string) (*entit
 	return user, nil
 }
 
+// Update applies the non-empty fields of user. With a user.Version it only
+// applies while the stored version is the same and returns
+// utils.ErrVersionConflict otherwise. user.Version is set to the new version.
 func (u *UserPGRepository) Update(ctx context.Context, user *entity.User) error {
 	query := "UPDATE users SET"
 	params := []interface{}{} // Slice to store the query parameters
@@ -123,26 +133,112 @@ func (u *UserPGRepository) Update(ctx context.Context, user *entity.User) error
 		params = append(params, user.Balance)
 	}
 
-	// Remove the trailing comma
-	query = query[:len(query)-1]
-
-	query += " WHERE id = ?"
+	query += " version = version + 1 WHERE id = ? AND deleted_at IS NULL"
 	params = append(params, user.ID)
+	if user.Version > 0 {
+		query += " AND version = ?"
+		params = append(params, user.Version)
+	}
+	query += " RETURNING version"
 
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
-	_, err := u.DB.ExecContext(ctx, query, params...)
+	err := u.DB.QueryRowContext(ctx, query, params...).Scan(&user.Version)
+	if errors.Is(err, sql.ErrNoRows) && user.Version > 0 {
+		return u.versionConflict(ctx, user.ID)
+	}
+
+	return err
+}
+
+// versionConflict tells a stale version apart from a missing user after a
+// versioned update matched no row.
+func (u *UserPGRepository) versionConflict(ctx context.Context, id int) error {
+	var exists bool
+	err := u.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists)
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return sql.ErrNoRows
+	}
 
-	return nil
+	return utils.ErrVersionConflict
 }
 
+// Delete soft deletes the user so their orders keep a customer. The account
+// can no longer log in until the user is restored.
 func (u *UserPGRepository) Delete(ctx context.Context, id int) error {
-	_, err := u.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+	return expectOneRow(u.DB.ExecContext(ctx, "UPDATE users SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL", id))
+}
+
+func (u *UserPGRepository) Restore(ctx context.Context, id int) error {
+	return expectOneRow(u.DB.ExecContext(ctx, "UPDATE users SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL", id))
+}
+
+// Purge hard deletes users deleted before the cutoff who never placed an
+// order, together with their accounts and roles. It returns the number of
+// users removed.
+func (u *UserPGRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT u.id FROM users u
+		WHERE u.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)
+		FOR UPDATE`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	queries := []string{
+		`DELETE FROM user_roles WHERE auth_id IN (SELECT id FROM accounts WHERE user_id = ANY($1))`,
+		`DELETE FROM accounts WHERE user_id = ANY($1)`,
+		`DELETE FROM users WHERE id = ANY($1)`,
+	}
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ids), tx.Commit()
+}
+
+// expectOneRow turns an update that matched nothing into sql.ErrNoRows.
+func expectOneRow(result sql.Result, err error) error {
 	if err != nil {
 		return err
 	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
@@ -3,14 +3,17 @@ package repository
 import (
 	"context"
 	"ecommerce/internal/user/entity"
+	"time"
 )
 
 type IUser interface {
 	Create(ctx context.Context, user *entity.User) error
-	GetAll(ctx context.Context) ([]*entity.User, error)
+	GetAll(ctx context.Context, includeDeleted bool) ([]*entity.User, error)
 	GetByID(ctx context.Context, id int) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
 }
@@ -4,6 +4,10 @@ import (
 	"context"
 	"ecommerce/internal/user/entity"
 	"ecommerce/internal/user/repository"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
+	"time"
 )
 
 type UserUsecase struct {
@@ -20,8 +24,8 @@ func (u *UserUsecase) CreateUser(ctx context.Context, user *entity.User) error {
 	return u.userRepo.Create(ctx, user)
 }
 
-func (u *UserUsecase) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
-	return u.userRepo.GetAll(ctx)
+func (u *UserUsecase) GetAllUsers(ctx context.Context, includeDeleted bool) ([]*entity.User, error) {
+	return u.userRepo.GetAll(ctx, includeDeleted)
 }
 
 func (u *UserUsecase) GetByUserID(ctx context.Context, id int) (*entity.User, error) {
@@ -36,10 +40,47 @@ func (u *UserUsecase) GetByUserEmail(ctx context.Context, email string) (*entity
 	return u.userRepo.GetByEmail(ctx, email)
 }
 
-func (u *UserUsecase) UpdateUser(ctx context.Context, user *entity.User) error {
-	return u.userRepo.Update(ctx, user)
+// UpdateUser changes the caller's own user, or any user for callers allowed
+// to. Users changing themselves can only change their name; their email is
+// changed through PUT /api/me, which has the new address verified. Anyone
+// else gets policy.ErrNotFound.
+func (u *UserUsecase) UpdateUser(ctx context.Context, caller *utils.Claims, user *entity.User) error {
+	current, err := u.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err = policy.Authorize(caller, current.Username, rbac.UserWriteAny); err != nil {
+		return err
+	}
+
+	admin := caller.HasPermission(rbac.UserWriteAny)
+	if !admin {
+		// Empty strings and a negative balance are left unchanged
+		user.Username, user.Email = "", ""
+		user.Balance = -1
+	}
+
+	if err = u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if !admin {
+		user.Username, user.Email, user.Balance = current.Username, current.Email, current.Balance
+	}
+
+	return nil
 }
 
 func (u *UserUsecase) DeleteUser(ctx context.Context, id int) error {
 	return u.userRepo.Delete(ctx, id)
 }
+
+func (u *UserUsecase) RestoreUser(ctx context.Context, id int) error {
+	return u.userRepo.Restore(ctx, id)
+}
+
+// PurgeDeletedUsers hard deletes users that were deleted more than retention
+// ago and never placed an order.
+func (u *UserUsecase) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int, error) {
+	return u.userRepo.Purge(ctx, time.Now().Add(-retention))
+}
@@ -2,10 +2,15 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"ecommerce/internal/user/entity"
 	mock_repository "ecommerce/internal/user/mocks"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/rbac"
+	"ecommerce/pkg/utils"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -94,7 +99,7 @@ func (suite *UserUsecaseTestSuite) TestGetAllUsers() {
 					{ID: 1, Name: "John Doe", Username: "johndoe", Email: "john@example.com", Balance: 100.0},
 					{ID: 2, Name: "Jane Doe", Username: "janedoe", Email: "jane@example.com", Balance: 50.0},
 				}
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(users, nil)
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return(users, nil)
 			},
 			expectedResult: []*entity.User{
 				{ID: 1, Name: "John Doe", Username: "johndoe", Email: "john@example.com", Balance: 100.0},
@@ -105,7 +110,7 @@ func (suite *UserUsecaseTestSuite) TestGetAllUsers() {
 		{
 			name: "Failed retrieval of users",
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().GetAll(gomock.Any()).Return(nil, errors.New("database error"))
+				suite.mockRepo.EXPECT().GetAll(gomock.Any(), false).Return(nil, errors.New("database error"))
 			},
 			expectedResult: nil,
 			expectedError:  errors.New("database error"),
@@ -115,7 +120,7 @@ func (suite *UserUsecaseTestSuite) TestGetAllUsers() {
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			users, err := suite.userUsecase.GetAllUsers(context.Background())
+			users, err := suite.userUsecase.GetAllUsers(context.Background(), false)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -256,36 +261,71 @@ func (suite *UserUsecaseTestSuite) TestGetByUserEmail() {
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdateUser() {
+	stored := &entity.User{ID: 1, Name: "Alice", Username: "alice", Email: "alice@example.com", Balance: 100}
+	self := &utils.Claims{Username: "alice", Permissions: []string{rbac.OrderCreate}}
+	other := &utils.Claims{Username: "mallory", Permissions: []string{rbac.OrderCreate}}
+	admin := &utils.Claims{Username: "admin", Permissions: []string{rbac.UserReadAny, rbac.UserWriteAny}}
+
 	testCases := []struct {
 		name          string
-		input         *entity.User
+		caller        *utils.Claims
 		mockBehavior  func()
+		expectedSaved *entity.User
 		expectedError error
 	}{
 		{
-			name: "Successful user update",
-			input: &entity.User{
-				ID:       1,
-				Name:     "John Doe Updated",
-				Username: "johndoe_updated",
-				Email:    "john_updated@example.com",
-				Balance:  150.0,
+			name:   "User updates their name but keeps username, email and balance",
+			caller: self,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
 			},
+			expectedSaved: &entity.User{ID: 1, Name: "Alice Updated", Balance: -1},
+		},
+		{
+			name:   "Admin updates another user",
+			caller: admin,
 			mockBehavior: func() {
-				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
 			},
-			expectedError: nil,
+			expectedSaved: &entity.User{ID: 1, Name: "Alice Updated", Username: "alice2", Email: "alice@example.org", Balance: 500},
 		},
 		{
-			name: "Failed user update",
-			input: &entity.User{
-				ID:       2,
-				Name:     "Jane Doe Updated",
-				Username: "janedoe_updated",
-				Email:    "jane_updated@example.com",
-				Balance:  75.0,
+			name:   "Failed user update - Another user",
+			caller: other,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed user update - Reader may not change users",
+			caller: &utils.Claims{Username: "support", Permissions: []string{rbac.UserReadAny}},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed user update - No caller",
+			caller: nil,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
+			},
+			expectedError: policy.ErrNotFound,
+		},
+		{
+			name:   "Failed user update - User does not exist",
+			caller: admin,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.User{}, sql.ErrNoRows)
 			},
+			expectedError: sql.ErrNoRows,
+		},
+		{
+			name:   "Failed user update - Database error",
+			caller: self,
 			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(stored, nil)
 				suite.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
 			},
 			expectedError: errors.New("database error"),
@@ -295,7 +335,12 @@ func (suite *UserUsecaseTestSuite) TestUpdateUser() {
 	for _, tc := range testCases {
 		suite.Run(tc.name, func() {
 			tc.mockBehavior()
-			err := suite.userUsecase.UpdateUser(context.Background(), tc.input)
+			if tc.expectedSaved != nil {
+				suite.mockRepo.EXPECT().Update(gomock.Any(), tc.expectedSaved).Return(nil)
+			}
+
+			input := &entity.User{ID: 1, Name: "Alice Updated", Username: "alice2", Email: "alice@example.org", Balance: 500}
+			err := suite.userUsecase.UpdateUser(context.Background(), tc.caller, input)
 			if tc.expectedError != nil {
 				suite.EqualError(err, tc.expectedError.Error())
 			} else {
@@ -342,3 +387,50 @@ func (suite *UserUsecaseTestSuite) TestDeleteUser() {
 		})
 	}
 }
+
+func (suite *UserUsecaseTestSuite) TestRestoreUser() {
+	testCases := []struct {
+		name          string
+		input         int
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Successful user restore",
+			input: 1,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Restore(gomock.Any(), 1).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Failed user restore - Not deleted",
+			input: 2,
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().Restore(gomock.Any(), 2).Return(sql.ErrNoRows)
+			},
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.userUsecase.RestoreUser(context.Background(), tc.input)
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *UserUsecaseTestSuite) TestPurgeDeletedUsers() {
+	retention := 90 * 24 * time.Hour
+	suite.mockRepo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, cutoff time.Time) (int, error) {
+		suite.WithinDuration(time.Now().Add(-retention), cutoff, time.Minute)
+		return 3, nil
+	})
+
+	purged, err := suite.userUsecase.PurgeDeletedUsers(context.Background(), retention)
+
+	suite.NoError(err)
+	suite.Equal(3, purged)
+}
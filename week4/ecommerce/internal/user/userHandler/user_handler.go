@@ -1,8 +1,13 @@
 package userHandler
 
 import (
+	"database/sql"
 	"ecommerce/internal/user/entity"
 	"ecommerce/internal/user/usecase"
+	"ecommerce/pkg/middleware"
+	"ecommerce/pkg/policy"
+	"ecommerce/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"strconv"
 )
@@ -51,7 +56,7 @@ func (uh *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 	var users []*entity.User
 	var err error
 
-	users, err = uh.uc.GetAllUsers(c.Context())
+	users, err = uh.uc.GetAllUsers(c.Context(), c.QueryBool("include_deleted"))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -62,14 +67,17 @@ func (uh *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 // UpdateUser handles updating an existing user
 //
 //	@Summary		Update a user
-//	@Description	Update details of an existing user
+//	@Description	Update details of an existing user. Users updating themselves can only change their name; emails are changed through PUT /api/me.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			user	body		entity.User	true	"User data"
-//	@Success		200		{string}	string		"User updated successfully"
-//	@Failure		400		{string}	string		"Bad Request"
-//	@Failure		500		{string}	string		"Internal Server Error"
+//	@Param			user		body		entity.User	true	"User data"
+//	@Param			If-Match	header		string		false	"Version the update is made against"
+//	@Success		200			{string}	string		"User updated successfully"
+//	@Failure		400			{string}	string		"Bad Request"
+//	@Failure		404			{string}	string		"Not Found"
+//	@Failure		409			{string}	string		"Conflict, the user changed since the given version"
+//	@Failure		500			{string}	string		"Internal Server Error"
 //	@Router			/users [put]
 func (uh *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	var user entity.User
@@ -84,17 +92,35 @@ func (uh *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	user.ID = id
 	// Decode request body to user struct
-	if err = c.BodyParser(user); err != nil {
+	if err = c.BodyParser(&user); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	user.ID = id
+
+	user.Version, err = middleware.ExpectedVersion(c, user.Version)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = uh.uc.UpdateUser(c.Context(), middleware.Claims(c), &user)
+	if errors.Is(err, utils.ErrVersionConflict) {
+		current, err := uh.uc.GetByUserID(c.Context(), id)
+		if err != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": utils.ErrVersionConflict.Error()})
+		}
 
-	err = uh.uc.UpdateUser(c.Context(), &user)
+		c.Set(fiber.HeaderETag, utils.VersionTag(current.Version, ""))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": utils.ErrVersionConflict.Error(), "current": current})
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, policy.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	c.Set(fiber.HeaderETag, utils.VersionTag(user.Version, ""))
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Account updated successfully", "user": user})
 }
 
@@ -105,15 +131,16 @@ func (uh *UserHandler) UpdateUser(c *fiber.Ctx) error {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	query		int		true	"User ID"
+//	@Param			id	path		int		true	"User ID"
 //	@Success		200	{string}	string	"User deleted successfully"
 //	@Failure		400	{string}	string	"Bad Request"
+//	@Failure		404	{string}	string	"Not Found"
 //	@Failure		500	{string}	string	"Internal Server Error"
-//	@Router			/users [delete]
+//	@Router			/users/{id} [delete]
 func (uh *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	var err error
 
-	idStr := c.Query("id")
+	idStr := c.Params("id")
 	if idStr == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID is required"})
 	}
@@ -124,6 +151,9 @@ func (uh *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	}
 
 	err = uh.uc.DeleteUser(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
 	if err != nil {
 		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		return nil
@@ -131,3 +161,32 @@ func (uh *UserHandler) DeleteUser(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "User deleted successfully"})
 }
+
+// RestoreUser handles restoring a soft deleted user
+//
+//	@Summary		Restore a user
+//	@Description	Undo the deletion of a user by its ID
+//	@Tags			users
+//	@Produce		json
+//	@Param			id	path		int		true	"User ID"
+//	@Success		200	{string}	string	"User restored successfully"
+//	@Failure		400	{string}	string	"Bad Request"
+//	@Failure		404	{string}	string	"Not Found"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/users/{id}/restore [post]
+func (uh *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	err = uh.uc.RestoreUser(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No deleted user with this ID"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "User restored successfully"})
+}
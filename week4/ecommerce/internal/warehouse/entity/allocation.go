@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// Location is a point on the map, in degrees.
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two locations.
+func (l Location) DistanceKm(other Location) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Allocation is the part of an order line shipped from one warehouse.
+type Allocation struct {
+	WarehouseID int `json:"warehouse_id"`
+	Quantity    int `json:"quantity"`
+}
+
+// Allocate decides which warehouses ship qty items. The nearest warehouse
+// that holds the full quantity wins; when none does, the quantity is split
+// across warehouses starting with the nearest. Without a destination,
+// warehouses are ranked by ID. Warehouses without a location rank last.
+func Allocate(levels []*StockLevel, qty int, shipTo *Location) ([]Allocation, error) {
+	ranked := make([]*StockLevel, 0, len(levels))
+	total := 0
+	for _, level := range levels {
+		if level.Quantity > 0 {
+			ranked = append(ranked, level)
+			total += level.Quantity
+		}
+	}
+
+	if qty <= 0 || total < qty {
+		return nil, ErrInsufficientStock
+	}
+
+	distance := func(level *StockLevel) float64 {
+		if shipTo == nil {
+			return 0
+		}
+		if level.Location == nil {
+			return math.Inf(1)
+		}
+		return shipTo.DistanceKm(*level.Location)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		di, dj := distance(ranked[i]), distance(ranked[j])
+		if di != dj {
+			return di < dj
+		}
+		return ranked[i].WarehouseID < ranked[j].WarehouseID
+	})
+
+	for _, level := range ranked {
+		if level.Quantity >= qty {
+			return []Allocation{{WarehouseID: level.WarehouseID, Quantity: qty}}, nil
+		}
+	}
+
+	var allocations []Allocation
+	remaining := qty
+	for _, level := range ranked {
+		take := min(level.Quantity, remaining)
+		allocations = append(allocations, Allocation{WarehouseID: level.WarehouseID, Quantity: take})
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+
+	return allocations, nil
+}
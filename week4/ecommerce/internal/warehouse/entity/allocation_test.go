@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocate(t *testing.T) {
+	hanoi := &Location{Latitude: 21.0285, Longitude: 105.8542}
+	hcmc := &Location{Latitude: 10.8231, Longitude: 106.6297}
+	levels := []*StockLevel{
+		{WarehouseID: 1, Location: hanoi, Quantity: 5},
+		{WarehouseID: 2, Location: hcmc, Quantity: 8},
+		{WarehouseID: 3, Quantity: 20},
+	}
+
+	testCases := []struct {
+		name          string
+		qty           int
+		shipTo        *Location
+		expected      []Allocation
+		expectedError error
+	}{
+		{
+			name:     "Nearest warehouse with full stock",
+			qty:      4,
+			shipTo:   &Location{Latitude: 10.7769, Longitude: 106.7009},
+			expected: []Allocation{{WarehouseID: 2, Quantity: 4}},
+		},
+		{
+			name:     "Skips nearest warehouse without full stock",
+			qty:      7,
+			shipTo:   &Location{Latitude: 21.0, Longitude: 105.8},
+			expected: []Allocation{{WarehouseID: 2, Quantity: 7}},
+		},
+		{
+			name:     "Warehouse without location only when nothing else fits",
+			qty:      12,
+			shipTo:   hanoi,
+			expected: []Allocation{{WarehouseID: 3, Quantity: 12}},
+		},
+		{
+			name:     "Split across warehouses nearest first",
+			qty:      30,
+			shipTo:   hcmc,
+			expected: []Allocation{{WarehouseID: 2, Quantity: 8}, {WarehouseID: 1, Quantity: 5}, {WarehouseID: 3, Quantity: 17}},
+		},
+		{
+			name:     "Without destination warehouses rank by ID",
+			qty:      3,
+			expected: []Allocation{{WarehouseID: 1, Quantity: 3}},
+		},
+		{
+			name:          "Insufficient total stock",
+			qty:           34,
+			shipTo:        hanoi,
+			expectedError: ErrInsufficientStock,
+		},
+		{
+			name:          "Invalid quantity",
+			qty:           0,
+			expectedError: ErrInsufficientStock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allocations, err := Allocate(levels, tc.qty, tc.shipTo)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, allocations)
+		})
+	}
+}
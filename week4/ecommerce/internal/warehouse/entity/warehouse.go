@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+type Warehouse struct {
+	ID        int        `json:"id"`
+	Code      string     `json:"code"`
+	Name      string     `json:"name"`
+	Location  *Location  `json:"location,omitempty"`
+	IsDefault bool       `json:"is_default"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// StockLevel is the quantity of one product held in one warehouse.
+type StockLevel struct {
+	WarehouseID   int       `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	Location      *Location `json:"-"`
+	Quantity      int       `json:"quantity"`
+}
+
+// ProductStock reports total and per-warehouse availability of a product.
+type ProductStock struct {
+	ProductID  int           `json:"product_id"`
+	Total      int           `json:"total"`
+	Warehouses []*StockLevel `json:"warehouses"`
+}
+
+// Transfer moves stock of a product from one warehouse to another.
+type Transfer struct {
+	ID              int        `json:"id"`
+	ProductID       int        `json:"product_id"`
+	FromWarehouseID int        `json:"from_warehouse_id"`
+	ToWarehouseID   int        `json:"to_warehouse_id"`
+	Quantity        int        `json:"quantity"`
+	Note            string     `json:"note,omitempty"`
+	Actor           string     `json:"actor,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+}
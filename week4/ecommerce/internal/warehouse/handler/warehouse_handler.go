@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"ecommerce/internal/warehouse/entity"
+	"ecommerce/internal/warehouse/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WarehouseHandler struct {
+	uc *usecase.WarehouseUsecase
+}
+
+func NewWarehouseHandler(uc *usecase.WarehouseUsecase) *WarehouseHandler {
+	return &WarehouseHandler{
+		uc: uc,
+	}
+}
+
+func warehouseError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrWarehouseNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidWarehouse),
+		errors.Is(err, usecase.ErrInvalidTransfer),
+		errors.Is(err, entity.ErrInsufficientStock):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+func (h *WarehouseHandler) GetAllWarehouses(c *fiber.Ctx) error {
+	warehouses, err := h.uc.GetAllWarehouses(c.Context())
+	if err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(warehouses)
+}
+
+func (h *WarehouseHandler) AddWarehouse(c *fiber.Ctx) error {
+	var warehouse entity.Warehouse
+	if err := c.BodyParser(&warehouse); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.uc.CreateWarehouse(c.Context(), &warehouse); err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(warehouse)
+}
+
+func (h *WarehouseHandler) UpdateWarehouse(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var warehouse entity.Warehouse
+	if err = c.BodyParser(&warehouse); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	warehouse.ID = id
+
+	if err = h.uc.UpdateWarehouse(c.Context(), &warehouse); err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(warehouse)
+}
+
+// GetProductStock handles GET /products/:id/stock
+func (h *WarehouseHandler) GetProductStock(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	stock, err := h.uc.GetProductStock(c.Context(), id)
+	if err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stock)
+}
+
+// TransferStock handles POST /warehouses/transfers with
+// {"product_id": 1, "from_warehouse_id": 1, "to_warehouse_id": 2, "quantity": 5}
+func (h *WarehouseHandler) TransferStock(c *fiber.Ctx) error {
+	var transfer entity.Transfer
+	if err := c.BodyParser(&transfer); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.uc.TransferStock(middleware.ActorContext(c), &transfer); err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+// GetTransfers handles GET /products/:id/transfers
+func (h *WarehouseHandler) GetTransfers(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	transfers, err := h.uc.GetTransfers(c.Context(), id)
+	if err != nil {
+		return warehouseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(transfers)
+}
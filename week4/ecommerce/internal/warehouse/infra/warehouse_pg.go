@@ -0,0 +1,246 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	inventoryEntity "ecommerce/internal/inventory/entity"
+	inventoryInfra "ecommerce/internal/inventory/infra"
+	"ecommerce/internal/warehouse/entity"
+	"ecommerce/pkg/utils"
+	"errors"
+)
+
+type WarehousePGRepository struct {
+	DB *sql.DB
+}
+
+func NewWarehousePGRepository(db *sql.DB) *WarehousePGRepository {
+	return &WarehousePGRepository{
+		DB: db,
+	}
+}
+
+func locationArgs(location *entity.Location) (interface{}, interface{}) {
+	if location == nil {
+		return nil, nil
+	}
+	return location.Latitude, location.Longitude
+}
+
+func scanLocation(latitude, longitude sql.NullFloat64) *entity.Location {
+	if !latitude.Valid || !longitude.Valid {
+		return nil
+	}
+	return &entity.Location{Latitude: latitude.Float64, Longitude: longitude.Float64}
+}
+
+func (r *WarehousePGRepository) Create(ctx context.Context, warehouse *entity.Warehouse) error {
+	latitude, longitude := locationArgs(warehouse.Location)
+	query := `INSERT INTO warehouses (code, name, latitude, longitude) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	return r.DB.QueryRowContext(ctx, query, warehouse.Code, warehouse.Name, latitude, longitude).Scan(&warehouse.ID, &warehouse.CreatedAt)
+}
+
+func scanWarehouse(row interface{ Scan(...interface{}) error }) (*entity.Warehouse, error) {
+	warehouse := &entity.Warehouse{}
+	var latitude, longitude sql.NullFloat64
+	err := row.Scan(&warehouse.ID, &warehouse.Code, &warehouse.Name, &latitude, &longitude, &warehouse.IsDefault, &warehouse.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.Location = scanLocation(latitude, longitude)
+
+	return warehouse, nil
+}
+
+func (r *WarehousePGRepository) GetAll(ctx context.Context) ([]*entity.Warehouse, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, code, name, latitude, longitude, is_default, created_at FROM warehouses ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []*entity.Warehouse
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *WarehousePGRepository) GetByID(ctx context.Context, id int) (*entity.Warehouse, error) {
+	query := `SELECT id, code, name, latitude, longitude, is_default, created_at FROM warehouses WHERE id = $1`
+	warehouse, err := scanWarehouse(r.DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return warehouse, err
+}
+
+func (r *WarehousePGRepository) Update(ctx context.Context, warehouse *entity.Warehouse) error {
+	latitude, longitude := locationArgs(warehouse.Location)
+	query := `UPDATE warehouses SET code = $1, name = $2, latitude = $3, longitude = $4 WHERE id = $5`
+	_, err := r.DB.ExecContext(ctx, query, warehouse.Code, warehouse.Name, latitude, longitude, warehouse.ID)
+	return err
+}
+
+func (r *WarehousePGRepository) GetProductStock(ctx context.Context, productID int) (*entity.ProductStock, error) {
+	query := `SELECT w.id, w.name, w.latitude, w.longitude, COALESCE(ws.quantity, 0)
+		FROM warehouses w
+		LEFT JOIN warehouse_stock ws ON ws.warehouse_id = w.id AND ws.product_id = $1
+		ORDER BY w.id`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := scanStockLevels(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := &entity.ProductStock{ProductID: productID, Warehouses: levels}
+	for _, level := range levels {
+		stock.Total += level.Quantity
+	}
+
+	return stock, nil
+}
+
+func scanStockLevels(rows *sql.Rows) ([]*entity.StockLevel, error) {
+	defer rows.Close()
+
+	var levels []*entity.StockLevel
+	for rows.Next() {
+		level := &entity.StockLevel{}
+		var latitude, longitude sql.NullFloat64
+		err := rows.Scan(&level.WarehouseID, &level.WarehouseName, &latitude, &longitude, &level.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		level.Location = scanLocation(latitude, longitude)
+		levels = append(levels, level)
+	}
+
+	return levels, rows.Err()
+}
+
+// LockStockLevels returns the stock of a product in every warehouse holding
+// it. The product row is locked first, like in inventory.ApplyMovement, so
+// concurrent orders cannot deadlock on the warehouse rows.
+func LockStockLevels(ctx context.Context, tx *sql.Tx, productID int) ([]*entity.StockLevel, error) {
+	var stock int
+	err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&stock)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT w.id, w.name, w.latitude, w.longitude, ws.quantity
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.id = ws.warehouse_id
+		WHERE ws.product_id = $1 AND ws.quantity > 0
+		ORDER BY w.id
+		FOR UPDATE OF ws`
+	rows, err := tx.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStockLevels(rows)
+}
+
+// Transfer moves stock between two warehouses. The product total does not
+// change; the ledger gets one movement out of the source and one into the
+// destination.
+func (r *WarehousePGRepository) Transfer(ctx context.Context, transfer *entity.Transfer) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if transfer.Actor == "" {
+		transfer.Actor = utils.ActorFromContext(ctx)
+	}
+
+	legs := []struct {
+		warehouseID int
+		quantity    int
+	}{
+		{transfer.FromWarehouseID, -transfer.Quantity},
+		{transfer.ToWarehouseID, transfer.Quantity},
+	}
+
+	for _, leg := range legs {
+		warehouseID := leg.warehouseID
+		err = inventoryInfra.ApplyMovement(ctx, tx, &inventoryEntity.Movement{
+			ProductID:   transfer.ProductID,
+			WarehouseID: &warehouseID,
+			Quantity:    leg.quantity,
+			Reason:      inventoryEntity.ReasonTransfer,
+			Note:        transfer.Note,
+			Actor:       transfer.Actor,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO stock_transfers (product_id, from_warehouse_id, to_warehouse_id, quantity, note, actor)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		transfer.ProductID,
+		transfer.FromWarehouseID,
+		transfer.ToWarehouseID,
+		transfer.Quantity,
+		transfer.Note,
+		transfer.Actor,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *WarehousePGRepository) GetTransfers(ctx context.Context, productID int) ([]*entity.Transfer, error) {
+	query := `SELECT id, product_id, from_warehouse_id, to_warehouse_id, quantity, COALESCE(note, ''), COALESCE(actor, ''), created_at
+		FROM stock_transfers
+		WHERE product_id = $1
+		ORDER BY id DESC`
+	rows, err := r.DB.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*entity.Transfer
+	for rows.Next() {
+		transfer := &entity.Transfer{}
+		err := rows.Scan(
+			&transfer.ID,
+			&transfer.ProductID,
+			&transfer.FromWarehouseID,
+			&transfer.ToWarehouseID,
+			&transfer.Quantity,
+			&transfer.Note,
+			&transfer.Actor,
+			&transfer.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
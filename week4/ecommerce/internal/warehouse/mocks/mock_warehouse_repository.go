@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/warehouse/repository/warehouse_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/warehouse/repository/warehouse_repository.go -destination=internal/warehouse/mocks/mock_warehouse_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/warehouse/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWarehouseRepository is a mock of IWarehouseRepository interface.
+type MockIWarehouseRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWarehouseRepositoryMockRecorder
+}
+
+// MockIWarehouseRepositoryMockRecorder is the mock recorder for MockIWarehouseRepository.
+type MockIWarehouseRepositoryMockRecorder struct {
+	mock *MockIWarehouseRepository
+}
+
+// NewMockIWarehouseRepository creates a new mock instance.
+func NewMockIWarehouseRepository(ctrl *gomock.Controller) *MockIWarehouseRepository {
+	mock := &MockIWarehouseRepository{ctrl: ctrl}
+	mock.recorder = &MockIWarehouseRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWarehouseRepository) EXPECT() *MockIWarehouseRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIWarehouseRepository) Create(ctx context.Context, warehouse *entity.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, warehouse)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIWarehouseRepositoryMockRecorder) Create(ctx, warehouse any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIWarehouseRepository)(nil).Create), ctx, warehouse)
+}
+
+// GetAll mocks base method.
+func (m *MockIWarehouseRepository) GetAll(ctx context.Context) ([]*entity.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entity.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockIWarehouseRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockIWarehouseRepository)(nil).GetAll), ctx)
+}
+
+// GetByID mocks base method.
+func (m *MockIWarehouseRepository) GetByID(ctx context.Context, id int) (*entity.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entity.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIWarehouseRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIWarehouseRepository)(nil).GetByID), ctx, id)
+}
+
+// GetProductStock mocks base method.
+func (m *MockIWarehouseRepository) GetProductStock(ctx context.Context, productID int) (*entity.ProductStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStock", ctx, productID)
+	ret0, _ := ret[0].(*entity.ProductStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock.
+func (mr *MockIWarehouseRepositoryMockRecorder) GetProductStock(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockIWarehouseRepository)(nil).GetProductStock), ctx, productID)
+}
+
+// GetTransfers mocks base method.
+func (m *MockIWarehouseRepository) GetTransfers(ctx context.Context, productID int) ([]*entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfers", ctx, productID)
+	ret0, _ := ret[0].([]*entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfers indicates an expected call of GetTransfers.
+func (mr *MockIWarehouseRepositoryMockRecorder) GetTransfers(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockIWarehouseRepository)(nil).GetTransfers), ctx, productID)
+}
+
+// Transfer mocks base method.
+func (m *MockIWarehouseRepository) Transfer(ctx context.Context, transfer *entity.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, transfer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockIWarehouseRepositoryMockRecorder) Transfer(ctx, transfer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockIWarehouseRepository)(nil).Transfer), ctx, transfer)
+}
+
+// Update mocks base method.
+func (m *MockIWarehouseRepository) Update(ctx context.Context, warehouse *entity.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, warehouse)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIWarehouseRepositoryMockRecorder) Update(ctx, warehouse any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIWarehouseRepository)(nil).Update), ctx, warehouse)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/warehouse/entity"
+)
+
+type IWarehouseRepository interface {
+	Create(ctx context.Context, warehouse *entity.Warehouse) error
+	GetAll(ctx context.Context) ([]*entity.Warehouse, error)
+	GetByID(ctx context.Context, id int) (*entity.Warehouse, error)
+	Update(ctx context.Context, warehouse *entity.Warehouse) error
+	GetProductStock(ctx context.Context, productID int) (*entity.ProductStock, error)
+	Transfer(ctx context.Context, transfer *entity.Transfer) error
+	GetTransfers(ctx context.Context, productID int) ([]*entity.Transfer, error)
+}
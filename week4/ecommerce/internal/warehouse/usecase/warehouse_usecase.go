@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/warehouse/entity"
+	"ecommerce/internal/warehouse/repository"
+	"errors"
+)
+
+var (
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInvalidWarehouse  = errors.New("warehouse code and name are required")
+	ErrInvalidTransfer   = errors.New("transfer needs two different warehouses and a positive quantity")
+)
+
+type WarehouseUsecase struct {
+	warehouseRepo repository.IWarehouseRepository
+}
+
+func NewWarehouseUsecase(warehouseRepo repository.IWarehouseRepository) *WarehouseUsecase {
+	return &WarehouseUsecase{
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (wu *WarehouseUsecase) CreateWarehouse(ctx context.Context, warehouse *entity.Warehouse) error {
+	if warehouse.Code == "" || warehouse.Name == "" {
+		return ErrInvalidWarehouse
+	}
+	return wu.warehouseRepo.Create(ctx, warehouse)
+}
+
+func (wu *WarehouseUsecase) GetAllWarehouses(ctx context.Context) ([]*entity.Warehouse, error) {
+	return wu.warehouseRepo.GetAll(ctx)
+}
+
+func (wu *WarehouseUsecase) UpdateWarehouse(ctx context.Context, warehouse *entity.Warehouse) error {
+	if warehouse.Code == "" || warehouse.Name == "" {
+		return ErrInvalidWarehouse
+	}
+
+	existing, err := wu.warehouseRepo.GetByID(ctx, warehouse.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrWarehouseNotFound
+	}
+
+	return wu.warehouseRepo.Update(ctx, warehouse)
+}
+
+// GetProductStock reports total and per-warehouse availability of a product.
+func (wu *WarehouseUsecase) GetProductStock(ctx context.Context, productID int) (*entity.ProductStock, error) {
+	return wu.warehouseRepo.GetProductStock(ctx, productID)
+}
+
+func (wu *WarehouseUsecase) TransferStock(ctx context.Context, transfer *entity.Transfer) error {
+	if transfer.Quantity <= 0 || transfer.FromWarehouseID == transfer.ToWarehouseID {
+		return ErrInvalidTransfer
+	}
+
+	for _, id := range []int{transfer.FromWarehouseID, transfer.ToWarehouseID} {
+		warehouse, err := wu.warehouseRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if warehouse == nil {
+			return ErrWarehouseNotFound
+		}
+	}
+
+	return wu.warehouseRepo.Transfer(ctx, transfer)
+}
+
+func (wu *WarehouseUsecase) GetTransfers(ctx context.Context, productID int) ([]*entity.Transfer, error) {
+	return wu.warehouseRepo.GetTransfers(ctx, productID)
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/warehouse/entity"
+	mock_repository "ecommerce/internal/warehouse/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WarehouseUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl         *gomock.Controller
+	mockRepo         *mock_repository.MockIWarehouseRepository
+	warehouseUsecase WarehouseUsecase
+}
+
+func (suite *WarehouseUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIWarehouseRepository(suite.mockCtrl)
+	suite.warehouseUsecase = *NewWarehouseUsecase(suite.mockRepo)
+}
+
+func (suite *WarehouseUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestWarehouseUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WarehouseUsecaseTestSuite))
+}
+
+func (suite *WarehouseUsecaseTestSuite) TestTransferStock() {
+	testCases := []struct {
+		name          string
+		input         *entity.Transfer
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name:  "Successful transfer",
+			input: &entity.Transfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 5},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Warehouse{ID: 1}, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 2).Return(&entity.Warehouse{ID: 2}, nil)
+				suite.mockRepo.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Failed transfer - Same warehouse",
+			input:         &entity.Transfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 1, Quantity: 5},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidTransfer,
+		},
+		{
+			name:          "Failed transfer - Invalid quantity",
+			input:         &entity.Transfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2},
+			mockBehavior:  func() {},
+			expectedError: ErrInvalidTransfer,
+		},
+		{
+			name:  "Failed transfer - Unknown warehouse",
+			input: &entity.Transfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 9, Quantity: 5},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Warehouse{ID: 1}, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 9).Return(nil, nil)
+			},
+			expectedError: ErrWarehouseNotFound,
+		},
+		{
+			name:  "Failed transfer - Insufficient stock",
+			input: &entity.Transfer{ProductID: 1, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 500},
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 1).Return(&entity.Warehouse{ID: 1}, nil)
+				suite.mockRepo.EXPECT().GetByID(gomock.Any(), 2).Return(&entity.Warehouse{ID: 2}, nil)
+				suite.mockRepo.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.ErrInsufficientStock)
+			},
+			expectedError: entity.ErrInsufficientStock,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+			err := suite.warehouseUsecase.TransferStock(context.Background(), tc.input)
+			if tc.expectedError != nil {
+				suite.EqualError(err, tc.expectedError.Error())
+			} else {
+				suite.NoError(err)
+			}
+		})
+	}
+}
+
+func (suite *WarehouseUsecaseTestSuite) TestCreateWarehouse() {
+	suite.EqualError(suite.warehouseUsecase.CreateWarehouse(context.Background(), &entity.Warehouse{Name: "North"}), ErrInvalidWarehouse.Error())
+
+	warehouse := &entity.Warehouse{Code: "NORTH", Name: "North", Location: &entity.Location{Latitude: 21.0, Longitude: 105.8}}
+	suite.mockRepo.EXPECT().Create(gomock.Any(), warehouse).Return(nil)
+	suite.NoError(suite.warehouseUsecase.CreateWarehouse(context.Background(), warehouse))
+}
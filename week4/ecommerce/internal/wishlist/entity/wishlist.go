@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrInStock         = errors.New("product is in stock, there is nothing to wait for")
+	ErrAlreadyWaiting  = errors.New("already subscribed to this product")
+)
+
+// Item is a product on a wishlist. Available is nil for products that never
+// run out.
+type Item struct {
+	ProductID int       `json:"product_id"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	ImagePath string    `json:"image_path"`
+	Available *int      `json:"available"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// Wishlist is a user's saved products. ShareToken is only set on the
+// owner's view.
+type Wishlist struct {
+	Owner      string  `json:"owner"`
+	ShareToken string  `json:"share_token,omitempty"`
+	Items      []*Item `json:"items"`
+}
+
+// Subscription asks for a notification when an out of stock product can be
+// bought again.
+type Subscription struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"-"`
+	Username    string     `json:"-"`
+	Email       string     `json:"-"`
+	ProductID   int        `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	CreatedAt   time.Time  `json:"created_at"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+}
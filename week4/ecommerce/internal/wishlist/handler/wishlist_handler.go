@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"database/sql"
+	"ecommerce/internal/wishlist/entity"
+	"ecommerce/internal/wishlist/usecase"
+	"ecommerce/pkg/middleware"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WishlistHandler struct {
+	uc *usecase.WishlistUsecase
+}
+
+func NewWishlistHandler(uc *usecase.WishlistUsecase) *WishlistHandler {
+	return &WishlistHandler{
+		uc: uc,
+	}
+}
+
+func wishlistError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrProductNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, entity.ErrInStock),
+		errors.Is(err, entity.ErrAlreadyWaiting):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
+	wishlist, err := h.uc.GetWishlist(c.Context(), middleware.Username(c))
+	if err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(wishlist)
+}
+
+// AddItem handles POST /me/wishlist/:productID. Adding a product twice is
+// not an error.
+func (h *WishlistHandler) AddItem(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("productID"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err = h.uc.AddItem(c.Context(), middleware.Username(c), productID); err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WishlistHandler) RemoveItem(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("productID"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err = h.uc.RemoveItem(c.Context(), middleware.Username(c), productID); err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Share handles POST /me/wishlist/share and returns the public link.
+func (h *WishlistHandler) Share(c *fiber.Ctx) error {
+	token, err := h.uc.Share(c.Context(), middleware.Username(c))
+	if err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"token": token, "url": "/wishlists/" + token})
+}
+
+func (h *WishlistHandler) Unshare(c *fiber.Ctx) error {
+	if err := h.uc.Unshare(c.Context(), middleware.Username(c)); err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSharedWishlist handles the public GET /wishlists/:token.
+func (h *WishlistHandler) GetSharedWishlist(c *fiber.Ctx) error {
+	wishlist, err := h.uc.GetSharedWishlist(c.Context(), c.Params("token"))
+	if err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(wishlist)
+}
+
+// Subscribe handles POST /products/:id/stock-subscription. Only out of stock
+// products can be subscribed to.
+func (h *WishlistHandler) Subscribe(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	subscription, err := h.uc.Subscribe(c.Context(), middleware.Username(c), id)
+	if err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(subscription)
+}
+
+func (h *WishlistHandler) Unsubscribe(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err = h.uc.Unsubscribe(c.Context(), middleware.Username(c), id); err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WishlistHandler) GetSubscriptions(c *fiber.Ctx) error {
+	subscriptions, err := h.uc.GetSubscriptions(c.Context(), middleware.Username(c))
+	if err != nil {
+		return wishlistError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(subscriptions)
+}
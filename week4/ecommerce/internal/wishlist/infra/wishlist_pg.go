@@ -0,0 +1,254 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"ecommerce/internal/wishlist/entity"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type WishlistPGRepository struct {
+	DB *sql.DB
+}
+
+func NewWishlistPGRepository(db *sql.DB) *WishlistPGRepository {
+	return &WishlistPGRepository{
+		DB: db,
+	}
+}
+
+func (r *WishlistPGRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1 AND deleted_at IS NULL`, username).Scan(&id)
+	return id, err
+}
+
+func (r *WishlistPGRepository) productExists(ctx context.Context, productID int) error {
+	var exists bool
+	err := r.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`, productID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return entity.ErrProductNotFound
+	}
+
+	return nil
+}
+
+// AddItem puts the product on the user's wishlist. Adding it twice is not
+// an error.
+func (r *WishlistPGRepository) AddItem(ctx context.Context, userID, productID int) error {
+	if err := r.productExists(ctx, productID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO wishlist_items (user_id, product_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.DB.ExecContext(ctx, query, userID, productID)
+	return err
+}
+
+func (r *WishlistPGRepository) RemoveItem(ctx context.Context, userID, productID int) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM wishlist_items WHERE user_id = $1 AND product_id = $2`, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetItems returns the wishlist newest first. Deleted products are left out.
+func (r *WishlistPGRepository) GetItems(ctx context.Context, userID int) ([]*entity.Item, error) {
+	query := `SELECT p.id, COALESCE(p.name, ''), COALESCE(p.price, 0.0), COALESCE(p.image_path, ''), pa.available, w.added_at
+		FROM wishlist_items w
+		JOIN products p ON p.id = w.product_id
+		JOIN product_availability pa ON pa.product_id = p.id
+		WHERE w.user_id = $1 AND p.deleted_at IS NULL
+		ORDER BY w.added_at DESC, p.id`
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*entity.Item{}
+	for rows.Next() {
+		item := &entity.Item{}
+		var available sql.NullInt64
+		if err = rows.Scan(&item.ProductID, &item.Name, &item.Price, &item.ImagePath, &available, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		if available.Valid {
+			n := int(available.Int64)
+			item.Available = &n
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetShareToken returns the user's share token, or "" when the wishlist is
+// not shared.
+func (r *WishlistPGRepository) GetShareToken(ctx context.Context, userID int) (string, error) {
+	var token string
+	err := r.DB.QueryRowContext(ctx, `SELECT token FROM wishlist_shares WHERE user_id = $1`, userID).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return token, err
+}
+
+func (r *WishlistPGRepository) SetShareToken(ctx context.Context, userID int, token string) error {
+	query := `INSERT INTO wishlist_shares (user_id, token) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = CURRENT_TIMESTAMP`
+	_, err := r.DB.ExecContext(ctx, query, userID, token)
+	return err
+}
+
+func (r *WishlistPGRepository) DeleteShareToken(ctx context.Context, userID int) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM wishlist_shares WHERE user_id = $1`, userID)
+	return err
+}
+
+// GetByShareToken returns the wishlist shared under token, or
+// sql.ErrNoRows.
+func (r *WishlistPGRepository) GetByShareToken(ctx context.Context, token string) (*entity.Wishlist, error) {
+	var userID int
+	wishlist := &entity.Wishlist{}
+	query := `SELECT u.id, COALESCE(u.name, u.username, '')
+		FROM wishlist_shares s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token = $1 AND u.deleted_at IS NULL`
+	if err := r.DB.QueryRowContext(ctx, query, token).Scan(&userID, &wishlist.Owner); err != nil {
+		return nil, err
+	}
+
+	items, err := r.GetItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wishlist.Items = items
+
+	return wishlist, nil
+}
+
+// Subscribe asks for a notification once the product is available again.
+// Only products that are out of stock right now can be subscribed to.
+func (r *WishlistPGRepository) Subscribe(ctx context.Context, userID, productID int) (*entity.Subscription, error) {
+	var available sql.NullInt64
+	subscription := &entity.Subscription{UserID: userID, ProductID: productID}
+	query := `SELECT COALESCE(p.name, ''), pa.available
+		FROM products p
+		JOIN product_availability pa ON pa.product_id = p.id
+		WHERE p.id = $1 AND p.deleted_at IS NULL`
+	err := r.DB.QueryRowContext(ctx, query, productID).Scan(&subscription.ProductName, &available)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !available.Valid || available.Int64 > 0 {
+		return nil, entity.ErrInStock
+	}
+
+	query = `INSERT INTO stock_subscriptions (user_id, product_id) VALUES ($1, $2) RETURNING id, created_at`
+	err = r.DB.QueryRowContext(ctx, query, userID, productID).Scan(&subscription.ID, &subscription.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, entity.ErrAlreadyWaiting
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *WishlistPGRepository) Unsubscribe(ctx context.Context, userID, productID int) error {
+	query := `DELETE FROM stock_subscriptions WHERE user_id = $1 AND product_id = $2 AND notified_at IS NULL`
+	result, err := r.DB.ExecContext(ctx, query, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetSubscriptions returns the user's open subscriptions.
+func (r *WishlistPGRepository) GetSubscriptions(ctx context.Context, userID int) ([]*entity.Subscription, error) {
+	query := `SELECT s.id, s.user_id, s.product_id, COALESCE(p.name, ''), s.created_at, s.notified_at
+		FROM stock_subscriptions s
+		JOIN products p ON p.id = s.product_id
+		WHERE s.user_id = $1 AND s.notified_at IS NULL
+		ORDER BY s.created_at, s.id`
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []*entity.Subscription{}
+	for rows.Next() {
+		s := &entity.Subscription{}
+		if err = rows.Scan(&s.ID, &s.UserID, &s.ProductID, &s.ProductName, &s.CreatedAt, &s.NotifiedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// ClaimBackInStock closes every open subscription whose product can be
+// bought again and returns them for notification. Closing and returning
+// happen in one statement, so concurrent runs never claim a subscription
+// twice.
+func (r *WishlistPGRepository) ClaimBackInStock(ctx context.Context) ([]*entity.Subscription, error) {
+	query := `UPDATE stock_subscriptions s
+		SET notified_at = CURRENT_TIMESTAMP
+		FROM products p, product_availability pa, users u
+		WHERE s.notified_at IS NULL
+		  AND p.id = s.product_id AND pa.product_id = p.id AND u.id = s.user_id
+		  AND p.deleted_at IS NULL AND u.deleted_at IS NULL
+		  AND (pa.available IS NULL OR pa.available > 0)
+		RETURNING s.id, s.user_id, COALESCE(u.username, ''), COALESCE(u.email, ''), s.product_id, COALESCE(p.name, ''), s.created_at, s.notified_at`
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []*entity.Subscription
+	for rows.Next() {
+		s := &entity.Subscription{}
+		err = rows.Scan(&s.ID, &s.UserID, &s.Username, &s.Email, &s.ProductID, &s.ProductName, &s.CreatedAt, &s.NotifiedAt)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, s)
+	}
+
+	return claimed, rows.Err()
+}
@@ -0,0 +1,216 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/wishlist/repository/wishlist_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/wishlist/repository/wishlist_repository.go -destination=internal/wishlist/mocks/mock_wishlist_repository.go
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	entity "ecommerce/internal/wishlist/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWishlistRepository is a mock of IWishlistRepository interface.
+type MockIWishlistRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWishlistRepositoryMockRecorder
+}
+
+// MockIWishlistRepositoryMockRecorder is the mock recorder for MockIWishlistRepository.
+type MockIWishlistRepositoryMockRecorder struct {
+	mock *MockIWishlistRepository
+}
+
+// NewMockIWishlistRepository creates a new mock instance.
+func NewMockIWishlistRepository(ctrl *gomock.Controller) *MockIWishlistRepository {
+	mock := &MockIWishlistRepository{ctrl: ctrl}
+	mock.recorder = &MockIWishlistRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWishlistRepository) EXPECT() *MockIWishlistRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddItem mocks base method.
+func (m *MockIWishlistRepository) AddItem(ctx context.Context, userID, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddItem", ctx, userID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddItem indicates an expected call of AddItem.
+func (mr *MockIWishlistRepositoryMockRecorder) AddItem(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddItem", reflect.TypeOf((*MockIWishlistRepository)(nil).AddItem), ctx, userID, productID)
+}
+
+// ClaimBackInStock mocks base method.
+func (m *MockIWishlistRepository) ClaimBackInStock(ctx context.Context) ([]*entity.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimBackInStock", ctx)
+	ret0, _ := ret[0].([]*entity.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimBackInStock indicates an expected call of ClaimBackInStock.
+func (mr *MockIWishlistRepositoryMockRecorder) ClaimBackInStock(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimBackInStock", reflect.TypeOf((*MockIWishlistRepository)(nil).ClaimBackInStock), ctx)
+}
+
+// DeleteShareToken mocks base method.
+func (m *MockIWishlistRepository) DeleteShareToken(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShareToken", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShareToken indicates an expected call of DeleteShareToken.
+func (mr *MockIWishlistRepositoryMockRecorder) DeleteShareToken(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShareToken", reflect.TypeOf((*MockIWishlistRepository)(nil).DeleteShareToken), ctx, userID)
+}
+
+// GetByShareToken mocks base method.
+func (m *MockIWishlistRepository) GetByShareToken(ctx context.Context, token string) (*entity.Wishlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByShareToken", ctx, token)
+	ret0, _ := ret[0].(*entity.Wishlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByShareToken indicates an expected call of GetByShareToken.
+func (mr *MockIWishlistRepositoryMockRecorder) GetByShareToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShareToken", reflect.TypeOf((*MockIWishlistRepository)(nil).GetByShareToken), ctx, token)
+}
+
+// GetItems mocks base method.
+func (m *MockIWishlistRepository) GetItems(ctx context.Context, userID int) ([]*entity.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", ctx, userID)
+	ret0, _ := ret[0].([]*entity.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockIWishlistRepositoryMockRecorder) GetItems(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockIWishlistRepository)(nil).GetItems), ctx, userID)
+}
+
+// GetShareToken mocks base method.
+func (m *MockIWishlistRepository) GetShareToken(ctx context.Context, userID int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareToken", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareToken indicates an expected call of GetShareToken.
+func (mr *MockIWishlistRepositoryMockRecorder) GetShareToken(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareToken", reflect.TypeOf((*MockIWishlistRepository)(nil).GetShareToken), ctx, userID)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockIWishlistRepository) GetSubscriptions(ctx context.Context, userID int) ([]*entity.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx, userID)
+	ret0, _ := ret[0].([]*entity.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockIWishlistRepositoryMockRecorder) GetSubscriptions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockIWishlistRepository)(nil).GetSubscriptions), ctx, userID)
+}
+
+// GetUserID mocks base method.
+func (m *MockIWishlistRepository) GetUserID(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserID", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserID indicates an expected call of GetUserID.
+func (mr *MockIWishlistRepositoryMockRecorder) GetUserID(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserID", reflect.TypeOf((*MockIWishlistRepository)(nil).GetUserID), ctx, username)
+}
+
+// RemoveItem mocks base method.
+func (m *MockIWishlistRepository) RemoveItem(ctx context.Context, userID, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveItem", ctx, userID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveItem indicates an expected call of RemoveItem.
+func (mr *MockIWishlistRepositoryMockRecorder) RemoveItem(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockIWishlistRepository)(nil).RemoveItem), ctx, userID, productID)
+}
+
+// SetShareToken mocks base method.
+func (m *MockIWishlistRepository) SetShareToken(ctx context.Context, userID int, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShareToken", ctx, userID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShareToken indicates an expected call of SetShareToken.
+func (mr *MockIWishlistRepositoryMockRecorder) SetShareToken(ctx, userID, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShareToken", reflect.TypeOf((*MockIWishlistRepository)(nil).SetShareToken), ctx, userID, token)
+}
+
+// Subscribe mocks base method.
+func (m *MockIWishlistRepository) Subscribe(ctx context.Context, userID, productID int) (*entity.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID, productID)
+	ret0, _ := ret[0].(*entity.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockIWishlistRepositoryMockRecorder) Subscribe(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockIWishlistRepository)(nil).Subscribe), ctx, userID, productID)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockIWishlistRepository) Unsubscribe(ctx context.Context, userID, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, userID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockIWishlistRepositoryMockRecorder) Unsubscribe(ctx, userID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockIWishlistRepository)(nil).Unsubscribe), ctx, userID, productID)
+}
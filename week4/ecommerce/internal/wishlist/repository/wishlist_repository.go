@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/internal/wishlist/entity"
+)
+
+type IWishlistRepository interface {
+	GetUserID(ctx context.Context, username string) (int, error)
+	AddItem(ctx context.Context, userID, productID int) error
+	RemoveItem(ctx context.Context, userID, productID int) error
+	GetItems(ctx context.Context, userID int) ([]*entity.Item, error)
+	GetShareToken(ctx context.Context, userID int) (string, error)
+	SetShareToken(ctx context.Context, userID int, token string) error
+	DeleteShareToken(ctx context.Context, userID int) error
+	GetByShareToken(ctx context.Context, token string) (*entity.Wishlist, error)
+	Subscribe(ctx context.Context, userID, productID int) (*entity.Subscription, error)
+	Unsubscribe(ctx context.Context, userID, productID int) error
+	GetSubscriptions(ctx context.Context, userID int) ([]*entity.Subscription, error)
+	ClaimBackInStock(ctx context.Context) ([]*entity.Subscription, error)
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"ecommerce/internal/wishlist/entity"
+	"ecommerce/internal/wishlist/repository"
+	"ecommerce/pkg/notify"
+	"encoding/hex"
+	"fmt"
+	"log"
+)
+
+type WishlistUsecase struct {
+	wishlistRepo repository.IWishlistRepository
+	notifier     notify.Notifier
+}
+
+func NewWishlistUsecase(wishlistRepo repository.IWishlistRepository, notifier notify.Notifier) *WishlistUsecase {
+	return &WishlistUsecase{
+		wishlistRepo: wishlistRepo,
+		notifier:     notifier,
+	}
+}
+
+func (wu *WishlistUsecase) AddItem(ctx context.Context, username string, productID int) error {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return wu.wishlistRepo.AddItem(ctx, userID, productID)
+}
+
+func (wu *WishlistUsecase) RemoveItem(ctx context.Context, username string, productID int) error {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return wu.wishlistRepo.RemoveItem(ctx, userID, productID)
+}
+
+// GetWishlist returns the user's own wishlist including its share token.
+func (wu *WishlistUsecase) GetWishlist(ctx context.Context, username string) (*entity.Wishlist, error) {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := wu.wishlistRepo.GetItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := wu.wishlistRepo.GetShareToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Wishlist{Owner: username, ShareToken: token, Items: items}, nil
+}
+
+// Share returns the token that makes the user's wishlist public, creating
+// one on first use. Sharing again keeps the same link.
+func (wu *WishlistUsecase) Share(ctx context.Context, username string) (string, error) {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := wu.wishlistRepo.GetShareToken(ctx, userID)
+	if err != nil || token != "" {
+		return token, err
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return "", err
+	}
+	token = hex.EncodeToString(b)
+
+	return token, wu.wishlistRepo.SetShareToken(ctx, userID, token)
+}
+
+// Unshare revokes the share link; a later Share creates a new one.
+func (wu *WishlistUsecase) Unshare(ctx context.Context, username string) error {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return wu.wishlistRepo.DeleteShareToken(ctx, userID)
+}
+
+func (wu *WishlistUsecase) GetSharedWishlist(ctx context.Context, token string) (*entity.Wishlist, error) {
+	return wu.wishlistRepo.GetByShareToken(ctx, token)
+}
+
+func (wu *WishlistUsecase) Subscribe(ctx context.Context, username string, productID int) (*entity.Subscription, error) {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return wu.wishlistRepo.Subscribe(ctx, userID, productID)
+}
+
+func (wu *WishlistUsecase) Unsubscribe(ctx context.Context, username string, productID int) error {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return wu.wishlistRepo.Unsubscribe(ctx, userID, productID)
+}
+
+func (wu *WishlistUsecase) GetSubscriptions(ctx context.Context, username string) ([]*entity.Subscription, error) {
+	userID, err := wu.wishlistRepo.GetUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return wu.wishlistRepo.GetSubscriptions(ctx, userID)
+}
+
+// NotifyBackInStock tells every subscriber whose product is available again.
+// Each subscription is closed when it is claimed, so it is notified once. It
+// is run by the scheduler.
+func (wu *WishlistUsecase) NotifyBackInStock(ctx context.Context) error {
+	claimed, err := wu.wishlistRepo.ClaimBackInStock(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range claimed {
+		// The subscription is already closed, so a failed delivery is only logged
+		if err := wu.notifier.Send(ctx, backInStockMessage(subscription)); err != nil {
+			log.Printf("failed to send back in stock notification %d: %v", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func backInStockMessage(subscription *entity.Subscription) notify.Message {
+	to := subscription.Email
+	if to == "" {
+		to = subscription.Username
+	}
+
+	return notify.Message{
+		Event:   "back_in_stock",
+		To:      to,
+		Subject: fmt.Sprintf("%s is back in stock", subscription.ProductName),
+		Body:    fmt.Sprintf("Good news, %s (#%d) can be ordered again.", subscription.ProductName, subscription.ProductID),
+		Data: map[string]interface{}{
+			"product_id": subscription.ProductID,
+			"username":   subscription.Username,
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/wishlist/entity"
+	mock_repository "ecommerce/internal/wishlist/mocks"
+	"ecommerce/pkg/notify"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type recordingNotifier struct {
+	sent []notify.Message
+	err  error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+type WishlistUsecaseTestSuite struct {
+	suite.Suite
+	mockCtrl        *gomock.Controller
+	mockRepo        *mock_repository.MockIWishlistRepository
+	notifier        *recordingNotifier
+	wishlistUsecase *WishlistUsecase
+}
+
+func (suite *WishlistUsecaseTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepo = mock_repository.NewMockIWishlistRepository(suite.mockCtrl)
+	suite.notifier = &recordingNotifier{}
+	suite.wishlistUsecase = NewWishlistUsecase(suite.mockRepo, suite.notifier)
+}
+
+func (suite *WishlistUsecaseTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestWishlistUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WishlistUsecaseTestSuite))
+}
+
+func (suite *WishlistUsecaseTestSuite) TestGetWishlist() {
+	items := []*entity.Item{{ProductID: 1, Name: "Keyboard"}}
+	suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+	suite.mockRepo.EXPECT().GetItems(gomock.Any(), 7).Return(items, nil)
+	suite.mockRepo.EXPECT().GetShareToken(gomock.Any(), 7).Return("abc", nil)
+
+	wishlist, err := suite.wishlistUsecase.GetWishlist(context.Background(), "alice")
+
+	suite.NoError(err)
+	suite.Equal(&entity.Wishlist{Owner: "alice", ShareToken: "abc", Items: items}, wishlist)
+}
+
+func (suite *WishlistUsecaseTestSuite) TestShare() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedToken string
+		expectedError error
+	}{
+		{
+			name: "Existing link is reused",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().GetShareToken(gomock.Any(), 7).Return("abc", nil)
+			},
+			expectedToken: "abc",
+		},
+		{
+			name: "New link is created",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().GetShareToken(gomock.Any(), 7).Return("", nil)
+				suite.mockRepo.EXPECT().SetShareToken(gomock.Any(), 7, gomock.Len(32)).Return(nil)
+			},
+		},
+		{
+			name: "Failed share - Database error",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().GetShareToken(gomock.Any(), 7).Return("", errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			token, err := suite.wishlistUsecase.Share(context.Background(), "alice")
+
+			suite.Equal(tc.expectedError, err)
+			if tc.expectedToken != "" {
+				suite.Equal(tc.expectedToken, token)
+			} else if err == nil {
+				suite.Len(token, 32)
+			}
+		})
+	}
+}
+
+func (suite *WishlistUsecaseTestSuite) TestSubscribe() {
+	testCases := []struct {
+		name          string
+		mockBehavior  func()
+		expectedError error
+	}{
+		{
+			name: "Successful subscription",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().Subscribe(gomock.Any(), 7, 1).Return(&entity.Subscription{ID: 3, ProductID: 1}, nil)
+			},
+		},
+		{
+			name: "Failed subscription - Product in stock",
+			mockBehavior: func() {
+				suite.mockRepo.EXPECT().GetUserID(gomock.Any(), "alice").Return(7, nil)
+				suite.mockRepo.EXPECT().Subscribe(gomock.Any(), 7, 1).Return(nil, entity.ErrInStock)
+			},
+			expectedError: entity.ErrInStock,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.mockBehavior()
+
+			_, err := suite.wishlistUsecase.Subscribe(context.Background(), "alice", 1)
+
+			suite.Equal(tc.expectedError, err)
+		})
+	}
+}
+
+func (suite *WishlistUsecaseTestSuite) TestNotifyBackInStock() {
+	suite.mockRepo.EXPECT().ClaimBackInStock(gomock.Any()).Return([]*entity.Subscription{
+		{ID: 1, Username: "alice", Email: "alice@example.com", ProductID: 4, ProductName: "Keyboard"},
+		{ID: 2, Username: "bob", ProductID: 4, ProductName: "Keyboard"},
+	}, nil)
+	suite.notifier.err = errors.New("smtp down")
+
+	// Delivery failures are logged, the claimed subscriptions stay closed
+	err := suite.wishlistUsecase.NotifyBackInStock(context.Background())
+
+	suite.NoError(err)
+	suite.Require().Len(suite.notifier.sent, 2)
+	suite.Equal("back_in_stock", suite.notifier.sent[0].Event)
+	suite.Equal("alice@example.com", suite.notifier.sent[0].To)
+	suite.Equal("bob", suite.notifier.sent[1].To)
+	suite.Equal("Keyboard is back in stock", suite.notifier.sent[1].Subject)
+}
+
+func (suite *WishlistUsecaseTestSuite) TestNotifyBackInStockError() {
+	suite.mockRepo.EXPECT().ClaimBackInStock(gomock.Any()).Return(nil, errors.New("database error"))
+
+	err := suite.wishlistUsecase.NotifyBackInStock(context.Background())
+
+	suite.Equal(errors.New("database error"), err)
+	suite.Empty(suite.notifier.sent)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"ecommerce/pkg/config"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultSize is the number of entries kept by the in-memory cache when
+// CACHE_SIZE is not set.
+const DefaultSize = 1000
+
+// Cache stores encoded values for a limited time. A miss is reported with
+// ok false and a nil error; errors are for a backend that cannot be reached.
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix drops every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// NewFromEnv uses the Redis server in REDIS_ADDR when set, so every instance
+// of the application shares one cache, and an in-memory LRU of CACHE_SIZE
+// entries otherwise.
+func NewFromEnv() Cache {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return NewRedisCache(config.NewRedis(addr, os.Getenv("REDIS_PASSWORD")))
+	}
+
+	size, err := strconv.Atoi(os.Getenv("CACHE_SIZE"))
+	if err != nil || size <= 0 {
+		size = DefaultSize
+	}
+
+	return NewLRU(size)
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	now = now.Add(time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("expected a to be expired")
+	}
+}
+
+func TestLRUDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	c.Set(ctx, "catalog:product:1", []byte("1"), time.Minute)
+	c.Set(ctx, "catalog:products", []byte("[]"), time.Minute)
+	c.Set(ctx, "other", []byte("x"), time.Minute)
+	c.DeletePrefix(ctx, "catalog:")
+
+	if _, ok, _ := c.Get(ctx, "catalog:product:1"); ok {
+		t.Fatal("expected catalog:product:1 to be deleted")
+	}
+	if _, ok, _ := c.Get(ctx, "other"); !ok {
+		t.Fatal("expected other to be kept")
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+// OIDCConfig is the OpenID Connect identity provider users can sign in with
+// instead of a password. RedirectURL is our callback, as registered with the
+// provider.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+func NewOIDCConfig(issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCConfig {
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+
+	return &OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"github.com/go-redis/redis/v8"
+)
+
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(addr, password string) *Redis {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+	})
+
+	return &Redis{
+		client: rdb,
+	}
+}
+
+func (r *Redis) Close() {
+	r.client.Close()
+}
+
+func (r *Redis) GetClient() *redis.Client {
+	return r.client
+}
@@ -0,0 +1,139 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxUploadSize is the largest image accepted for upload, in bytes.
+const MaxUploadSize = 5 << 20
+
+// MaxWidth and MaxHeight bound the pixels of an upload. A few compressed
+// bytes can describe a huge image, and decoding allocates every pixel.
+const (
+	MaxWidth  = 6000
+	MaxHeight = 6000
+)
+
+var (
+	ErrTooLarge        = errors.New("image exceeds the maximum upload size")
+	ErrTooManyPixels   = errors.New("image exceeds the maximum width or height")
+	ErrUnsupportedType = errors.New("unsupported image type, expected jpeg, png or gif")
+)
+
+// Rendition is a resized copy of an uploaded image.
+type Rendition struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+var (
+	Thumbnail = Rendition{Name: "thumbnail", MaxWidth: 150, MaxHeight: 150}
+	Medium    = Rendition{Name: "medium", MaxWidth: 600, MaxHeight: 600}
+)
+
+var contentTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// Check validates the size, content type and dimensions of data from its
+// header alone, without decoding the pixels. The returned format is one of
+// "jpeg", "png" or "gif".
+func Check(data []byte) (string, error) {
+	if len(data) > MaxUploadSize {
+		return "", ErrTooLarge
+	}
+
+	format, ok := contentTypes[http.DetectContentType(data)]
+	if !ok {
+		return "", ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedType
+	}
+	if config.Width > MaxWidth || config.Height > MaxHeight {
+		return "", ErrTooManyPixels
+	}
+
+	return format, nil
+}
+
+// Decode checks data like Check and decodes it.
+func Decode(data []byte) (image.Image, string, error) {
+	format, err := Check(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedType
+	}
+
+	return img, format, nil
+}
+
+// Resize scales img down to fit within the rendition bounds, keeping the
+// aspect ratio. Images that already fit are returned unchanged.
+func Resize(img image.Image, r Rendition) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= r.MaxWidth && height <= r.MaxHeight {
+		return img
+	}
+
+	scale := min(float64(r.MaxWidth)/float64(width), float64(r.MaxHeight)/float64(height))
+	dstWidth := max(1, int(float64(width)*scale))
+	dstHeight := max(1, int(float64(height)*scale))
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// Encode writes img in the given format. GIF renditions are stored as PNG
+// so the resized frame keeps its transparency without palette quantisation.
+func Encode(img image.Image, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/png"
+
+	switch format {
+	case "jpeg":
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	default:
+		err = png.Encode(&buf, img)
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// Extension returns the file extension for an image of the given content type.
+func Extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
@@ -0,0 +1,51 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCheckDimensions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height int
+		expectedError error
+	}{
+		{"Within the limits", MaxWidth, 1, nil},
+		{"Too wide", MaxWidth + 1, 1, ErrTooManyPixels},
+		{"Too high", 1, MaxHeight + 1, ErrTooManyPixels},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// A blank image compresses to a few bytes, well below MaxUploadSize
+			data := encodePNG(t, tc.width, tc.height)
+
+			format, err := Check(data)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected %v, got %v", tc.expectedError, err)
+			}
+			if err == nil && format != "png" {
+				t.Errorf("expected png, got %q", format)
+			}
+
+			if _, _, err = Decode(data); !errors.Is(err, tc.expectedError) {
+				t.Errorf("Decode: expected %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
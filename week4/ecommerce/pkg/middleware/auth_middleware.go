@@ -1,14 +1,50 @@
 package middleware
 
 import (
+	"context"
 	"ecommerce/pkg/utils"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthMiddleware() fiber.Handler {
+// TokenRevocations is checked for access tokens revoked before they expire,
+// by a logout or a reused refresh token.
+type TokenRevocations interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// HeaderAPIKey carries the API key of service-to-service requests.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKeys authenticates API keys. It gives nil claims for a key that is
+// unknown, expired or revoked.
+type APIKeys interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*utils.Claims, error)
+}
+
+// AuthMiddleware validates the bearer token, or the API key in HeaderAPIKey.
+// revocations may be nil, in which case tokens are valid until they expire;
+// apiKeys may be nil to accept tokens only.
+func AuthMiddleware(revocations TokenRevocations, apiKeys APIKeys) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if key := c.Get(HeaderAPIKey); key != "" && apiKeys != nil {
+			claim, err := apiKeys.AuthenticateAPIKey(c.Context(), key)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			if claim == nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid API key",
+				})
+			}
+
+			c.Locals("claims", claim)
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -31,6 +67,20 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
+		if revocations != nil && claim.ID != "" {
+			revoked, err := revocations.IsRevoked(c.Context(), claim.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			if revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Token has been revoked",
+				})
+			}
+		}
+
 		// Store the claims in the context
 		c.Locals("claims", claim)
 
@@ -39,11 +89,11 @@ func AuthMiddleware() fiber.Handler {
 	}
 }
 
-func IsAdminMiddleware() fiber.Handler {
+// RequirePermission only lets requests through whose token grants
+// permission.
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		claim := c.Locals("claims").(*utils.Claims)
-
-		if claim.Role != "admin" {
+		if !HasPermission(c, permission) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Unauthorized",
 			})
@@ -53,16 +103,70 @@ func IsAdminMiddleware() fiber.Handler {
 	}
 }
 
-func IsUserMiddleware() fiber.Handler {
+// HasPermission reports whether the authenticated token grants permission,
+// for handlers that show more on routes open to everyone.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	claim, ok := c.Locals("claims").(*utils.Claims)
+	return ok && claim.HasPermission(permission)
+}
+
+// EmailVerification tells whether an account has confirmed its email.
+type EmailVerification interface {
+	IsEmailVerified(ctx context.Context, username string) (bool, error)
+}
+
+// VerifiedEmailMiddleware only lets accounts with a verified email through.
+func VerifiedEmailMiddleware(accounts EmailVerification) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		claim := c.Locals("claims").(*utils.Claims)
+		verified, err := accounts.IsEmailVerified(c.Context(), Username(c))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 
-		if claim.Role != "user" {
+		if !verified {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Unauthorized",
+				"error": "Verify your email address before placing orders",
 			})
 		}
 
 		return c.Next()
 	}
 }
+
+// ActorContext returns the request context tagged with the authenticated
+// username, so repositories can record who made a change.
+func ActorContext(c *fiber.Ctx) context.Context {
+	if claim, ok := c.Locals("claims").(*utils.Claims); ok {
+		return utils.WithActor(c.Context(), claim.Username)
+	}
+
+	return c.Context()
+}
+
+// Claims returns the authenticated caller, or nil without valid claims.
+func Claims(c *fiber.Ctx) *utils.Claims {
+	claim, _ := c.Locals("claims").(*utils.Claims)
+	return claim
+}
+
+// Username returns the authenticated username, or "" without valid claims.
+func Username(c *fiber.Ctx) string {
+	if claim, ok := c.Locals("claims").(*utils.Claims); ok {
+		return claim.Username
+	}
+
+	return ""
+}
+
+// ExpectedVersion returns the version an update is made against: the one in
+// If-Match when sent, otherwise bodyVersion from the submitted document. 0
+// means the update applies to any version.
+func ExpectedVersion(c *fiber.Ctx, bodyVersion int) (int, error) {
+	if header := c.Get(fiber.HeaderIfMatch); header != "" {
+		return utils.ParseVersionTag(header)
+	}
+
+	return bodyVersion, nil
+}
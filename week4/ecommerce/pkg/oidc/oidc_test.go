@@ -0,0 +1,106 @@
+package oidc_test
+
+import (
+	"context"
+	"ecommerce/pkg/config"
+	"ecommerce/pkg/oidc"
+	"ecommerce/pkg/oidc/oidctest"
+	"errors"
+	"testing"
+)
+
+func newProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	server, err := oidctest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	cfg := config.NewOIDCConfig(server.Issuer(), oidctest.ClientID, oidctest.ClientSecret, oidctest.RedirectURL, nil)
+	return oidc.NewProvider(cfg, nil), server
+}
+
+func TestExchange(t *testing.T) {
+	ctx := context.Background()
+	user := oidctest.User{Subject: "u-123", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+
+	testCases := []struct {
+		name          string
+		verifier      string
+		nonce         string
+		expectedError bool
+	}{
+		{"Valid code", "verifier", "nonce", false},
+		{"Wrong PKCE verifier", "other verifier", "nonce", true},
+		{"Wrong nonce", "verifier", "other nonce", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, server := newProvider(t)
+			server.SignInAs(user)
+
+			authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, state, err := server.Authorize(authURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state != "state" {
+				t.Fatalf("expected state to come back, got %q", state)
+			}
+
+			token, err := provider.Exchange(ctx, code, tc.verifier, tc.nonce)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Subject != user.Subject || token.Email != user.Email || !token.EmailVerified || token.Issuer != server.Issuer() {
+				t.Errorf("unexpected ID token %+v", token)
+			}
+		})
+	}
+}
+
+func TestCodeUsedOnce(t *testing.T) {
+	ctx := context.Background()
+	provider, server := newProvider(t)
+	server.SignInAs(oidctest.User{Subject: "u-123"})
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = provider.Exchange(ctx, code, "verifier", "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = provider.Exchange(ctx, code, "verifier", "nonce"); err == nil {
+		t.Fatal("expected the second exchange to fail")
+	}
+}
+
+func TestDiscoveryChecksIssuer(t *testing.T) {
+	server, err := oidctest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cfg := config.NewOIDCConfig(server.Issuer()+"/", oidctest.ClientID, oidctest.ClientSecret, oidctest.RedirectURL, nil)
+	_, err = oidc.NewProvider(cfg, nil).AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err == nil || errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("expected a discovery error, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package policy decides who may see and change resources that belong to a
+// user.
+package policy
+
+import (
+	"ecommerce/pkg/utils"
+	"errors"
+)
+
+// ErrNotFound is returned in place of a resource the caller may not access,
+// so that its existence is not revealed.
+var ErrNotFound = errors.New("not found")
+
+// CanAccess reports whether caller may act on a resource owned by owner: the
+// owner always may, anyone else needs permission. API keys own nothing, so
+// an account named like one gains nothing.
+func CanAccess(caller *utils.Claims, owner, permission string) bool {
+	if caller == nil {
+		return false
+	}
+
+	return (owner != "" && caller.APIKeyID == 0 && caller.Username == owner) || caller.HasPermission(permission)
+}
+
+// Authorize returns ErrNotFound unless CanAccess allows the access.
+func Authorize(caller *utils.Claims, owner, permission string) error {
+	if !CanAccess(caller, owner, permission) {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package rbac lists the permissions routes are guarded with. Roles and
+// their permissions live in the database; a permission only means
+// something once a route checks for it, so the names are fixed here.
+package rbac
+
+const (
+	ProductWrite       = "product:write"
+	ProductReadDeleted = "product:read:deleted"
+	PriceWrite         = "price:write"
+	InventoryWrite     = "inventory:write"
+	ReportRead         = "report:read"
+	OrderCreate        = "order:create"
+	OrderReadAny       = "order:read:any"
+	OrderWriteAny      = "order:write:any"
+	ReviewWrite        = "review:write"
+	ReviewModerate     = "review:moderate"
+	WishlistWrite      = "wishlist:write"
+	UserReadAny        = "user:read:any"
+	UserWriteAny       = "user:write:any"
+	AccountManage      = "account:manage"
+	RoleManage         = "role:manage"
+	APIKeyManage       = "apikey:manage"
+)
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions is every permission that can be granted to a role.
+var Permissions = []Permission{
+	{ProductWrite, "Create, edit and delete products, their images, bundles, digital files and licence keys, and import products"},
+	{ProductReadDeleted, "See deleted products"},
+	{PriceWrite, "Schedule and cancel price changes"},
+	{InventoryWrite, "Receive, adjust and transfer stock, and manage warehouses and reorder policies"},
+	{ReportRead, "Read stock and price history, low stock alerts, reorder suggestions and product exports"},
+	{OrderCreate, "Place orders"},
+	{OrderReadAny, "Read every customer's orders and deliveries"},
+	{OrderWriteAny, "Edit, ship and delete any order"},
+	{ReviewWrite, "Write product reviews"},
+	{ReviewModerate, "Read and moderate all reviews"},
+	{WishlistWrite, "Keep a wishlist and stock notifications"},
+	{UserReadAny, "Read all users, including deleted ones"},
+	{UserWriteAny, "Create, edit, delete and restore users"},
+	{AccountManage, "List, lock and unlock accounts, assign roles and reset two-factor authentication"},
+	{RoleManage, "Create and edit roles and their permissions"},
+	{APIKeyManage, "Create, list and revoke API keys for other services"},
+}
+
+// Valid reports whether name is a known permission.
+func Valid(name string) bool {
+	for _, permission := range Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CustomerPermissions are the ones the built-in user role is seeded with.
+// Anything beyond them is elevated.
+var CustomerPermissions = []string{OrderCreate, ReviewWrite, WishlistWrite}
+
+// Elevated reports whether permission is one customers do not get, such as
+// editing prices or orders.
+func Elevated(permission string) bool {
+	for _, customer := range CustomerPermissions {
+		if customer == permission {
+			return false
+		}
+	}
+
+	return true
+}
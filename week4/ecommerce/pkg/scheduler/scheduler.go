@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// Every runs job once per interval until ctx is cancelled. Errors are logged
+// and do not stop the schedule. It blocks, so start it in a goroutine.
+func Every(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Printf("scheduler: %s failed: %v", name, err)
+			}
+		}
+	}
+}
+
+// IntervalFromEnv reads a duration such as "15m" from the environment
+// variable key and falls back to def when it is empty or invalid.
+func IntervalFromEnv(key string, def time.Duration) time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || interval <= 0 {
+		return def
+	}
+
+	return interval
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	LocalDir       = "uploads"
+	LocalURLPrefix = "/uploads"
+
+	// PrivateLocalDir holds files that must not be served statically
+	PrivateLocalDir = "private"
+)
+
+// LocalStorage keeps files on disk, to be served as static files.
+type LocalStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+func NewLocalStorage(dir, urlPrefix string) *LocalStorage {
+	return &LocalStorage{
+		dir:       dir,
+		urlPrefix: urlPrefix,
+	}
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	fullPath := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(fullPath, data, 0o644); err != nil {
+		return "", err
+	}
+
+	return path.Join(s.urlPrefix, key), nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
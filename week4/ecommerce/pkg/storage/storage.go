@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"ecommerce/pkg/config"
+	"os"
+)
+
+// Storage persists uploaded files and returns the URL they are served from.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromEnv returns an S3 backed storage when AWS_BUCKET_NAME is set and
+// falls back to the local uploads directory otherwise.
+func NewFromEnv() (Storage, error) {
+	if bucket := os.Getenv("AWS_BUCKET_NAME"); bucket != "" {
+		return NewS3Storage(*config.NewS3Config(
+			os.Getenv("AWS_REGION"),
+			bucket,
+			os.Getenv("AWS_ACCESS_KEY_ID"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		))
+	}
+
+	return NewLocalStorage(LocalDir, LocalURLPrefix), nil
+}
+
+// NewPrivateFromEnv returns the storage for files that are only handed out
+// by the application, such as paid downloads. It uses the S3 bucket in
+// AWS_PRIVATE_BUCKET_NAME when set and a local directory that is not served
+// as static files otherwise.
+func NewPrivateFromEnv() (Storage, error) {
+	if bucket := os.Getenv("AWS_PRIVATE_BUCKET_NAME"); bucket != "" {
+		return NewS3Storage(*config.NewS3Config(
+			os.Getenv("AWS_REGION"),
+			bucket,
+			os.Getenv("AWS_ACCESS_KEY_ID"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		))
+	}
+
+	return NewLocalStorage(PrivateLocalDir, ""), nil
+}
@@ -0,0 +1,116 @@
+// Package tabular reads and writes simple tables as CSV or XLSX so the same
+// code can serve spreadsheet imports and exports in either format.
+package tabular
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format, expected csv or xlsx")
+
+// ParseFormat accepts "csv" or "xlsx" in any case.
+func ParseFormat(raw string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(raw))) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// FormatFromFilename picks the format from the file extension.
+func FormatFromFilename(name string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv"
+}
+
+// Read returns every row of the input. For XLSX only the first sheet is
+// read. Rows may have fewer cells than the header when trailing cells are
+// empty.
+func Read(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case CSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case XLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+
+		return f.GetRows(sheets[0])
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Write writes rows to w. XLSX output uses a single sheet with the given name.
+func Write(w io.Writer, format Format, sheet string, rows [][]string) error {
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case XLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+
+		if err := f.SetSheetName("Sheet1", sheet); err != nil {
+			return err
+		}
+
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+			}
+
+			if err = f.SetSheetRow(sheet, cell, &values); err != nil {
+				return err
+			}
+		}
+
+		_, err := f.WriteTo(w)
+		return err
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
@@ -0,0 +1,57 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"id", "name", "price"},
+		{"1", "Mouse, wireless", "19.99"},
+		{"", "Keyboard", "49"},
+	}
+
+	for _, format := range []Format{CSV, XLSX} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, "Products", rows); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Read(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(rows) {
+				t.Fatalf("expected %d rows, got %d", len(rows), len(got))
+			}
+			for i := range rows {
+				for j := range rows[i] {
+					if j < len(got[i]) && got[i][j] != rows[i][j] {
+						t.Errorf("row %d cell %d: expected %q, got %q", i, j, rows[i][j], got[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	testCases := map[string]Format{
+		"catalog.csv":  CSV,
+		"Catalog.XLSX": XLSX,
+	}
+
+	for name, expected := range testCases {
+		format, err := FormatFromFilename(name)
+		if err != nil || format != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", name, expected, format, err)
+		}
+	}
+
+	if _, err := FormatFromFilename("catalog.pdf"); err == nil {
+		t.Error("expected an error for pdf")
+	}
+}
@@ -0,0 +1,86 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA-1 secret of RFC 6238, appendix B, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// The last six digits of the RFC's eight digit codes
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tc := range testCases {
+		code, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.code {
+			t.Errorf("at %d: expected %s, got %s", tc.unix, tc.code, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"Current code", code(current), -1, current, true},
+		{"Previous code within skew", code(current - 1), -1, current - 1, true},
+		{"Next code within skew", code(current + 1), -1, current + 1, true},
+		{"Code outside skew", code(current - 2), -1, 0, false},
+		{"Code already used", code(current), current, 0, false},
+		{"Wrong length", "12345", -1, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tc.code, now, tc.lastStep)
+			if ok != tc.wantOK || step != tc.wantStep {
+				t.Errorf("expected (%d, %v), got (%d, %v)", tc.wantStep, tc.wantOK, step, ok)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Code(secret, 1); err != nil {
+		t.Fatalf("generated secret %q does not decode: %v", secret, err)
+	}
+
+	uri := ProvisioningURI("Shop", "alice@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Shop:alice@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected provisioning URI %s", uri)
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that carries the username performing the
+// request, for audit trails written deep in the repositories.
+func WithActor(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, actorKey{}, username)
+}
+
+// ActorFromContext returns the username stored by WithActor, or "".
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -1,47 +1,144 @@
 package utils
 
 import (
-	"github.com/golang-jwt/jwt/v5"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// AccessTokenTTL is kept short because an access token is only checked
+// against the revocation list, not the database; refresh tokens extend the
+// session.
+const AccessTokenTTL = 15 * time.Minute
+
+const (
+	defaultIssuer   = "ecommerce"
+	defaultAudience = "ecommerce-api"
+)
+
+// Claims carry the account's roles and the permissions they grant, so
+// requests are authorised without a database lookup. Role is the primary
+// role, kept for clients that read it.
 type Claims struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// APIKeyID is set instead of a token for requests made with an API key
+	APIKeyID int `json:"-"`
 	jwt.RegisteredClaims
 }
 
-var (
-	jwtKey = []byte(os.Getenv("JWT_SECRET"))
-)
+// HasPermission reports whether the token grants permission.
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signingKeys is loaded from the database at startup and kept current by
+// the key rotation job.
+var signingKeys = &KeySet{}
+
+// SetSigningKeys replaces the keys access tokens are signed and verified
+// with.
+func SetSigningKeys(keys ...*SigningKey) {
+	signingKeys.Set(keys...)
+}
+
+// SigningJWKS returns the public keys currently accepted for access tokens.
+func SigningJWKS() JWKS {
+	return signingKeys.JWKS(time.Now())
+}
+
+// The issuer and audience are read on every use rather than at package
+// init, which runs before .env is loaded.
+func jwtIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+
+	return defaultIssuer
+}
+
+func jwtAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+
+	return defaultAudience
+}
+
+// GenerateJWT issues an access token. The claims are returned as well, as
+// their ID (jti) is what a revocation refers to.
+func GenerateJWT(username string, role string, roles, permissions []string) (string, *Claims, error) {
+	now := time.Now()
+	key, err := signingKeys.signing(now)
+	if err != nil {
+		return "", nil, err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", nil, err
+	}
 
-func GenerateJWT(username string, role string) (string, error) {
-	expirationTime := time.Now().Add(time.Hour)
 	claims := &Claims{
-		Username: username,
-		Role:     role,
+		Username:    username,
+		Role:        role,
+		Roles:       roles,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        hex.EncodeToString(jti),
+			Issuer:    jwtIssuer(),
+			Audience:  jwt.ClaimStrings{jwtAudience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", nil, err
+	}
 
-	return token.SignedString(jwtKey)
+	return signed, claims, nil
 }
 
+// ValidateJWT verifies an access token against the key named by its kid,
+// only accepting that key's algorithm, and checks the issuer, audience and
+// expiry.
 func ValidateJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := signingKeys.lookup(kid, time.Now())
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
 
-	if !token.Valid {
+		return key.Private.Public(), nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(jwtIssuer()),
+		jwt.WithAudience(jwtAudience()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms for access tokens.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+var (
+	ErrNoSigningKey       = errors.New("no signing key available")
+	ErrUnknownKeyID       = errors.New("token signed with an unknown key")
+	ErrUnsupportedKeyType = errors.New("unsupported signing key type")
+)
+
+// SigningKey is one key of the rotating set. A key signs tokens from
+// ActiveAt until the next key becomes active, and verifies them for another
+// AccessTokenTTL after that, until the last token it signed has expired.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	ActiveAt  time.Time
+}
+
+// GenerateSigningKey creates a key for algorithm that starts signing at
+// activeAt.
+func GenerateSigningKey(algorithm string, activeAt time.Time) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:        hex.EncodeToString(id),
+		Algorithm: algorithm,
+		Private:   private,
+		ActiveAt:  activeAt,
+	}, nil
+}
+
+// MarshalPrivateKey encodes the private key as PKCS#8 PEM for storage.
+func (k *SigningKey) MarshalPrivateKey() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKey decodes a key written by MarshalPrivateKey.
+func ParsePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// JWK is the public half of a signing key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *SigningKey) jwk() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	enc := base64.RawURLEncoding
+
+	switch public := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = enc.EncodeToString(public.N.Bytes())
+		jwk.E = enc.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = enc.EncodeToString(public)
+	}
+
+	return jwk
+}
+
+// KeySet holds the keys tokens are signed and verified with.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []*SigningKey
+}
+
+// Set replaces the keys in the set.
+func (s *KeySet) Set(keys ...*SigningKey) {
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ActiveAt.Before(sorted[j].ActiveAt)
+	})
+
+	s.mu.Lock()
+	s.keys = sorted
+	s.mu.Unlock()
+}
+
+// signing returns the newest key already active at now.
+func (s *KeySet) signing(now time.Time) (*SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveAt.After(now) {
+			return s.keys[i], nil
+		}
+	}
+
+	return nil, ErrNoSigningKey
+}
+
+// verifying returns the keys tokens can still be signed with at now: keys
+// not yet active, which are published ahead of time, the current key, and
+// keys replaced less than AccessTokenTTL ago.
+func (s *KeySet) verifying(now time.Time) []*SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []*SigningKey
+	for i, key := range s.keys {
+		if i+1 < len(s.keys) && s.keys[i+1].ActiveAt.Add(AccessTokenTTL).Before(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (s *KeySet) lookup(id string, now time.Time) (*SigningKey, error) {
+	for _, key := range s.verifying(now) {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownKeyID
+}
+
+// JWKS returns the public keys other services verify our tokens with.
+func (s *KeySet) JWKS(now time.Time) JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.verifying(now) {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+
+	return jwks
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidateJWT(t *testing.T) {
+	for _, algorithm := range []string{AlgRS256, AlgEdDSA} {
+		key, err := GenerateSigningKey(algorithm, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		SetSigningKeys(key)
+
+		token, issued, err := GenerateJWT("alice", "user", []string{"user"}, []string{"order:create"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claims, err := ValidateJWT(token)
+		if err != nil {
+			t.Fatalf("%s: ValidateJWT: %v", algorithm, err)
+		}
+		if claims.Username != "alice" || claims.ID != issued.ID || !claims.HasPermission("order:create") {
+			t.Errorf("%s: claims = %+v, want those issued", algorithm, claims)
+		}
+	}
+}
+
+func TestValidateJWTRejects(t *testing.T) {
+	key, err := GenerateSigningKey(AlgRS256, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetSigningKeys(key)
+
+	now := time.Now()
+	valid := func() *Claims {
+		return &Claims{
+			Username: "alice",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    defaultIssuer,
+				Audience:  jwt.ClaimStrings{defaultAudience},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+	}
+	sign := func(claims *Claims, kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	wrongIssuer := valid()
+	wrongIssuer.Issuer = "someone-else"
+	wrongAudience := valid()
+	wrongAudience.Audience = jwt.ClaimStrings{"other-api"}
+	expired := valid()
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+	noExpiry := valid()
+	noExpiry.ExpiresAt = nil
+
+	// HS256 with the public key as the secret, the classic algorithm
+	// confusion attack
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+	hmac.Header["kid"] = key.ID
+	confused, err := hmac.SignedString([]byte(key.jwk().N))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"unknown kid":    sign(valid(), "unknown"),
+		"wrong issuer":   sign(wrongIssuer, key.ID),
+		"wrong audience": sign(wrongAudience, key.ID),
+		"expired":        sign(expired, key.ID),
+		"no expiry":      sign(noExpiry, key.ID),
+		"hmac":           confused,
+		"none":           unsigned,
+		"tampered":       sign(valid(), key.ID) + "x",
+	}
+
+	for name, token := range cases {
+		if _, err := ValidateJWT(token); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	now := time.Now()
+	old, _ := GenerateSigningKey(AlgEdDSA, now.Add(-48*time.Hour))
+	current, _ := GenerateSigningKey(AlgEdDSA, now.Add(-time.Minute))
+	next, _ := GenerateSigningKey(AlgEdDSA, now.Add(time.Hour))
+
+	var set KeySet
+	set.Set(next, old, current)
+
+	signing, err := set.signing(now)
+	if err != nil || signing != current {
+		t.Fatalf("signing key = %v, %v; want the current key", signing, err)
+	}
+
+	// The old key still verifies tokens signed just before the switch
+	if _, err := set.lookup(old.ID, now); err != nil {
+		t.Errorf("old key rejected right after rotation: %v", err)
+	}
+	if _, err := set.lookup(old.ID, now.Add(AccessTokenTTL)); err != ErrUnknownKeyID {
+		t.Errorf("old key accepted after its tokens expired: %v", err)
+	}
+	if _, err := set.lookup(next.ID, now); err != nil {
+		t.Errorf("next key not published ahead of time: %v", err)
+	}
+
+	jwks := set.JWKS(now)
+	if len(jwks.Keys) != 3 || jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].X == "" {
+		t.Errorf("JWKS = %+v", jwks)
+	}
+
+	if _, err := (&KeySet{}).signing(now); err != ErrNoSigningKey {
+		t.Errorf("empty set signing = %v, want ErrNoSigningKey", err)
+	}
+}
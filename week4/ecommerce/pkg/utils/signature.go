@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of message under key, for links
+// that must not be forged or altered.
+func Sign(key []byte, message string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature reports whether signature was made by Sign for message,
+// comparing in constant time.
+func ValidSignature(key []byte, message, signature string) bool {
+	return hmac.Equal([]byte(Sign(key, message)), []byte(signature))
+}
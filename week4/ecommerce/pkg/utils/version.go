@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrVersionConflict is returned by updates made against a version that
+	// someone else has changed in the meantime.
+	ErrVersionConflict = errors.New("the record was changed by someone else, reload it and try again")
+	ErrInvalidVersion  = errors.New("invalid version in If-Match")
+)
+
+// VersionTag is the entity tag of a row version, optionally followed by
+// more detail about the representation. ParseVersionTag reads it back.
+func VersionTag(version int, detail string) string {
+	if detail == "" {
+		return fmt.Sprintf(`"%d"`, version)
+	}
+
+	return fmt.Sprintf(`"%d-%s"`, version, detail)
+}
+
+// ParseVersionTag returns the version in an If-Match header made from
+// VersionTag. It returns 0, meaning any version, for an empty header or "*".
+func ParseVersionTag(header string) (int, error) {
+	tag := strings.TrimSpace(header)
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+
+	// Only the first tag counts, a client edits from one representation
+	tag, _, _ = strings.Cut(tag, ",")
+	tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+	tag, _, _ = strings.Cut(tag, "-")
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidVersion
+	}
+
+	return version, nil
+}
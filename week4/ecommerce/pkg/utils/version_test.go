@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestParseVersionTag(t *testing.T) {
+	cases := []struct {
+		header  string
+		version int
+		err     error
+	}{
+		{"", 0, nil},
+		{"*", 0, nil},
+		{VersionTag(3, ""), 3, nil},
+		{VersionTag(12, "9f2c"), 12, nil},
+		{`W/"7", "8"`, 7, nil},
+		{`"abc"`, 0, ErrInvalidVersion},
+		{`"0"`, 0, ErrInvalidVersion},
+	}
+
+	for _, c := range cases {
+		version, err := ParseVersionTag(c.header)
+		if version != c.version || err != c.err {
+			t.Errorf("ParseVersionTag(%q) = %d, %v; want %d, %v", c.header, version, err, c.version, c.err)
+		}
+	}
+}